@@ -0,0 +1,149 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned by requests made by callers over their quota
+var ErrQuotaExceeded = errors.New("caller quota exceeded")
+
+type callerKey struct{}
+
+// WithCaller returns a copy of ctx tagging the requests made with it as made
+// by caller, which are accounted and limited by the caller quota
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// callerFrom returns the caller carried by ctx, an empty string if none
+func callerFrom(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// Usage is the amount of requests and bytes transferred by a caller
+type Usage struct {
+	Requests      int64
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// Quota limits the usage of a caller. Zero values are unlimited.
+type Quota struct {
+	// Requests is the maximum number of requests
+	Requests int64
+
+	// Bytes is the maximum number of bytes sent and received
+	Bytes int64
+
+	// Period resets the usage of the caller periodically,
+	// if zero the quota is never reset
+	Period time.Duration
+}
+
+// SetQuota limits the usage of caller, resetting its current usage
+func (m *Millennium) SetQuota(caller string, quota Quota) {
+	m.accounting.setQuota(caller, quota)
+}
+
+// Usage returns the usage of caller in the current quota period
+func (m *Millennium) Usage(caller string) Usage {
+	return m.accounting.usage(caller)
+}
+
+// accounting keeps the usage and quota of every caller
+type accounting struct {
+	mu      sync.Mutex
+	callers map[string]*callerUsage
+}
+
+type callerUsage struct {
+	usage Usage
+	quota Quota
+	start time.Time
+}
+
+// get returns the caller usage, resetting it if the quota period is over.
+// It should be called with the lock held.
+func (a *accounting) get(caller string) *callerUsage {
+	if a.callers == nil {
+		a.callers = map[string]*callerUsage{}
+	}
+
+	c, ok := a.callers[caller]
+	if !ok {
+		c = &callerUsage{start: time.Now()}
+		a.callers[caller] = c
+	}
+
+	if c.quota.Period > 0 && time.Since(c.start) >= c.quota.Period {
+		c.usage = Usage{}
+		c.start = time.Now()
+	}
+
+	return c
+}
+
+func (a *accounting) setQuota(caller string, quota Quota) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c := a.get(caller)
+	c.quota = quota
+	c.usage = Usage{}
+	c.start = time.Now()
+}
+
+func (a *accounting) usage(caller string) Usage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.get(caller).usage
+}
+
+// admit accounts a new request of caller, rejecting it if the caller is over quota
+func (a *accounting) admit(caller string, bytesSent int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	c := a.get(caller)
+	if c.quota.Requests > 0 && c.usage.Requests >= c.quota.Requests {
+		return ErrQuotaExceeded
+	}
+
+	if c.quota.Bytes > 0 && c.usage.BytesSent+c.usage.BytesReceived >= c.quota.Bytes {
+		return ErrQuotaExceeded
+	}
+
+	c.usage.Requests++
+	if bytesSent > 0 {
+		c.usage.BytesSent += bytesSent
+	}
+
+	return nil
+}
+
+func (a *accounting) received(caller string, n int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.get(caller).usage.BytesReceived += n
+}
+
+// countingBody accounts the bytes read from a response body
+type countingBody struct {
+	io.ReadCloser
+	count func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.count(int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,62 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAccounting(t *testing.T) {
+	client := NewTestClient(t)
+	client.Context = WithCaller(context.Background(), "sync-produtos")
+	client.SetQuota("sync-produtos", Quota{Requests: 2})
+
+	var r interface{}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage := client.Usage("sync-produtos")
+	if usage.Requests != 2 || usage.BytesReceived == 0 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded but got %v", err)
+	}
+
+	// Other callers are not affected by the quota
+	records, errs := client.Stream(WithCaller(context.Background(), "lookup"), "test.success.GET", url.Values{})
+	for range records {
+	}
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+
+	if usage := client.Usage("lookup"); usage.Requests != 1 {
+		t.Errorf("Expected 1 request but got %d", usage.Requests)
+	}
+}
+
+func TestAccountingQuotaPeriod(t *testing.T) {
+	var a accounting
+	a.setQuota("job", Quota{Bytes: 10, Period: 20 * time.Millisecond})
+
+	if err := a.admit("job", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.admit("job", 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded but got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := a.admit("job", 1); err != nil {
+		t.Errorf("Expected quota reset but got %v", err)
+	}
+}
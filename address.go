@@ -0,0 +1,165 @@
+package millennium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by the address helpers
+var (
+	ErrInvalidCEP = errors.New("invalid CEP")
+	ErrInvalidUF  = errors.New("invalid UF")
+)
+
+// states are the abbreviations of the Brazilian states (UF)
+var states = map[string]bool{
+	"AC": true, "AL": true, "AP": true, "AM": true, "BA": true, "CE": true, "DF": true,
+	"ES": true, "GO": true, "MA": true, "MT": true, "MS": true, "MG": true, "PA": true,
+	"PB": true, "PR": true, "PE": true, "PI": true, "RJ": true, "RN": true, "RS": true,
+	"RO": true, "RR": true, "SC": true, "SP": true, "SE": true, "TO": true,
+}
+
+// NormalizeCEP returns the 8 digits of a CEP, the format accepted by
+// Millennium, like "01310100" for "01310-100" or "01.310-100".
+// CEPs that lost their leading zero, like when stored as numbers, are padded;
+// only CEPs starting with 0 lose it, so shorter ones are invalid.
+func NormalizeCEP(cep string) (string, error) {
+	var digits strings.Builder
+	for _, c := range strings.TrimSpace(cep) {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case c == '-' || c == '.' || c == ' ':
+		default:
+			return "", fmt.Errorf("%w %q", ErrInvalidCEP, cep)
+		}
+	}
+
+	if digits.Len() < 7 || digits.Len() > 8 {
+		return "", fmt.Errorf("%w %q", ErrInvalidCEP, cep)
+	}
+
+	return strings.Repeat("0", 8-digits.Len()) + digits.String(), nil
+}
+
+// FormatCEP returns a CEP as 00000-000, for display
+func FormatCEP(cep string) (string, error) {
+	normalized, err := NormalizeCEP(cep)
+	if err != nil {
+		return "", err
+	}
+
+	return normalized[:5] + "-" + normalized[5:], nil
+}
+
+// NormalizeUF returns the abbreviation of a Brazilian state in upper case,
+// like "SP" for " sp"
+func NormalizeUF(uf string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(uf))
+	if !states[normalized] {
+		return "", fmt.Errorf("%w %q", ErrInvalidUF, uf)
+	}
+
+	return normalized, nil
+}
+
+// WithoutNumber is the number used by addresses without one
+const WithoutNumber = "S/N"
+
+// Address is a Brazilian address as sent to the Millennium customer and
+// delivery methods
+type Address struct {
+	Logradouro  string `json:"logradouro"`
+	Numero      string `json:"numero"`
+	Complemento string `json:"complemento,omitempty"`
+	Bairro      string `json:"bairro"`
+	Cidade      string `json:"cidade"`
+	UF          string `json:"estado"`
+	CEP         string `json:"cep"`
+}
+
+// Normalize returns the address with repeated spaces removed, the CEP and
+// UF normalized and WithoutNumber when the number is empty. Invalid CEP and
+// UF are kept as they are and reported in the error.
+func (a Address) Normalize() (Address, error) {
+	for _, field := range []*string{&a.Logradouro, &a.Numero, &a.Complemento, &a.Bairro, &a.Cidade} {
+		*field = strings.Join(strings.Fields(*field), " ")
+	}
+
+	if a.Numero == "" {
+		a.Numero = WithoutNumber
+	}
+
+	var errs []error
+
+	if cep, err := NormalizeCEP(a.CEP); err != nil {
+		errs = append(errs, err)
+	} else {
+		a.CEP = cep
+	}
+
+	if uf, err := NormalizeUF(a.UF); err != nil {
+		errs = append(errs, err)
+	} else {
+		a.UF = uf
+	}
+
+	return a, errors.Join(errs...)
+}
+
+// CEPFields and UFFields are the fields normalized by NormalizeAddressPayload
+var (
+	CEPFields = []string{"cep"}
+	UFFields  = []string{"uf", "estado"}
+)
+
+// NormalizeAddressPayload normalizes the CEP and UF fields found at any
+// depth of a JSON body, like the delivery addresses nested in an order,
+// before it is sent to Millennium. CEPs sent as numbers become strings.
+func NormalizeAddressPayload(body []byte) ([]byte, error) {
+	match := func(field string) bool {
+		return fieldIn(field, CEPFields) || fieldIn(field, UFFields)
+	}
+
+	return transformFields(body, match, func(field string, value json.RawMessage) (json.RawMessage, error) {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			// CEPs stored as numbers
+			var n json.Number
+			if err := json.Unmarshal(value, &n); err != nil || !fieldIn(field, CEPFields) {
+				return value, nil
+			}
+			s = n.String()
+		}
+
+		// Empty fields are left to Millennium validation
+		if strings.TrimSpace(s) == "" {
+			return value, nil
+		}
+
+		normalize := NormalizeUF
+		if fieldIn(field, CEPFields) {
+			normalize = NormalizeCEP
+		}
+
+		normalized, err := normalize(s)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(normalized)
+	})
+}
+
+// fieldIn reports if field is one of fields, ignoring case
+func fieldIn(field string, fields []string) bool {
+	for _, f := range fields {
+		if strings.EqualFold(field, f) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package millennium
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeCEP(t *testing.T) {
+	cases := []struct {
+		CEP       string
+		Expected  string
+		Formatted string
+		Err       bool
+	}{
+		{CEP: "01310-100", Expected: "01310100", Formatted: "01310-100"},
+		{CEP: " 01.310-100 ", Expected: "01310100", Formatted: "01310-100"},
+		{CEP: "1310100", Expected: "01310100", Formatted: "01310-100"},
+		{CEP: "90010000", Expected: "90010000", Formatted: "90010-000"},
+		{CEP: "0131010a", Err: true},
+		{CEP: "013101000", Err: true},
+		{CEP: "131010", Err: true},
+		{CEP: "13101", Err: true},
+		{CEP: "", Err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.CEP, func(t *testing.T) {
+			cep, err := NormalizeCEP(c.CEP)
+			if c.Err {
+				if !errors.Is(err, ErrInvalidCEP) {
+					t.Errorf("Expected ErrInvalidCEP but got %v", err)
+				}
+				return
+			}
+
+			if err != nil || cep != c.Expected {
+				t.Errorf("Expected %s but got %s (%v)", c.Expected, cep, err)
+			}
+
+			if formatted, _ := FormatCEP(c.CEP); formatted != c.Formatted {
+				t.Errorf("Expected %s but got %s", c.Formatted, formatted)
+			}
+		})
+	}
+}
+
+func TestAddressNormalize(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Address  Address
+		Expected Address
+		Err      error
+	}{
+		{
+			Name:     "normalized",
+			Address:  Address{Logradouro: "  Av.  Paulista ", Bairro: "Bela   Vista", Cidade: "São Paulo", UF: "sp", CEP: "01310-100"},
+			Expected: Address{Logradouro: "Av. Paulista", Numero: WithoutNumber, Bairro: "Bela Vista", Cidade: "São Paulo", UF: "SP", CEP: "01310100"},
+		},
+		{Name: "invalid UF", Address: Address{Numero: "10", UF: "XX", CEP: "01310100"}, Err: ErrInvalidUF},
+		{Name: "invalid CEP", Address: Address{Numero: "10", UF: "RJ", CEP: "123"}, Err: ErrInvalidCEP},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			address, err := c.Address.Normalize()
+			if c.Err != nil {
+				if !errors.Is(err, c.Err) {
+					t.Errorf("Expected %v but got %v", c.Err, err)
+				}
+				return
+			}
+
+			if err != nil || address != c.Expected {
+				t.Errorf("Expected %+v but got %+v (%v)", c.Expected, address, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddressPayload(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Body     string
+		Expected string
+		Err      error
+	}{
+		{
+			Name:     "nested",
+			Body:     `{"cliente":1,"enderecos":[{"cep":"01310-100","estado":"sp"},{"CEP":1310100,"uf":" rj"}]}`,
+			Expected: `{"cliente":1,"enderecos":[{"cep":"01310100","estado":"SP"},{"CEP":"01310100","uf":"RJ"}]}`,
+		},
+		{Name: "empty and null", Body: `{"cep":"","estado":null}`, Expected: `{"cep":"","estado":null}`},
+		{Name: "invalid", Body: `{"cep":"abc"}`, Err: ErrInvalidCEP},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			body, err := NormalizeAddressPayload([]byte(c.Body))
+			if c.Err != nil {
+				if !errors.Is(err, c.Err) {
+					t.Errorf("Expected %v but got %v", c.Err, err)
+				}
+				return
+			}
+
+			if err != nil || string(body) != c.Expected {
+				t.Errorf("Expected %s but got %s (%v)", c.Expected, body, err)
+			}
+		})
+	}
+}
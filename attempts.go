@@ -0,0 +1,141 @@
+package millennium
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Attempt describes a single try of a request to Millennium
+type Attempt struct {
+	// StatusCode of the response, zero if there was no response
+	StatusCode int
+
+	// Err is the transport error of the attempt, if any
+	Err error
+
+	// Latency from the attempt start until its response
+	Latency time.Duration
+
+	// RetryAfter is the delay asked by a throttled response, if any
+	RetryAfter time.Duration
+}
+
+// RetryHook is called once a request is done, with every attempt made and
+// the final error of the request, nil if it succeeded
+type RetryHook func(method string, attempts []Attempt, err error)
+
+// WithRetryHook sets a hook to receive the attempts made by each request
+func WithRetryHook(hook RetryHook) Option {
+	return func(m *Millennium) {
+		m.retryHook = hook
+	}
+}
+
+// RetryError is returned when a request failed after being retried, or
+// failed with a retryable response when retries are disabled, carrying the
+// details of every attempt
+type RetryError struct {
+	Attempts []Attempt
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (%d attempts)", e.Err, len(e.Attempts))
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+type attemptsKey struct{}
+
+// attemptRecorder collects the attempts of a single request
+type attemptRecorder struct {
+	mu       sync.Mutex
+	start    time.Time
+	attempts []Attempt
+
+	// response of the last attempt, used to compute the backoff
+	response *http.Response
+}
+
+func withAttemptRecorder(ctx context.Context) (context.Context, *attemptRecorder) {
+	rec := &attemptRecorder{start: time.Now()}
+	return context.WithValue(ctx, attemptsKey{}, rec), rec
+}
+
+func attemptRecorderFrom(ctx context.Context) *attemptRecorder {
+	rec, _ := ctx.Value(attemptsKey{}).(*attemptRecorder)
+	return rec
+}
+
+// begin marks the start of a new attempt
+func (r *attemptRecorder) begin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.start = time.Now()
+}
+
+// finish records the outcome of the current attempt
+func (r *attemptRecorder) finish(res *http.Response, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt := Attempt{Err: err, Latency: time.Since(r.start)}
+	if res != nil {
+		attempt.StatusCode = res.StatusCode
+		attempt.RetryAfter, _ = throttledFor(res)
+	}
+
+	r.attempts = append(r.attempts, attempt)
+	r.response = res
+}
+
+// last returns the number of attempts and the response of the last one
+func (r *attemptRecorder) last() (int, *http.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.attempts), r.response
+}
+
+func (r *attemptRecorder) list() []Attempt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Attempt(nil), r.attempts...)
+}
+
+// requestLogHook is the retryablehttp RequestLogHook of the client,
+// called before every attempt
+func (m *Millennium) requestLogHook(_ retryablehttp.Logger, req *http.Request, attempt int) {
+	m.paceAttempt(req.Context(), attempt)
+
+	if rec := attemptRecorderFrom(req.Context()); rec != nil {
+		rec.begin()
+	}
+}
+
+// reportAttempts calls the retry hook and wraps err with the attempts when
+// the request was retried
+func (m *Millennium) reportAttempts(method string, rec *attemptRecorder, err error) error {
+	attempts := rec.list()
+
+	if m.retryHook != nil {
+		m.retryHook(method, attempts, err)
+	}
+
+	// A single attempt with a response failed only because the retries
+	// were disabled, the status is still needed to match ErrThrottled
+	if err != nil && (len(attempts) > 1 || (len(attempts) == 1 && attempts[0].StatusCode != 0)) {
+		return &RetryError{Attempts: attempts, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,76 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryHook(t *testing.T) {
+	server, _ := newCountingServer(t, http.StatusInternalServerError, `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Query error"}}}`)
+
+	var (
+		hookMethod   string
+		hookAttempts []Attempt
+		hookErr      error
+	)
+
+	hook := func(method string, attempts []Attempt, err error) {
+		hookMethod, hookAttempts, hookErr = method, attempts, err
+	}
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRetryHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	var r interface{}
+	_, err = client.Get("test", url.Values{}, &r)
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Expected RetryError but got %v", err)
+	}
+
+	if len(retryErr.Attempts) != RetryMax+1 {
+		t.Errorf("Expected %d attempts but got %d", RetryMax+1, len(retryErr.Attempts))
+	}
+
+	if hookMethod != "test" || hookErr == nil || len(hookAttempts) != RetryMax+1 {
+		t.Errorf("Unexpected hook call %s %v %v", hookMethod, hookAttempts, hookErr)
+	}
+
+	for _, attempt := range hookAttempts {
+		if attempt.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected status 500 but got %d", attempt.StatusCode)
+		}
+	}
+}
+
+func TestRetryHookSuccess(t *testing.T) {
+	var attempts []Attempt
+
+	client, err := NewClient(context.Background(), serverAddr, 5*time.Second, WithRetryHook(func(method string, a []Attempt, err error) {
+		attempts = a
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attempts) != 1 || attempts[0].StatusCode != http.StatusOK || attempts[0].Latency <= 0 {
+		t.Errorf("Unexpected attempts %+v", attempts)
+	}
+}
@@ -0,0 +1,60 @@
+package millennium
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// AuthLayer adds authentication to the requests on top of the Millennium
+// authentication, like the credentials of a gateway in front of Millennium
+type AuthLayer interface {
+	Apply(req *http.Request) error
+}
+
+// AuthLayerFunc is an adapter to use ordinary functions as AuthLayer
+type AuthLayerFunc func(req *http.Request) error
+
+// Apply calls f(req)
+func (f AuthLayerFunc) Apply(req *http.Request) error {
+	return f(req)
+}
+
+// WithAuthLayers adds authentication layers applied to every request, after
+// the Millennium authentication. Layers are kept when Login is called again,
+// so a gateway basic auth and the WTS session can be used together.
+func WithAuthLayers(layers ...AuthLayer) Option {
+	return func(m *Millennium) {
+		m.authLayers = append(m.authLayers, layers...)
+	}
+}
+
+// BasicAuthLayer authenticates the requests with basic auth, as required by
+// some gateways in front of Millennium.
+// It uses the Authorization header, so it should be combined with the
+// Session authentication, as Basic and NTLM use the same header.
+func BasicAuthLayer(username string, password string) AuthLayer {
+	return AuthLayerFunc(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// HeaderAuthLayer authenticates the requests with a header, like the API key
+// of a gateway
+func HeaderAuthLayer(name string, value string) AuthLayer {
+	return AuthLayerFunc(func(req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
+
+// applyAuthLayers applies the authentication layers to req
+func (m *Millennium) applyAuthLayers(req *http.Request) error {
+	for _, layer := range m.authLayers {
+		if err := layer.Apply(req); err != nil {
+			return fmt.Errorf("unable to authenticate request: %w", err)
+		}
+	}
+
+	return nil
+}
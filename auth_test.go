@@ -0,0 +1,64 @@
+package millennium
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAuthLayers(t *testing.T) {
+	server, _ := newSessionServer(t)
+
+	// The gateway requires its own basic auth and API key before Millennium
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "gateway" || password != "secret" || r.Header.Get("X-Api-Key") != "key" {
+			http.Error(w, `{"error":{"code":401,"message":{"lang":"en","value":"gateway denied"}}}`, http.StatusUnauthorized)
+			return
+		}
+
+		req, _ := http.NewRequest(r.Method, server.URL+r.URL.RequestURI(), r.Body)
+		req.Header = r.Header
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(res.StatusCode)
+		_, _ = io.Copy(w, res.Body)
+	}))
+	defer gateway.Close()
+
+	client, err := NewClient(context.Background(), gateway.URL, 5*time.Second, WithAuthLayers(
+		BasicAuthLayer("gateway", "secret"),
+		HeaderAuthLayer("X-Api-Key", "key"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	// Logging in again keeps the gateway authentication
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+}
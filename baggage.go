@@ -0,0 +1,61 @@
+package millennium
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type baggageKey struct{}
+
+// WithBaggage returns a copy of ctx carrying key=value as baggage, like a
+// trace id or the id of the upstream event, besides the baggage already in
+// ctx. The baggage is set in the samples of the requests made with ctx and
+// forwarded by StatusPinger, keeping the flows correlated end to end.
+func WithBaggage(ctx context.Context, key, value string) context.Context {
+	baggage := map[string]string{key: value}
+	for k, v := range BaggageFrom(ctx) {
+		if k != key {
+			baggage[k] = v
+		}
+	}
+
+	return context.WithValue(ctx, baggageKey{}, baggage)
+}
+
+// BaggageFrom returns the baggage carried by ctx, nil if none. The map must
+// not be changed.
+func BaggageFrom(ctx context.Context) map[string]string {
+	baggage, _ := ctx.Value(baggageKey{}).(map[string]string)
+	return baggage
+}
+
+// baggageHeader encodes the tenant, operation and baggage as a W3C baggage
+// header, sorted by key
+func baggageHeader(tenant, operation string, baggage map[string]string) string {
+	members := make(map[string]string, len(baggage)+2)
+	for k, v := range baggage {
+		members[k] = v
+	}
+
+	if tenant != "" {
+		members["tenant"] = tenant
+	}
+
+	if operation != "" {
+		members["operation"] = operation
+	}
+
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		keys[i] = url.PathEscape(k) + "=" + url.PathEscape(members[k])
+	}
+
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,66 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWithBaggage(t *testing.T) {
+	ctx := WithBaggage(context.Background(), "trace", "abc")
+	ctx = WithBaggage(ctx, "event", "order 42")
+	overridden := WithBaggage(ctx, "trace", "def")
+
+	if got := BaggageFrom(ctx); len(got) != 2 || got["trace"] != "abc" || got["event"] != "order 42" {
+		t.Errorf("Unexpected baggage %v", got)
+	}
+
+	if got := BaggageFrom(overridden)["trace"]; got != "def" {
+		t.Errorf("Expected the trace to be overridden but got %s", got)
+	}
+
+	if got := BaggageFrom(context.Background()); got != nil {
+		t.Errorf("Expected no baggage but got %v", got)
+	}
+}
+
+func TestBaggagePropagation(t *testing.T) {
+	headers := make(chan string, 1)
+	status := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get("Baggage")
+	}))
+	defer status.Close()
+
+	pinger := &StatusPinger{URL: status.URL}
+
+	var samples []Sample
+	hook := func(s Sample) {
+		samples = append(samples, s)
+		pinger.Hook(s)
+	}
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithTenant("loja-1"), WithSampleHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithBaggage(WithOperation(context.Background(), "sync-orders"), "trace", "abc 1")
+
+	var r interface{}
+	if _, err := client.GetCtx(ctx, "test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+	pinger.Wait()
+
+	if len(samples) != 1 || samples[0].Baggage["trace"] != "abc 1" {
+		t.Errorf("Expected the baggage in the sample but got %+v", samples)
+	}
+
+	expected := "operation=sync-orders,tenant=loja-1,trace=abc%201"
+	if got := <-headers; got != expected {
+		t.Errorf("Expected baggage header %q but got %q", expected, got)
+	}
+}
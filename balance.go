@@ -0,0 +1,121 @@
+package millennium
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// BalanceStrategy is how WithBalancer picks the server of a GET request
+type BalanceStrategy int
+
+// Strategies of WithBalancer
+const (
+	// RoundRobin sends the requests to each server in turn
+	RoundRobin BalanceStrategy = iota
+	// LeastPending sends the requests to the server with the fewest
+	// requests in flight, in turn when tied
+	LeastPending
+)
+
+func (s BalanceStrategy) String() string {
+	switch s {
+	case RoundRobin:
+		return "round-robin"
+	case LeastPending:
+		return "least-pending"
+	}
+
+	return fmt.Sprintf("BalanceStrategy(%d)", int(s))
+}
+
+// WithBalancer distributes the GET requests across servers, the Millennium
+// application servers of the deployment, using strategy. Include the server
+// of the client to also send reads to it.
+// The other requests are pinned to the server of the client, which issued
+// the session, so writes and logins never move between servers. The
+// servers share the session of the client, as Millennium keeps the
+// sessions in the database.
+func WithBalancer(strategy BalanceStrategy, servers ...string) Option {
+	return func(m *Millennium) {
+		if len(servers) == 0 {
+			m.balancer = nil
+			return
+		}
+
+		b := &balancer{strategy: strategy, pending: make([]int, len(servers))}
+		for _, server := range servers {
+			b.servers = append(b.servers, strings.TrimSuffix(server, "/"))
+		}
+
+		m.balancer = b
+	}
+}
+
+// balancer picks the servers of WithBalancer, tracking their pending
+// requests
+type balancer struct {
+	strategy BalanceStrategy
+	servers  []string
+
+	mu      sync.Mutex
+	next    int
+	pending []int
+}
+
+// pick returns the server of the next GET request
+func (b *balancer) pick() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	picked := b.next % len(b.servers)
+	if b.strategy == LeastPending {
+		for i := range b.servers {
+			candidate := (b.next + i) % len(b.servers)
+			if b.pending[candidate] < b.pending[picked] {
+				picked = candidate
+			}
+		}
+	}
+
+	b.next = picked + 1
+	return b.servers[picked]
+}
+
+// begin counts a request to url as pending, returning the func to call once
+// it is done. Requests to servers not balanced are ignored.
+func (b *balancer) begin(url string) (done func()) {
+	if b == nil {
+		return func() {}
+	}
+
+	for i, server := range b.servers {
+		if !strings.HasPrefix(url, server+"/api/") {
+			continue
+		}
+
+		b.mu.Lock()
+		b.pending[i]++
+		b.mu.Unlock()
+
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				b.mu.Lock()
+				b.pending[i]--
+				b.mu.Unlock()
+			})
+		}
+	}
+
+	return func() {}
+}
+
+// serverFor returns the server of a request using the HTTP method
+func (m *Millennium) serverFor(method HTTPMethod) string {
+	if m.balancer != nil && method == GET {
+		return m.balancer.pick()
+	}
+
+	return m.serverAddr()
+}
@@ -0,0 +1,99 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBalancer(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"server":"` + name + `"}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	primary, a, b := newServer("primary"), newServer("a"), newServer("b")
+	names := map[string]string{primary.URL: "primary", a.URL: "a", b.URL: "b"}
+
+	// served returns the server of a response, leaving its body open
+	served := func(t *testing.T, res *http.Response) string {
+		t.Helper()
+
+		for url, name := range names {
+			if strings.HasPrefix(res.Request.URL.String(), url+"/") {
+				return name
+			}
+		}
+
+		t.Fatalf("Unexpected server %s", res.Request.URL)
+		return ""
+	}
+
+	cases := []struct {
+		Name     string
+		Strategy BalanceStrategy
+		Requests []string
+		Close    []bool
+		Expected []string
+	}{
+		{
+			Name:     "round-robin",
+			Strategy: RoundRobin,
+			Requests: []string{"GET", "GET", "POST", "GET"},
+			Close:    []bool{true, true, true, true},
+			Expected: []string{"a", "b", "primary", "a"},
+		},
+		{
+			Name:     "least-pending",
+			Strategy: LeastPending,
+			Requests: []string{"GET", "GET", "GET", "PUT"},
+			Close:    []bool{false, true, true, true},
+			Expected: []string{"a", "b", "b", "primary"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), primary.URL, 5*time.Second, WithBalancer(c.Strategy, a.URL, b.URL+"/"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var open []*http.Response
+			t.Cleanup(func() {
+				for _, res := range open {
+					res.Body.Close()
+				}
+			})
+
+			for i, method := range c.Requests {
+				r := RequestMethod{HTTPMethod: HTTPMethod(method), Method: "test.success"}
+				if method != "GET" {
+					r.Body = []byte(`{}`)
+				}
+
+				res, err := client.Do(context.Background(), r)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := served(t, res); got != c.Expected[i] {
+					t.Errorf("Expected request %d to be sent to %s but got %s", i, c.Expected[i], got)
+				}
+
+				if c.Close[i] {
+					res.Body.Close()
+				} else {
+					open = append(open, res)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Base64 is a content sent by Millennium as a base64 string, like the XML
+// of a NF-e or a product image, decoded to bytes when unmarshaled.
+// Line breaks inside the string and missing padding are accepted, and null
+// or empty strings decode to an empty content. It can be used as field type
+// with Get or Record.ScanStruct.
+type Base64 []byte
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *Base64) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*b = nil
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	content, err := DecodeBase64(s)
+	if err != nil {
+		return err
+	}
+
+	*b = content
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the content back to a
+// base64 string
+func (b Base64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.StdEncoding.EncodeToString(b))
+}
+
+// Reader returns a reader of the decoded content
+func (b Base64) Reader() io.Reader {
+	return bytes.NewReader(b)
+}
+
+// String returns the decoded content as text, like the XML of a NF-e
+func (b Base64) String() string {
+	return string(b)
+}
+
+// DecodeBase64 decodes a base64 string as sent by Millennium, ignoring line
+// breaks and accepting it with or without padding
+func DecodeBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, s)
+
+	content, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 content: %w", err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,61 @@
+package millennium
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestBase64(t *testing.T) {
+	cases := []struct {
+		Name    string
+		JSON    string
+		Content string
+		Error   bool
+	}{
+		{Name: "padded", JSON: `"PE5GZT48L05GZT4="`, Content: "<NFe></NFe>"},
+		{Name: "without padding", JSON: `"PE5GZT48L05GZT4"`, Content: "<NFe></NFe>"},
+		{Name: "line breaks", JSON: `"PE5GZT48\r\nL05GZT4="`, Content: "<NFe></NFe>"},
+		{Name: "null", JSON: `null`},
+		{Name: "empty", JSON: `""`},
+		{Name: "invalid", JSON: `"not base64!"`, Error: true},
+		{Name: "not a string", JSON: `10`, Error: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var v struct {
+				XML Base64 `json:"xml"`
+			}
+
+			err := json.Unmarshal([]byte(`{"xml":`+c.JSON+`}`), &v)
+			if (err != nil) != c.Error {
+				t.Fatalf("Expected error to be %v but got %v", c.Error, err)
+			}
+
+			if c.Error {
+				return
+			}
+
+			content, err := io.ReadAll(v.XML.Reader())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != c.Content {
+				t.Errorf("Expected %q but got %q", c.Content, content)
+			}
+		})
+	}
+}
+
+func TestBase64Marshal(t *testing.T) {
+	data, err := json.Marshal(Base64("<NFe></NFe>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `"PE5GZT48L05GZT4="` {
+		t.Errorf("Expected %s but got %s", `"PE5GZT48L05GZT4="`, data)
+	}
+}
@@ -0,0 +1,220 @@
+package millennium
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// CanaryBodyLimit is the maximum number of bytes of each body captured by
+// WithCanary, the rest is discarded
+const CanaryBodyLimit = 64 << 10
+
+// CanaryTrace holds the diagnostics of a request sampled by WithCanary.
+// Timings are of the last attempt, except Total, which goes from the first
+// attempt until the response body is closed.
+type CanaryTrace struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Err        error
+	Started    time.Time
+	Attempts   int
+
+	// DNS, Connect and TLS are zero when an idle connection was reused
+	DNS             time.Duration
+	Connect         time.Duration
+	TLS             time.Duration
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+	ConnReused      bool
+	RemoteAddr      string
+
+	// RequestBody and ResponseBody are redacted by the client
+	// RedactionPolicy and cut at CanaryBodyLimit
+	RequestBody  []byte
+	ResponseBody []byte
+}
+
+// CanaryHook receives the diagnostics of every sampled request
+type CanaryHook func(trace CanaryTrace)
+
+// WithCanary samples percent (0 to 100) of the requests to be sent with
+// extra diagnostics, like connection timings and the captured bodies,
+// passed to hook once the response body is closed. The remaining requests
+// do not pay for the tracing.
+func WithCanary(percent float64, hook CanaryHook) Option {
+	return func(m *Millennium) {
+		m.canary = &canarySampler{percent: percent, hook: hook}
+	}
+}
+
+// canarySampler is the sampler set by WithCanary
+type canarySampler struct {
+	percent float64
+	hook    CanaryHook
+}
+
+// sampled reports if the next request should be traced
+func (s *canarySampler) sampled() bool {
+	return s != nil && s.hook != nil && rand.Float64()*100 < s.percent
+}
+
+// canaryTrace collects the diagnostics of a single request
+type canaryTrace struct {
+	mu    sync.Mutex
+	trace CanaryTrace
+
+	dnsStart, connectStart, tlsStart, wrote time.Time
+}
+
+// start returns the tracer of a request sampled by s
+func (s *canarySampler) start(method string) *canaryTrace {
+	return &canaryTrace{trace: CanaryTrace{Method: method, Started: time.Now()}}
+}
+
+// clientTrace returns the httptrace hooks recording the timings of each
+// attempt, replacing the ones of the previous attempt
+func (c *canaryTrace) clientTrace() *httptrace.ClientTrace {
+	since := func(start time.Time) time.Duration {
+		if start.IsZero() {
+			return 0
+		}
+		return time.Since(start)
+	}
+
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.trace.Attempts++
+			c.trace.DNS, c.trace.Connect, c.trace.TLS, c.trace.TimeToFirstByte = 0, 0, 0, 0
+			c.dnsStart, c.connectStart, c.tlsStart, c.wrote = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.trace.ConnReused = info.Reused
+			if info.Conn != nil {
+				c.trace.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.trace.DNS = since(c.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.trace.Connect = since(c.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.trace.TLS = since(c.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.wrote = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.trace.TimeToFirstByte = since(c.wrote)
+		},
+	}
+}
+
+// finish sends the diagnostics to hook once the response body is closed
+func (c *canaryTrace) finish(hook CanaryHook, policy *RedactionPolicy, req *retryablehttp.Request, res *http.Response, err error) *http.Response {
+	c.mu.Lock()
+	c.trace.URL = policy.redactURL(req.URL).String()
+	if body, _ := req.BodyBytes(); len(body) > 0 {
+		c.trace.RequestBody = policy.Redact(limitBody(body))
+	}
+	c.mu.Unlock()
+
+	done := func(body []byte) {
+		c.mu.Lock()
+		trace := c.trace
+		c.mu.Unlock()
+
+		trace.Err = err
+		trace.Total = time.Since(trace.Started)
+		if res != nil {
+			trace.StatusCode = res.StatusCode
+		}
+		if len(body) > 0 {
+			trace.ResponseBody = policy.Redact(body)
+		}
+
+		hook(trace)
+	}
+
+	if err != nil {
+		done(nil)
+		return res
+	}
+
+	body := &canaryBody{ReadCloser: res.Body}
+	body.done = func() { done(body.buf.Bytes()) }
+	res.Body = body
+	return res
+}
+
+// limitBody cuts body at CanaryBodyLimit
+func limitBody(body []byte) []byte {
+	if len(body) > CanaryBodyLimit {
+		return body[:CanaryBodyLimit]
+	}
+	return body
+}
+
+// canaryBody copies up to CanaryBodyLimit bytes of the response body while
+// it is read
+type canaryBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func()
+	once sync.Once
+}
+
+func (b *canaryBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := CanaryBodyLimit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	return n, err
+}
+
+func (b *canaryBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
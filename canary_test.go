@@ -0,0 +1,76 @@
+package millennium
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCanary(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Percent float64
+		Traces  int
+	}{
+		{Name: "all sampled", Percent: 100, Traces: 4},
+		{Name: "none sampled", Percent: 0, Traces: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var mu sync.Mutex
+			var traces []CanaryTrace
+
+			client, err := NewClient(context.Background(), serverAddr, 5*time.Second, WithCanary(c.Percent, func(trace CanaryTrace) {
+				mu.Lock()
+				defer mu.Unlock()
+				traces = append(traces, trace)
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				var r interface{}
+				if _, err := client.Get("test.success.GET", nil, &r); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := client.Post("test.success.POST", []byte(`{"produto":1,"senha":"segredo"}`), &r); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(traces) != c.Traces {
+				t.Fatalf("Expected %d traces but got %d", c.Traces, len(traces))
+			}
+
+			for _, trace := range traces {
+				if !strings.HasPrefix(trace.Method, "test.success") || trace.StatusCode != 200 || trace.Attempts != 1 || trace.Err != nil {
+					t.Errorf("Unexpected trace %+v", trace)
+				}
+
+				if trace.Total <= 0 || trace.RemoteAddr == "" || !strings.Contains(trace.URL, "/api/test") {
+					t.Errorf("Expected timings and address in trace %+v", trace)
+				}
+
+				if len(trace.ResponseBody) == 0 {
+					t.Error("Expected the response body to be captured")
+				}
+
+				if strings.Contains(string(trace.RequestBody), "segredo") {
+					t.Errorf("Expected the request body to be redacted but got %s", trace.RequestBody)
+				}
+			}
+
+			if c.Traces > 0 && !traces[len(traces)-1].ConnReused {
+				t.Error("Expected the connection to be reused")
+			}
+		})
+	}
+}
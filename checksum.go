@@ -0,0 +1,94 @@
+package millennium
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrChecksumMismatch is returned when the response body does not match the
+// checksum sent by the server, usually because it was truncated
+var ErrChecksumMismatch = errors.New("response checksum mismatch")
+
+// ContentDigestHeader is the standard header (RFC 9530) carrying the body digest
+const ContentDigestHeader = "Content-Digest"
+
+// ResponseMeta holds information about the response of a request.
+// It is filled when a pointer to it is set in RequestMethod.Meta.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+
+	// Checksum is the hex encoded SHA-256 of the raw response body
+	Checksum string
+
+	// Duration of the request, from the first attempt until the response
+	// body was decoded
+	Duration time.Duration
+
+	// Retries is the number of attempts made after the first one
+	Retries int
+}
+
+// WithChecksumVerification compares the SHA-256 of every response body with
+// the one sent by the server in header, as a header or a trailer.
+// The value can be hex or base64 encoded, or in the Content-Digest format
+// (sha-256=:base64:). Responses without the header are not verified.
+func WithChecksumVerification(header string) Option {
+	return func(m *Millennium) {
+		m.checksumHeader = header
+	}
+}
+
+// verifyChecksum compares the body checksum against the server one
+func (m *Millennium) verifyChecksum(res *http.Response, sum []byte) error {
+	if m.checksumHeader == "" {
+		return nil
+	}
+
+	// Trailers are only available after the body is read
+	expected := res.Trailer.Get(m.checksumHeader)
+	if expected == "" {
+		expected = res.Header.Get(m.checksumHeader)
+	}
+
+	if expected == "" {
+		return nil
+	}
+
+	if !checksumMatches(expected, sum) {
+		return fmt.Errorf("%w: expected %s but got %s", ErrChecksumMismatch, expected, hex.EncodeToString(sum))
+	}
+
+	return nil
+}
+
+// checksumMatches compares sum with an expected value in any supported format
+func checksumMatches(expected string, sum []byte) bool {
+	expected = strings.TrimSpace(expected)
+
+	// Content-Digest may carry multiple algorithms: sha-256=:...:, sha-512=:...:
+	if strings.Contains(expected, "=:") {
+		for _, digest := range strings.Split(expected, ",") {
+			algorithm, value, ok := strings.Cut(strings.TrimSpace(digest), "=")
+			if ok && strings.EqualFold(algorithm, "sha-256") {
+				return strings.Trim(value, ":") == base64.StdEncoding.EncodeToString(sum)
+			}
+		}
+		return false
+	}
+
+	return strings.EqualFold(expected, hex.EncodeToString(sum)) ||
+		expected == base64.StdEncoding.EncodeToString(sum)
+}
+
+// checksum returns the SHA-256 of body
+func checksum(body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return sum[:]
+}
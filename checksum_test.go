@@ -0,0 +1,77 @@
+package millennium
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChecksumVerification(t *testing.T) {
+	body := []byte(`{"odata.count":1,"value":[{"number":1}]}`)
+	sum := sha256.Sum256(body)
+
+	cases := []struct {
+		Name        string
+		Header      string
+		Trailer     string
+		ExpectError bool
+	}{
+		{Name: "no checksum"},
+		{Name: "hex", Header: hex.EncodeToString(sum[:])},
+		{Name: "base64", Header: base64.StdEncoding.EncodeToString(sum[:])},
+		{Name: "content digest", Header: "sha-512=:abc:, sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"},
+		{Name: "trailer", Trailer: hex.EncodeToString(sum[:])},
+		{Name: "mismatch", Header: hex.EncodeToString(make([]byte, 32)), ExpectError: true},
+		{Name: "trailer mismatch", Trailer: "sha-256=:AAAA:", ExpectError: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if c.Header != "" {
+					w.Header().Set(ContentDigestHeader, c.Header)
+				}
+				if c.Trailer != "" {
+					w.Header().Set("Trailer", ContentDigestHeader)
+				}
+				_, _ = w.Write(body)
+				if c.Trailer != "" {
+					w.Header().Set(ContentDigestHeader, c.Trailer)
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithChecksumVerification(ContentDigestHeader))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				res  interface{}
+				meta ResponseMeta
+			)
+
+			err = client.Request(RequestMethod{
+				HTTPMethod: GET,
+				Method:     "test",
+				Response:   &res,
+				Meta:       &meta,
+			})
+
+			if errors.Is(err, ErrChecksumMismatch) != c.ExpectError {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			if meta.Checksum != hex.EncodeToString(sum[:]) || meta.StatusCode != http.StatusOK {
+				t.Errorf("Unexpected meta %+v", meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package millennium
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Clock is the source of time used by the client to wait between retries.
+// It can be replaced in tests by a fake clock, like millenniumtest.FakeClock,
+// to avoid real sleeps.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock makes the client wait between retries using clock. The backoff
+// policy of the client is kept, only the waiting goes through the clock.
+func WithClock(clock Clock) Option {
+	return func(m *Millennium) {
+		m.clock = clock
+		m.backoff = m.Client.Backoff
+		m.Client.Backoff = noBackoff
+		m.Client.PrepareRetry = m.prepareRetry
+	}
+}
+
+// noBackoff lets the client wait between retries in prepareRetry instead
+func noBackoff(_, _ time.Duration, _ int, _ *http.Response) time.Duration {
+	return 0
+}
+
+// prepareRetry waits before a retry using the client clock
+func (m *Millennium) prepareRetry(req *http.Request) error {
+	rec := attemptRecorderFrom(req.Context())
+	if rec == nil {
+		return nil
+	}
+
+	backoff := m.backoff
+	if backoff == nil {
+		backoff = honorRetryAfter(retryablehttp.DefaultBackoff)
+	}
+
+	attempts, last := rec.last()
+	wait := backoff(m.Client.RetryWaitMin, m.Client.RetryWaitMax, attempts-1, last)
+
+	select {
+	case <-m.clock.After(wait):
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
@@ -0,0 +1,104 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WithTimeout replaces the timeout of the client, mostly useful with Clone
+func WithTimeout(timeout time.Duration) Option {
+	return func(m *Millennium) {
+		m.Timeout = timeout
+	}
+}
+
+// WithContext replaces the context of the client, mostly useful with Clone
+func WithContext(ctx context.Context) Option {
+	return func(m *Millennium) {
+		m.Context = ctx
+	}
+}
+
+// WithDefaultParams sends params with every request, unless the request
+// sets the same parameter, like a default company filter
+func WithDefaultParams(params url.Values) Option {
+	return func(m *Millennium) {
+		m.defaultParams = cloneParams(params)
+	}
+}
+
+// Clone returns a new client for the same server, with a copy of the http
+// client and transport and copying the credentials, session included,
+// headers, retry settings, classifier and clock, method configurations,
+// auth layers, host override and default params, then applies opts to it.
+// Options applied to the clone never change the original client.
+// It spawns per job or per tenant clients without logging in again.
+// Other options, hooks and state, like quotas and health, are not copied.
+func (m *Millennium) Clone(opts ...Option) *Millennium {
+	clone := &Millennium{
+		ServerAddr:    m.serverAddr(),
+		Context:       m.Context,
+		Timeout:       m.Timeout,
+		headers:       m.headerSnapshot(),
+		credentials:   m.getCredentials(),
+		redaction:     m.redaction,
+		authLayers:    append([]AuthLayer(nil), m.authLayers...),
+		hostOverride:  m.hostOverride,
+		defaultParams: cloneParams(m.defaultParams),
+
+		retryClassifier: m.retryClassifier,
+		methods:         m.methodConfigs(),
+	}
+
+	clone.Client = clone.setClient()
+	clone.Client.HTTPClient = cloneHTTPClient(m.Client.HTTPClient)
+	clone.Client.Logger = m.Client.Logger
+	clone.Client.RetryMax = m.Client.RetryMax
+	clone.Client.RetryWaitMin = m.Client.RetryWaitMin
+	clone.Client.RetryWaitMax = m.Client.RetryWaitMax
+	clone.Client.Backoff = m.Client.Backoff
+
+	if m.clock != nil {
+		clone.clock = m.clock
+		clone.backoff = m.backoff
+		clone.Client.PrepareRetry = clone.prepareRetry
+	}
+
+	// A session not requested yet is requested by the clone itself
+	if clone.credentials.AuthType == Session && clone.credentials.Session == "" {
+		clone.setSessionPending()
+	}
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	return clone
+}
+
+// cloneHTTPClient returns a copy of client, with its own copy of the
+// transport when it is an *http.Transport, so changes to the copy, like the
+// redirect policy or the TLS settings, don't reach client
+func cloneHTTPClient(client *http.Client) *http.Client {
+	clone := *client
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		clone.Transport = transport.Clone()
+	}
+
+	return &clone
+}
+
+// methodConfigs returns a copy of the configuration of each method
+func (m *Millennium) methodConfigs() map[string]MethodConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	methods := make(map[string]MethodConfig, len(m.methods))
+	for method, config := range m.methods {
+		methods[method] = config
+	}
+
+	return methods
+}
@@ -0,0 +1,145 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	var logins int32
+	var query atomic.Value
+	query.Store(url.Values{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+	})
+	mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+		query.Store(r.URL.Query())
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("WTS-Session") == "" || r.Header.Get("X-Job") != "sync" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessão inválida"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithAuthLayers(HeaderAuthLayer("X-Job", "sync")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clone := client.Clone(
+		WithTimeout(time.Second),
+		WithContext(ctx),
+		WithDefaultParams(url.Values{"empresa": {"2"}}),
+	)
+
+	if clone.Timeout != time.Second || clone.Context != ctx || client.Timeout != 5*time.Second {
+		t.Error("Expected the clone to override timeout and context only")
+	}
+
+	cases := []struct {
+		Name    string
+		Params  url.Values
+		Empresa string
+	}{
+		{Name: "default params", Empresa: "2"},
+		{Name: "request params", Params: url.Values{"empresa": {"3"}}, Empresa: "3"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var r interface{}
+			if _, err := clone.Get("test", c.Params, &r); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := query.Load().(url.Values).Get("empresa"); got != c.Empresa {
+				t.Errorf("Expected empresa %q but got %q", c.Empresa, got)
+			}
+		})
+	}
+
+	if atomic.LoadInt32(&logins) != 1 {
+		t.Errorf("Expected the clone to reuse the session but got %d logins", logins)
+	}
+
+	// The original client is not changed by the clone options
+	var r interface{}
+	if _, err := client.Get("test", nil, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := query.Load().(url.Values).Get("empresa"); got != "" {
+		t.Errorf("Expected no default params on the original client but got %q", got)
+	}
+}
+
+func TestCloneIsolation(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusInternalServerError, `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Falha"}}}`)
+
+	classifier := RetryClassifierFunc(func(ctx context.Context, res *http.Response, resErr *ResponseError, err error) (bool, error) {
+		return false, nil
+	})
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRetryClassifier(classifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Configure("produtos.lista", MethodConfig{PageSize: 7})
+
+	clone := client.Clone(WithRedirectPolicy(RedirectError), WithHostOverride("millennium.example.com"))
+
+	redirect, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if client.Client.HTTPClient == clone.Client.HTTPClient || client.Client.HTTPClient.CheckRedirect(redirect, []*http.Request{redirect}) != nil {
+		t.Error("Expected the redirect policy of the clone not to reach the original client")
+	}
+
+	if tlsConfig := client.Client.HTTPClient.Transport.(*http.Transport).TLSClientConfig; tlsConfig != nil && tlsConfig.ServerName != "" {
+		t.Errorf("Expected the host override of the clone not to reach the original client but got %q", tlsConfig.ServerName)
+	}
+
+	if err := clone.Login("test", "test", NTLM); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.getCredentials().AuthType == NTLM {
+		t.Error("Expected the login of the clone not to reach the original client")
+	}
+
+	if got := clone.methodConfig("produtos.lista").PageSize; got != 7 {
+		t.Errorf("Expected the method configuration to be copied but got page size %d", got)
+	}
+
+	// The classifier of the original client is kept, so the error is not retried
+	clone = client.Clone()
+	var r interface{}
+	if _, err := clone.Get("produtos.lista", url.Values{}, &r); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("Expected the retry classifier to be copied but got %d hits", got)
+	}
+}
@@ -0,0 +1,70 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// ErrClosed is returned by requests made after the client is closed
+var ErrClosed = errors.New("millennium client is closed")
+
+// closingKey marks the context of the requests made by Close itself, which
+// are sent after the client is closed
+type closingKey struct{}
+
+// begin registers a new in-flight request bound to ctx, unless the client
+// is closed
+func (m *Millennium) begin(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed && ctx.Value(closingKey{}) == nil {
+		return ErrClosed
+	}
+
+	m.inflight.Add(1)
+	return nil
+}
+
+// Close shuts the client down. New requests fail with ErrClosed, while the
+// in-flight ones are waited until ctx is done. Idle connections are closed
+// in the end, even if the in-flight requests did not finish in time.
+// The session keepalive, if any, is stopped first. Once the in-flight
+// requests are done, the WTS session is ended on Millennium, on a best
+// effort basis bounded by ctx; it is left to expire when they are not.
+func (m *Millennium) Close(ctx context.Context) error {
+	m.StopSessionKeepAlive()
+
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+		m.endSession(ctx)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	m.Client.HTTPClient.CloseIdleConnections()
+
+	return err
+}
+
+// endSession ends the WTS session of a closed client, logging the failures
+func (m *Millennium) endSession(ctx context.Context) {
+	if err := m.Logout(context.WithValue(ctx, closingKey{}, true)); err != nil {
+		if logger, ok := m.Client.Logger.(retryablehttp.Logger); ok {
+			logger.Printf("[WARN] unable to end the Millennium session: %v", err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	client := NewTestClient(t)
+
+	// Keep a request in-flight by not consuming the stream
+	records, errs := client.Stream(context.Background(), "test.stream", url.Values{})
+	<-records
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded but got %v", err)
+	}
+
+	for range records {
+	}
+
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("Expected drained client but got %v", err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed but got %v", err)
+	}
+}
+
+func TestCloseLogout(t *testing.T) {
+	var (
+		logouts int32
+		slow    int32
+	)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+	})
+	mux.HandleFunc("/api/logout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("WTS-Session") != "" {
+			atomic.AddInt32(&logouts, 1)
+		}
+	})
+	mux.HandleFunc("/api/test.slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+
+		// The session is still valid while the request is in flight
+		if atomic.LoadInt32(&logouts) == 0 {
+			atomic.AddInt32(&slow, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	inflight := make(chan error, 1)
+	go func() {
+		var r interface{}
+		_, err := client.Get("test.slow", url.Values{}, &r)
+		inflight <- err
+	}()
+	<-started
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close(context.Background()) }()
+
+	// New requests are rejected while Close waits for the in-flight one
+	var r interface{}
+	for {
+		_, err := client.Get("test.none", url.Values{}, &r)
+		if errors.Is(err, ErrClosed) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := atomic.LoadInt32(&logouts); got != 0 {
+		t.Errorf("Expected no logout while a request is in flight but got %d", got)
+	}
+
+	close(release)
+	if err := <-inflight; err != nil {
+		t.Error(err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := atomic.LoadInt32(&logouts); got != 1 || atomic.LoadInt32(&slow) != 1 {
+		t.Errorf("Expected the session to be ended once after the in-flight request but got %d logouts", got)
+	}
+
+	if client.getCredentials().Session != "" {
+		t.Error("Expected the session to be forgotten")
+	}
+}
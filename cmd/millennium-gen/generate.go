@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"unicode"
+
+	millennium "github.com/fabiomatavelli/millennium-go"
+)
+
+// edmTypes are the Go types of the EDM primitive types. Dates are kept as
+// strings, as Millennium sends them without the time zone required by
+// time.Time.
+var edmTypes = map[string]string{
+	"Edm.String":         "string",
+	"Edm.Guid":           "string",
+	"Edm.DateTime":       "string",
+	"Edm.DateTimeOffset": "string",
+	"Edm.Date":           "string",
+	"Edm.Time":           "string",
+	"Edm.Boolean":        "bool",
+	"Edm.Byte":           "int",
+	"Edm.SByte":          "int",
+	"Edm.Int16":          "int",
+	"Edm.Int32":          "int",
+	"Edm.Int64":          "int64",
+	"Edm.Single":         "float64",
+	"Edm.Double":         "float64",
+	"Edm.Decimal":        "float64",
+	"Edm.Binary":         "[]byte",
+}
+
+// generator writes the structs of the methods, each type once
+type generator struct {
+	metadata *millennium.Metadata
+	shapes   map[string]map[string]millennium.FieldShape
+	buf      bytes.Buffer
+
+	// types are the Go names of the types already generated or queued,
+	// by qualified name
+	types map[string]string
+	queue []queuedType
+
+	brNumber bool
+}
+
+// queuedType is a type to be generated, with the method and path of the
+// responses whose shapes refine its fields
+type queuedType struct {
+	t      millennium.MetadataType
+	method string
+	path   string
+}
+
+// generate returns the formatted Go source of the structs of methods, all
+// of them when empty
+func generate(metadata *millennium.Metadata, methods []string, pkg string, shapes map[string]map[string]millennium.FieldShape) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package %q", pkg)
+	}
+
+	g := &generator{metadata: metadata, shapes: shapes, types: map[string]string{}}
+
+	selected := metadata.Methods
+	if len(methods) > 0 {
+		selected = nil
+		for _, name := range methods {
+			method, ok := metadata.Method(name)
+			if !ok {
+				return nil, fmt.Errorf("method %s not found in $metadata", name)
+			}
+			selected = append(selected, method)
+		}
+	}
+
+	for _, method := range selected {
+		g.writeMethod(method)
+	}
+
+	for len(g.queue) > 0 {
+		next := g.queue[0]
+		g.queue = g.queue[1:]
+		g.writeType(next)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by millennium-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if g.brNumber {
+		fmt.Fprintf(&src, "import millennium %q\n\n", "github.com/fabiomatavelli/millennium-go")
+	}
+	src.Write(g.buf.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// writeMethod writes the struct of the parameters of method, queuing the
+// types it returns
+func (g *generator) writeMethod(method millennium.MetadataFunction) {
+	name := goName(method.Name) + "Params"
+	returns := g.goType(method.ReturnType, method.Name, "")
+
+	fmt.Fprintf(&g.buf, "// %s are the parameters of %s", name, method.Name)
+	if returns != "" {
+		fmt.Fprintf(&g.buf, ", which returns %s", returns)
+	}
+	fmt.Fprintf(&g.buf, "\ntype %s struct {\n", name)
+	// Shapes are recorded from the responses, not the parameters
+	g.writeFields(method.Parameters, "", "")
+	fmt.Fprintf(&g.buf, "}\n\n")
+}
+
+// writeType writes the struct of an entity or complex type
+func (g *generator) writeType(q queuedType) {
+	t := q.t
+	name := g.types[t.Namespace+"."+t.Name]
+
+	fmt.Fprintf(&g.buf, "// %s is the %s.%s type\ntype %s struct {\n", name, t.Namespace, t.Name, name)
+	g.writeFields(t.Properties, q.method, q.path)
+	fmt.Fprintf(&g.buf, "}\n\n")
+}
+
+// writeFields writes the fields of a struct, the shapes of the fields of
+// method under path refining their types
+func (g *generator) writeFields(properties []millennium.MetadataProperty, method, path string) {
+	used := map[string]int{}
+
+	for _, p := range properties {
+		name := goName(p.Name)
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, used[name])
+		}
+
+		typ := g.goType(p.Type, method, path+p.Name)
+		if typ == "" {
+			typ = "interface{}"
+		}
+
+		// Numbers sent as strings by Millennium
+		if shape, ok := g.shapes[method][path+p.Name]; ok && shape.Types[millennium.FieldString] > 0 && (typ == "int" || typ == "int64" || typ == "float64") {
+			typ = "millennium.BRNumber"
+			g.brNumber = true
+		}
+
+		tag := p.Name
+		if p.Nullable {
+			tag += ",omitempty"
+		}
+
+		fmt.Fprintf(&g.buf, "\t%s %s `json:%q`\n", name, typ, tag)
+	}
+}
+
+// goType returns the Go type of an EDM type, queuing the entities and
+// complex types referenced, empty if unknown. field is the path of the
+// value in the responses of method, empty for the records themselves.
+func (g *generator) goType(edmType, method, field string) string {
+	if edmType == "" {
+		return ""
+	}
+
+	if inner, ok := strings.CutPrefix(edmType, "Collection("); ok {
+		if field != "" {
+			field += "[]"
+		}
+
+		elem := g.goType(strings.TrimSuffix(inner, ")"), method, field)
+		if elem == "" {
+			return ""
+		}
+		return "[]" + elem
+	}
+
+	if typ, ok := edmTypes[edmType]; ok {
+		return typ
+	}
+
+	if name, ok := g.types[edmType]; ok {
+		return name
+	}
+
+	for _, types := range [][]millennium.MetadataType{g.metadata.Entities, g.metadata.ComplexTypes} {
+		for _, t := range types {
+			if t.Namespace+"."+t.Name != edmType {
+				continue
+			}
+
+			name := g.uniqueName(goName(t.Name))
+			g.types[edmType] = name
+			path := ""
+			if field != "" {
+				path = field + "."
+			}
+
+			g.queue = append(g.queue, queuedType{t: t, method: method, path: path})
+			return name
+		}
+	}
+
+	return ""
+}
+
+// uniqueName returns name, numbered if already used by another type
+func (g *generator) uniqueName(name string) string {
+	taken := func(candidate string) bool {
+		for _, used := range g.types {
+			if used == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	unique := name
+	for i := 2; taken(unique); i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	return unique
+}
+
+// goName returns the exported Go name of a Millennium name, like
+// ProdutosLista for produtos.lista and DataEmissao for data_emissao
+func goName(name string) string {
+	var b strings.Builder
+
+	upper := true
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"go/format"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const metadata = `<?xml version="1.0" encoding="utf-8"?>
+<edmx:Edmx Version="1.0" xmlns:edmx="http://schemas.microsoft.com/ado/2007/06/edmx">
+  <edmx:DataServices>
+    <Schema Namespace="millenium" xmlns="http://schemas.microsoft.com/ado/2008/09/edm">
+      <EntityType Name="pedido_venda">
+        <Key><PropertyRef Name="pedidov"/></Key>
+        <Property Name="pedidov" Type="Edm.Int32" Nullable="false"/>
+        <Property Name="data_emissao" Type="Edm.DateTime"/>
+        <Property Name="total" Type="Edm.Decimal"/>
+        <Property Name="endereco" Type="millenium.endereco"/>
+        <Property Name="itens" Type="Collection(millenium.item)"/>
+      </EntityType>
+      <ComplexType Name="endereco">
+        <Property Name="cep" Type="Edm.String"/>
+      </ComplexType>
+      <ComplexType Name="item">
+        <Property Name="sku" Type="Edm.String" Nullable="false"/>
+        <Property Name="preco" Type="Edm.Double"/>
+      </ComplexType>
+      <EntityContainer Name="millenium">
+        <FunctionImport Name="pedido_venda.lista" ReturnType="Collection(millenium.pedido_venda)">
+          <Parameter Name="pedidov" Type="Edm.Int32"/>
+          <Parameter Name="aprovado" Type="Edm.Boolean" Nullable="false"/>
+        </FunctionImport>
+        <FunctionImport Name="pedido_venda.total" ReturnType="Edm.Decimal"/>
+      </EntityContainer>
+    </Schema>
+  </edmx:DataServices>
+</edmx:Edmx>`
+
+const generated = `// Code generated by millennium-gen. DO NOT EDIT.
+
+package erp
+
+import millennium "github.com/fabiomatavelli/millennium-go"
+
+// PedidoVendaListaParams are the parameters of pedido_venda.lista, which returns []PedidoVenda
+type PedidoVendaListaParams struct {
+	Pedidov  int  ` + "`json:\"pedidov,omitempty\"`" + `
+	Aprovado bool ` + "`json:\"aprovado\"`" + `
+}
+
+// PedidoVenda is the millenium.pedido_venda type
+type PedidoVenda struct {
+	Pedidov     int       ` + "`json:\"pedidov\"`" + `
+	DataEmissao string    ` + "`json:\"data_emissao,omitempty\"`" + `
+	Total       float64   ` + "`json:\"total,omitempty\"`" + `
+	Endereco    Endereco  ` + "`json:\"endereco,omitempty\"`" + `
+	Itens       []Item    ` + "`json:\"itens,omitempty\"`" + `
+}
+
+// Endereco is the millenium.endereco type
+type Endereco struct {
+	Cep string ` + "`json:\"cep,omitempty\"`" + `
+}
+
+// Item is the millenium.item type
+type Item struct {
+	Sku   string              ` + "`json:\"sku\"`" + `
+	Preco millennium.BRNumber ` + "`json:\"preco,omitempty\"`" + `
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"metadata.xml": metadata,
+		"shapes.json":  `{"pedido_venda.lista":{"itens[].preco":{"seen":2,"types":{"number":1,"string":1}},"total":{"seen":2,"types":{"number":2}}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := filepath.Join(dir, "models.go")
+	args := []string{"-metadata", filepath.Join(dir, "metadata.xml"), "-shapes", filepath.Join(dir, "shapes.json"), "-package", "erp", "-o", output, "pedido_venda.lista"}
+	if err := run(context.Background(), args, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := formatExpected(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(src) != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, src)
+	}
+}
+
+func TestGenerateFromServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/$metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(metadata))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"-server", server.URL}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"package models", "type PedidoVendaListaParams struct", "type PedidoVendaTotalParams struct", "which returns float64", "type PedidoVenda struct"} {
+		if !bytes.Contains(out.Bytes(), []byte(expected)) {
+			t.Errorf("Expected %q in\n%s", expected, out.String())
+		}
+	}
+
+	if bytes.Contains(out.Bytes(), []byte("import")) {
+		t.Errorf("Expected no imports without shapes in\n%s", out.String())
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "metadata.xml")
+	if err := os.WriteFile(file, []byte(metadata), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name string
+		Args []string
+	}{
+		{Name: "unknown method", Args: []string{"-metadata", file, "produtos.lista"}},
+		{Name: "invalid package", Args: []string{"-metadata", file, "-package", "my-models"}},
+		{Name: "no source", Args: []string{"-server", ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := run(context.Background(), c.Args, &bytes.Buffer{}); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+// formatExpected aligns the expected source like gofmt
+func formatExpected(src string) (string, error) {
+	formatted, err := format.Source([]byte(src))
+	return string(formatted), err
+}
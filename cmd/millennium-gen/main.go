@@ -0,0 +1,119 @@
+// Command millennium-gen generates Go structs from the $metadata document
+// of a Millennium server, so the request and response types of the methods
+// used by an integration don't need to be written by hand.
+//
+// Usage:
+//
+//	millennium-gen [flags] METHOD...
+//
+// For each METHOD, like produtos.lista, it generates a struct with its
+// parameters, named after the method, like ProdutosListaParams, and the
+// structs of the entities it returns and of the types they reference, with
+// json tags. All the methods are generated when none is given.
+//
+// The $metadata is read from a saved copy given by -metadata, like one saved
+// by millennium schema snapshot, or requested from the server. The server
+// and credentials are read from the flags or from the MILLENNIUM_SERVER,
+// MILLENNIUM_USERNAME and MILLENNIUM_PASSWORD variables.
+//
+// Numeric fields that Millennium sends as strings in production, as
+// recorded by millennium.ShapeRecorder and saved as JSON to the file given
+// by -shapes, are generated as millennium.BRNumber.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	millennium "github.com/fabiomatavelli/millennium-go"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "millennium-gen:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("millennium-gen", flag.ContinueOnError)
+	server := flags.String("server", os.Getenv("MILLENNIUM_SERVER"), "Millennium server address, like https://127.0.0.1:6018")
+	username := flags.String("username", os.Getenv("MILLENNIUM_USERNAME"), "Millennium username")
+	password := flags.String("password", os.Getenv("MILLENNIUM_PASSWORD"), "Millennium password")
+	auth := flags.String("auth", string(millennium.Session), "authentication type: SESSION, NTLM, BASIC or STATELESS")
+	timeout := flags.Duration("timeout", time.Minute, "request timeout")
+	metadataFile := flags.String("metadata", "", "saved $metadata document, instead of requesting it from the server")
+	shapesFile := flags.String("shapes", "", "JSON file with the field shapes recorded by millennium.ShapeRecorder")
+	pkg := flags.String("package", "models", "package of the generated file")
+	output := flags.String("o", "", "output file, the standard output if empty")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var metadata *millennium.Metadata
+
+	if *metadataFile != "" {
+		doc, err := os.ReadFile(*metadataFile)
+		if err != nil {
+			return err
+		}
+
+		if metadata, err = millennium.ParseMetadata(doc); err != nil {
+			return fmt.Errorf("%s: %w", *metadataFile, err)
+		}
+	} else {
+		if *server == "" {
+			return errors.New("the server address or the -metadata file is required")
+		}
+
+		m, err := millennium.NewClient(ctx, *server, *timeout)
+		if err != nil {
+			return err
+		}
+		defer m.Close(ctx)
+
+		if *username != "" {
+			if err := m.Login(*username, *password, millennium.AuthType(strings.ToUpper(*auth))); err != nil {
+				return fmt.Errorf("unable to login: %w", err)
+			}
+		}
+
+		if metadata, err = m.Metadata(ctx); err != nil {
+			return err
+		}
+	}
+
+	var shapes map[string]map[string]millennium.FieldShape
+	if *shapesFile != "" {
+		data, err := os.ReadFile(*shapesFile)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, &shapes); err != nil {
+			return fmt.Errorf("%s: %w", *shapesFile, err)
+		}
+	}
+
+	src, err := generate(metadata, flags.Args(), *pkg, shapes)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = out.Write(src)
+		return err
+	}
+
+	return os.WriteFile(*output, src, 0o644)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// enumSpec is the SPEC file of the enums command, like:
+//
+//	{
+//	  "package": "erp",
+//	  "enums": [{
+//	    "name": "OrderStatus",
+//	    "type": "int",
+//	    "doc": "OrderStatus is the status of an order",
+//	    "values": [
+//	      {"name": "Open", "value": 1},
+//	      {"name": "Invoiced", "value": 2, "doc": "the order was invoiced"}
+//	    ]
+//	  }]
+//	}
+//
+// Constants are named after the enum and the value, like OrderStatusOpen.
+// The type is int or string.
+type enumSpec struct {
+	Package string `json:"package"`
+	Enums   []enum `json:"enums"`
+}
+
+type enum struct {
+	Name   string      `json:"name"`
+	Type   string      `json:"type"`
+	Doc    string      `json:"doc"`
+	Values []enumValue `json:"values"`
+}
+
+type enumValue struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+	Doc   string          `json:"doc"`
+}
+
+// enumsCommand generates the enums of a SPEC file
+func enumsCommand(args []string) error {
+	flags := flag.NewFlagSet("enums", flag.ContinueOnError)
+	pkg := flags.String("package", "", "package of the generated file, replacing the one in SPEC")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		return errors.New("enums expects the SPEC and OUTPUT files")
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var spec enumSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+
+	if *pkg != "" {
+		spec.Package = *pkg
+	}
+
+	src, err := generateEnums(spec)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(flags.Arg(1), src, 0o644)
+}
+
+// generateEnums returns the formatted Go source of the enums
+func generateEnums(spec enumSpec) ([]byte, error) {
+	if !token.IsIdentifier(spec.Package) {
+		return nil, fmt.Errorf("invalid package %q", spec.Package)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by millennium enums. DO NOT EDIT.\n\npackage %s\n\n", spec.Package)
+
+	imports := []string{"encoding/json", "fmt"}
+	for _, e := range spec.Enums {
+		if e.Type == "int" {
+			imports = append(imports, "strconv")
+			break
+		}
+	}
+
+	fmt.Fprintf(&buf, "import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&buf, "%q\n", path)
+	}
+	fmt.Fprintf(&buf, ")\n\n")
+
+	for _, e := range spec.Enums {
+		if err := writeEnum(&buf, e); err != nil {
+			return nil, err
+		}
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// writeEnum writes the type, constants and methods of e
+func writeEnum(buf *bytes.Buffer, e enum) error {
+	if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+		return fmt.Errorf("invalid enum name %q", e.Name)
+	}
+
+	if e.Type != "int" && e.Type != "string" {
+		return fmt.Errorf("enum %s: invalid type %q, expected int or string", e.Name, e.Type)
+	}
+
+	if len(e.Values) == 0 {
+		return fmt.Errorf("enum %s has no values", e.Name)
+	}
+
+	names := make([]string, len(e.Values))
+	literals := make([]string, len(e.Values))
+	seen := map[string]bool{}
+
+	for i, v := range e.Values {
+		names[i] = e.Name + v.Name
+		if !token.IsIdentifier(names[i]) || v.Name == "" {
+			return fmt.Errorf("enum %s: invalid value name %q", e.Name, v.Name)
+		}
+
+		literal, err := enumLiteral(e.Type, v.Value)
+		if err != nil {
+			return fmt.Errorf("enum %s: value %s: %w", e.Name, v.Name, err)
+		}
+		literals[i] = literal
+
+		if seen[names[i]] || seen[literal] {
+			return fmt.Errorf("enum %s: duplicate value %s", e.Name, v.Name)
+		}
+		seen[names[i]], seen[literal] = true, true
+	}
+
+	doc := e.Doc
+	if doc == "" {
+		doc = fmt.Sprintf("%s is a Millennium coded field", e.Name)
+	}
+	writeComment(buf, "", doc)
+	fmt.Fprintf(buf, "type %s %s\n\n", e.Name, e.Type)
+
+	fmt.Fprintf(buf, "// Values of %s\nconst (\n", e.Name)
+	for i, v := range e.Values {
+		writeComment(buf, "\t", v.Doc)
+		fmt.Fprintf(buf, "\t%s %s = %s\n", names[i], e.Name, literals[i])
+	}
+	fmt.Fprintf(buf, ")\n\n")
+
+	fmt.Fprintf(buf, "// String returns the name of the code\nfunc (v %s) String() string {\nswitch v {\n", e.Name)
+	for i, v := range e.Values {
+		fmt.Fprintf(buf, "case %s:\nreturn %q\n", names[i], v.Name)
+	}
+	fmt.Fprintf(buf, "}\n\nreturn fmt.Sprintf(\"%s(%%v)\", %s(v))\n}\n\n", e.Name, e.Type)
+
+	fmt.Fprintf(buf, "// Valid reports if v is a known code\nfunc (v %s) Valid() bool {\nswitch v {\ncase %s:\nreturn true\n}\n\nreturn false\n}\n\n", e.Name, strings.Join(names, ", "))
+
+	fmt.Fprintf(buf, "// UnmarshalJSON implements json.Unmarshaler, rejecting unknown codes\n")
+	fmt.Fprintf(buf, "func (v *%s) UnmarshalJSON(data []byte) error {\nif string(data) == \"null\" {\nreturn nil\n}\n\n", e.Name)
+	fmt.Fprintf(buf, "var code %s\n", e.Type)
+	if e.Type == "int" {
+		// Millennium sends some numeric codes as strings
+		fmt.Fprintf(buf, "var s string\nif err := json.Unmarshal(data, &s); err == nil {\nn, err := strconv.Atoi(s)\nif err != nil {\nreturn fmt.Errorf(\"invalid %s %%q\", s)\n}\ncode = n\n} else ", e.Name)
+	}
+	fmt.Fprintf(buf, "if err := json.Unmarshal(data, &code); err != nil {\nreturn err\n}\n\n")
+	fmt.Fprintf(buf, "if !%s(code).Valid() {\nreturn fmt.Errorf(\"invalid %s %%v\", code)\n}\n\n*v = %s(code)\nreturn nil\n}\n\n", e.Name, e.Name, e.Name)
+
+	return nil
+}
+
+// enumLiteral returns the Go literal of a value of the enum type
+func enumLiteral(typ string, value json.RawMessage) (string, error) {
+	if typ == "string" {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return "", fmt.Errorf("expected a string but got %s", value)
+		}
+		return strconv.Quote(s), nil
+	}
+
+	var n int
+	if err := json.Unmarshal(value, &n); err != nil {
+		return "", fmt.Errorf("expected an integer but got %s", value)
+	}
+	return strconv.Itoa(n), nil
+}
+
+// writeComment writes doc as a Go comment
+func writeComment(buf *bytes.Buffer, indent, doc string) {
+	if doc == "" {
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
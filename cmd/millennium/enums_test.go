@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const enumsSpec = `{
+  "package": "main",
+  "enums": [
+    {
+      "name": "OrderStatus",
+      "type": "int",
+      "doc": "OrderStatus is the status of an order",
+      "values": [
+        {"name": "Open", "value": 1},
+        {"name": "Invoiced", "value": 2, "doc": "the order was invoiced"}
+      ]
+    },
+    {
+      "name": "PaymentType",
+      "type": "string",
+      "values": [{"name": "Cash", "value": "DIN"}, {"name": "Card", "value": "CAR"}]
+    }
+  ]
+}`
+
+const enumsMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() {
+	var order struct {
+		Status  OrderStatus ` + "`json:\"status\"`" + `
+		Payment PaymentType ` + "`json:\"payment\"`" + `
+	}
+
+	for _, body := range []string{
+		` + "`{\"status\":2,\"payment\":\"CAR\"}`" + `,
+		` + "`{\"status\":\"1\",\"payment\":\"DIN\"}`" + `,
+		` + "`{\"status\":3}`" + `,
+		` + "`{\"payment\":\"PIX\"}`" + `,
+	} {
+		err := json.Unmarshal([]byte(body), &order)
+		fmt.Println(order.Status, order.Payment, err)
+	}
+
+	fmt.Println(OrderStatus(9), OrderStatus(9).Valid(), OrderStatusOpen.Valid())
+}
+`
+
+func TestEnums(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"spec.json": enumsSpec,
+		"main.go":   enumsMain,
+		"go.mod":    "module enums\n\ngo 1.21\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := run(context.Background(), []string{"enums", filepath.Join(dir, "spec.json"), filepath.Join(dir, "enums.go")}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+
+	expected := []string{
+		"Invoiced Card <nil>",
+		"Open Cash <nil>",
+		"Open Cash invalid OrderStatus 3",
+		"Open Cash invalid PaymentType PIX",
+		"OrderStatus(9) false true",
+	}
+
+	if got := strings.TrimSpace(string(out)); got != strings.Join(expected, "\n") {
+		t.Errorf("Expected\n%s\nbut got\n%s", strings.Join(expected, "\n"), got)
+	}
+}
+
+func TestEnumsInvalidSpec(t *testing.T) {
+	cases := []struct {
+		Name string
+		Spec enumSpec
+	}{
+		{Name: "package", Spec: enumSpec{Package: "my-package"}},
+		{Name: "unexported", Spec: enumSpec{Package: "erp", Enums: []enum{{Name: "status", Type: "int", Values: []enumValue{{Name: "Open", Value: []byte("1")}}}}}},
+		{Name: "type", Spec: enumSpec{Package: "erp", Enums: []enum{{Name: "Status", Type: "float", Values: []enumValue{{Name: "Open", Value: []byte("1")}}}}}},
+		{Name: "value type", Spec: enumSpec{Package: "erp", Enums: []enum{{Name: "Status", Type: "int", Values: []enumValue{{Name: "Open", Value: []byte(`"A"`)}}}}}},
+		{Name: "duplicate", Spec: enumSpec{Package: "erp", Enums: []enum{{Name: "Status", Type: "int", Values: []enumValue{{Name: "Open", Value: []byte("1")}, {Name: "Opened", Value: []byte("1")}}}}}},
+		{Name: "no values", Spec: enumSpec{Package: "erp", Enums: []enum{{Name: "Status", Type: "int"}}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if _, err := generateEnums(c.Spec); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Command millennium runs maintenance tasks against a Millennium server.
+//
+// Usage:
+//
+//	millennium schema snapshot [flags] FILE
+//	millennium schema diff [flags] FILE
+//	millennium enums [-package NAME] SPEC OUTPUT
+//
+// The schema snapshot command saves the $metadata document of the server to
+// FILE, and schema diff compares a live server against a saved snapshot,
+// printing the fields added (+), removed (-) and changed (~). It exits with
+// status 1 when the schemas differ, so it can be used before ERP upgrades.
+//
+// The enums command generates Go types for the coded fields listed in the
+// SPEC JSON file, like order status or payment conditions, with String,
+// Valid and JSON decoding rejecting unknown codes. Codes such as payment
+// conditions are registered per installation, so the lists are curated by
+// each integration. See enums.go for the SPEC format.
+//
+// The server and credentials are read from the flags or from the
+// MILLENNIUM_SERVER, MILLENNIUM_USERNAME and MILLENNIUM_PASSWORD variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	millennium "github.com/fabiomatavelli/millennium-go"
+)
+
+// errDrift is returned by schema diff when the schemas differ
+var errDrift = errors.New("schema drift detected")
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout)
+	if err == nil {
+		return
+	}
+
+	if !errors.Is(err, errDrift) && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "millennium:", err)
+	}
+
+	os.Exit(1)
+}
+
+const usage = `usage:
+  millennium schema snapshot [flags] FILE
+  millennium schema diff [flags] FILE
+  millennium enums [-package NAME] SPEC OUTPUT`
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) > 0 && args[0] == "enums" {
+		return enumsCommand(args[1:])
+	}
+
+	if len(args) < 2 || args[0] != "schema" {
+		return errors.New(usage)
+	}
+
+	switch args[1] {
+	case "snapshot":
+		return schemaCommand(ctx, args[1], args[2:], func(m *millennium.Millennium, file string) error {
+			return snapshot(ctx, m, file)
+		})
+	case "diff":
+		return schemaCommand(ctx, args[1], args[2:], func(m *millennium.Millennium, file string) error {
+			return diff(ctx, m, file, out)
+		})
+	}
+
+	return errors.New(usage)
+}
+
+// schemaCommand parses the flags of a schema subcommand and runs fn with a
+// client logged in the server
+func schemaCommand(ctx context.Context, name string, args []string, fn func(m *millennium.Millennium, file string) error) error {
+	flags := flag.NewFlagSet("schema "+name, flag.ContinueOnError)
+	server := flags.String("server", os.Getenv("MILLENNIUM_SERVER"), "Millennium server address, like https://127.0.0.1:6018")
+	username := flags.String("username", os.Getenv("MILLENNIUM_USERNAME"), "Millennium username")
+	password := flags.String("password", os.Getenv("MILLENNIUM_PASSWORD"), "Millennium password")
+	auth := flags.String("auth", string(millennium.Session), "authentication type: SESSION, NTLM, BASIC or STATELESS")
+	timeout := flags.Duration("timeout", time.Minute, "request timeout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("schema %s expects the snapshot FILE", name)
+	}
+
+	if *server == "" {
+		return errors.New("the server address is required")
+	}
+
+	m, err := millennium.NewClient(ctx, *server, *timeout)
+	if err != nil {
+		return err
+	}
+	defer m.Close(ctx)
+
+	if *username != "" {
+		if err := m.Login(*username, *password, millennium.AuthType(strings.ToUpper(*auth))); err != nil {
+			return fmt.Errorf("unable to login: %w", err)
+		}
+	}
+
+	return fn(m, flags.Arg(0))
+}
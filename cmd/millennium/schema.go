@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+
+	millennium "github.com/fabiomatavelli/millennium-go"
+)
+
+// describe returns the type of the property as shown by schema diff
+func describe(p millennium.MetadataProperty) string {
+	if !p.Nullable {
+		return p.Type + " not null"
+	}
+
+	return p.Type
+}
+
+// fetchMetadata returns the $metadata document of the server
+func fetchMetadata(ctx context.Context, m *millennium.Millennium) ([]byte, error) {
+	res, err := m.Do(ctx, millennium.RequestMethod{HTTPMethod: millennium.GET, Method: millennium.MetadataMethod})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get $metadata: %s", res.Status)
+	}
+
+	return body, nil
+}
+
+// parseFields parses a $metadata document into the type of each field,
+// keyed by type and field names, like "produtos.descricao". Methods are
+// listed with their return type and each parameter.
+func parseFields(doc []byte) (map[string]string, error) {
+	metadata, err := millennium.ParseMetadata(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{}
+	for _, types := range [][]millennium.MetadataType{metadata.Entities, metadata.ComplexTypes} {
+		for _, t := range types {
+			for _, p := range t.Properties {
+				fields[t.Name+"."+p.Name] = describe(p)
+			}
+		}
+	}
+
+	for _, method := range metadata.Methods {
+		fields[method.Name+"()"] = method.ReturnType
+		for _, p := range method.Parameters {
+			fields[method.Name+"("+p.Name+")"] = describe(p)
+		}
+	}
+
+	return fields, nil
+}
+
+// diffFields returns the fields added (+), removed (-) and changed (~) from
+// old to current, sorted by name
+func diffFields(old, current map[string]string) []string {
+	var changes []string
+
+	for name, t := range current {
+		previous, ok := old[name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("+ %s %s", name, t))
+		case previous != t:
+			changes = append(changes, fmt.Sprintf("~ %s %s -> %s", name, previous, t))
+		}
+	}
+
+	for name, t := range old {
+		if _, ok := current[name]; !ok {
+			changes = append(changes, fmt.Sprintf("- %s %s", name, t))
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i][2:] < changes[j][2:]
+	})
+
+	return changes
+}
+
+// snapshot saves the $metadata of the server to file
+func snapshot(ctx context.Context, m *millennium.Millennium, file string) error {
+	doc, err := fetchMetadata(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	if _, err := parseFields(doc); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, doc, 0o644)
+}
+
+// diff prints the changes of the server $metadata since the snapshot in file
+func diff(ctx context.Context, m *millennium.Millennium, file string, out io.Writer) error {
+	saved, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	old, err := parseFields(saved)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	doc, err := fetchMetadata(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	current, err := parseFields(doc)
+	if err != nil {
+		return err
+	}
+
+	changes := diffFields(old, current)
+	for _, change := range changes {
+		fmt.Fprintln(out, change)
+	}
+
+	if len(changes) > 0 {
+		return errDrift
+	}
+
+	return nil
+}
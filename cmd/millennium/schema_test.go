@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const metadataV1 = `<?xml version="1.0" encoding="utf-8"?>
+<edmx:Edmx Version="1.0" xmlns:edmx="http://schemas.microsoft.com/ado/2007/06/edmx">
+  <edmx:DataServices>
+    <Schema Namespace="millenium" xmlns="http://schemas.microsoft.com/ado/2008/09/edm">
+      <EntityType Name="produtos">
+        <Property Name="produto" Type="Edm.Int32" Nullable="false"/>
+        <Property Name="descricao" Type="Edm.String"/>
+        <Property Name="preco" Type="Edm.Double"/>
+      </EntityType>
+      <EntityContainer Name="millenium">
+        <FunctionImport Name="produtos.lista" ReturnType="Collection(millenium.produtos)">
+          <Parameter Name="produto" Type="Edm.Int32"/>
+        </FunctionImport>
+      </EntityContainer>
+    </Schema>
+  </edmx:DataServices>
+</edmx:Edmx>`
+
+func TestSchemaSnapshotDiff(t *testing.T) {
+	var doc atomic.Value
+	doc.Store(metadataV1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/$metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(doc.Load().(string)))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "metadata.xml")
+
+	if err := run(context.Background(), []string{"schema", "snapshot", "-server", server.URL, file}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name    string
+		Doc     string
+		Changes []string
+	}{
+		{Name: "unchanged", Doc: metadataV1},
+		{
+			Name: "drift",
+			Doc: strings.NewReplacer(
+				`<Property Name="preco" Type="Edm.Double"/>`, `<Property Name="preco" Type="Edm.Decimal"/><Property Name="ncm" Type="Edm.String"/>`,
+				`<Property Name="descricao" Type="Edm.String"/>`, ``,
+				`<Parameter Name="produto" Type="Edm.Int32"/>`, `<Parameter Name="produto" Type="Edm.Int32" Nullable="false"/>`,
+			).Replace(metadataV1),
+			Changes: []string{
+				"- produtos.descricao Edm.String",
+				"~ produtos.lista(produto) Edm.Int32 -> Edm.Int32 not null",
+				"+ produtos.ncm Edm.String",
+				"~ produtos.preco Edm.Double -> Edm.Decimal",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			doc.Store(c.Doc)
+
+			var out bytes.Buffer
+			err := run(context.Background(), []string{"schema", "diff", "-server", server.URL, file}, &out)
+			if (len(c.Changes) > 0) != errors.Is(err, errDrift) {
+				t.Errorf("Expected drift to be %v but got %v", len(c.Changes) > 0, err)
+			}
+
+			if got := strings.TrimSpace(out.String()); got != strings.Join(c.Changes, "\n") {
+				t.Errorf("Expected changes\n%s\nbut got\n%s", strings.Join(c.Changes, "\n"), out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CoalesceHook is called with the result of each update sent by a Coalescer
+// when its window ends, as nobody is waiting for it
+type CoalesceHook func(key string, body []byte, err error)
+
+// Coalescer buffers the updates of the same key, like the stock or price of
+// a SKU, sending only the latest body of each key once per window, reducing
+// the writes of chatty upstream systems that update the same SKU several
+// times a second. Updates of the same key are never sent concurrently, so
+// the last body received is always the last one sent.
+type Coalescer struct {
+	m       *Millennium
+	request RequestMethod
+	window  time.Duration
+	hook    CoalesceHook
+
+	mu     sync.Mutex
+	keys   map[string]*coalescedKey
+	closed bool
+
+	// sends counts the updates being sent, idle is signaled when it drops
+	// to zero. Both are guarded by mu, as the sends are started by the
+	// window timers while Flush may be waiting.
+	sends int
+	idle  *sync.Cond
+}
+
+type coalescedKey struct {
+	body      []byte
+	ctx       context.Context
+	dirty     bool
+	scheduled bool
+	sending   bool
+}
+
+// NewCoalescer returns a Coalescer sending the updates as r, usually a POST
+// or PUT to the stock or price method, with the body of the update.
+// The first update of a key is sent after window, with any update received
+// meanwhile replacing it. hook can be nil.
+func (m *Millennium) NewCoalescer(r RequestMethod, window time.Duration, hook CoalesceHook) *Coalescer {
+	c := &Coalescer{
+		m:       m,
+		request: r,
+		window:  window,
+		hook:    hook,
+		keys:    map[string]*coalescedKey{},
+	}
+	c.idle = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Update buffers body as the latest value of key, replacing the one not
+// sent yet, and returns without waiting for it to be sent.
+// The update is sent with the values of ctx, but not bound to its
+// cancellation, as it outlives the call. It returns ErrClosed after Close.
+func (c *Coalescer) Update(ctx context.Context, key string, body []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	k, ok := c.keys[key]
+	if !ok {
+		k = &coalescedKey{}
+		c.keys[key] = k
+	}
+
+	k.body, k.ctx, k.dirty = body, context.WithoutCancel(ctx), true
+
+	if !k.scheduled && !k.sending {
+		c.schedule(key, k)
+	}
+
+	return nil
+}
+
+// Pending returns the number of keys with updates not sent yet
+func (c *Coalescer) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := 0
+	for _, k := range c.keys {
+		if k.dirty {
+			pending++
+		}
+	}
+
+	return pending
+}
+
+// Flush sends the buffered updates now, without waiting for their window,
+// and waits for the updates being sent. It returns the errors of the
+// updates it sent.
+func (c *Coalescer) Flush() error {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+
+	flush := func() {
+		c.mu.Lock()
+		for key, k := range c.keys {
+			if !k.dirty || k.sending {
+				continue
+			}
+
+			c.start(key, k, func(_ []byte, err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			})
+		}
+
+		for c.sends > 0 {
+			c.idle.Wait()
+		}
+		c.mu.Unlock()
+	}
+
+	// Keys being sent are flushed again once done, with the updates
+	// received meanwhile
+	flush()
+	flush()
+
+	return errors.Join(errs...)
+}
+
+// Close flushes the buffered updates, making any further Update fail with
+// ErrClosed
+func (c *Coalescer) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	return c.Flush()
+}
+
+// schedule sends the key when the window ends, c.mu must be held
+func (c *Coalescer) schedule(key string, k *coalescedKey) {
+	k.scheduled = true
+
+	go func() {
+		<-c.m.after(c.window)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		k.scheduled = false
+
+		// Flushed or being sent, which schedules it again when done
+		if !k.dirty || k.sending {
+			return
+		}
+
+		c.start(key, k, func(body []byte, err error) {
+			if c.hook != nil {
+				c.hook(key, body, err)
+			}
+		})
+	}()
+}
+
+// start sends the latest body of key in background, calling done with the
+// body sent and the result before the send is done. c.mu must be held.
+func (c *Coalescer) start(key string, k *coalescedKey, done func(body []byte, err error)) {
+	body, ctx := k.body, k.ctx
+	k.dirty, k.sending = false, true
+	c.sends++
+
+	go func() {
+		var response interface{}
+		r := c.request
+		r.Params = cloneParams(r.Params)
+		r.Body, r.Response, r.Context = body, &response, ctx
+
+		err := c.m.Request(r)
+		done(body, err)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		k.sending = false
+		switch {
+		case k.dirty && !k.scheduled:
+			// Updated while being sent
+			c.schedule(key, k)
+		case !k.dirty && !k.scheduled:
+			delete(c.keys, key)
+		}
+
+		c.sends--
+		if c.sends == 0 {
+			c.idle.Broadcast()
+		}
+	}()
+}
@@ -0,0 +1,165 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalescer(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan string, 10)
+	coalescer := client.NewCoalescer(RequestMethod{HTTPMethod: POST, Method: "estoques.atualiza"}, 50*time.Millisecond, func(key string, body []byte, err error) {
+		if err != nil {
+			t.Errorf("Expected no error for %s but got %v", key, err)
+		}
+		sent <- key + "=" + string(body)
+	})
+
+	sentBodies := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		got := append([]string(nil), bodies...)
+		sort.Strings(got)
+		bodies = nil
+		return got
+	}
+
+	t.Run("window", func(t *testing.T) {
+		for _, body := range []string{`{"saldo":1}`, `{"saldo":2}`, `{"saldo":3}`} {
+			if err := coalescer.Update(context.Background(), "SKU1", []byte(body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := coalescer.Update(context.Background(), "SKU2", []byte(`{"saldo":9}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		if pending := coalescer.Pending(); pending != 2 {
+			t.Errorf("Expected 2 pending keys but got %d", pending)
+		}
+
+		got := []string{<-sent, <-sent}
+		sort.Strings(got)
+
+		expected := []string{`SKU1={"saldo":3}`, `SKU2={"saldo":9}`}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("Expected %s but got %s", expected[i], got[i])
+			}
+		}
+
+		if got := sentBodies(); len(got) != 2 {
+			t.Errorf("Expected 2 requests but got %v", got)
+		}
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		if err := coalescer.Update(context.Background(), "SKU1", []byte(`{"saldo":4}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := coalescer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if pending := coalescer.Pending(); pending != 0 {
+			t.Errorf("Expected no pending keys but got %d", pending)
+		}
+
+		if got := sentBodies(); len(got) != 1 || got[0] != `{"saldo":4}` {
+			t.Errorf("Expected the flushed update but got %v", got)
+		}
+
+		// The window ends with nothing left to send
+		time.Sleep(100 * time.Millisecond)
+		if got := sentBodies(); len(got) != 0 {
+			t.Errorf("Expected no more requests but got %v", got)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		if err := coalescer.Update(context.Background(), "SKU3", []byte(`{"saldo":5}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := coalescer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := sentBodies(); len(got) != 1 || got[0] != `{"saldo":5}` {
+			t.Errorf("Expected the update flushed on close but got %v", got)
+		}
+
+		if err := coalescer.Update(context.Background(), "SKU3", []byte(`{"saldo":6}`)); !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed but got %v", err)
+		}
+	})
+}
+
+func TestCoalescerFlushWhileSending(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusOK, `{}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The windows end while Flush is waiting, starting new sends
+	coalescer := client.NewCoalescer(RequestMethod{HTTPMethod: POST, Method: "estoques.atualiza"}, time.Millisecond, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if err := coalescer.Update(context.Background(), string(rune('A'+i%5)), []byte(`{"saldo":1}`)); err != nil {
+				t.Error(err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		if err := coalescer.Flush(); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Wait()
+
+	if err := coalescer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if pending := coalescer.Pending(); pending != 0 || *hits == 0 {
+		t.Errorf("Expected every update sent but got %d pending after %d requests", pending, *hits)
+	}
+}
@@ -0,0 +1,178 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// CompanyParam is the default parameter identifying the company (filial)
+const CompanyParam = "cod_filial"
+
+// ErrCompanyMismatch is returned when a scoped request targets another company
+var ErrCompanyMismatch = errors.New("request targets a different company")
+
+// CompanyClient is a view of the client scoped to a single company (filial).
+// The company parameter is added to every request, and requests that target
+// another company, by parameter or by body, are rejected.
+type CompanyClient struct {
+	// Param is the parameter receiving the company code
+	Param string
+
+	m    *Millennium
+	code string
+}
+
+// WithCompany returns a view of the client scoped to the company code.
+// The view shares the client connections, session and settings.
+func (m *Millennium) WithCompany(code string) *CompanyClient {
+	return &CompanyClient{Param: CompanyParam, m: m, code: code}
+}
+
+// Code returns the company code of the scope
+func (c *CompanyClient) Code() string {
+	return c.code
+}
+
+// scope adds the company to the request, ensuring it does not target another one
+func (c *CompanyClient) scope(r RequestMethod) (RequestMethod, error) {
+	r = r.Clone()
+
+	if r.Params == nil {
+		r.Params = url.Values{}
+	}
+
+	for _, value := range r.Params[c.Param] {
+		if value != c.code {
+			return r, fmt.Errorf("%w: %s=%s", ErrCompanyMismatch, c.Param, value)
+		}
+	}
+	r.Params.Set(c.Param, c.code)
+
+	if len(r.Body) > 0 {
+		if err := c.checkBody(r.Body); err != nil {
+			return r, err
+		}
+	}
+
+	return r, nil
+}
+
+// checkBody ensures the records of a body, a single object or an array of
+// them, don't target another company. Bodies that can't be checked are
+// rejected.
+func (c *CompanyClient) checkBody(body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("unable to check the company of the body: %w", err)
+	}
+
+	records, ok := v.([]interface{})
+	if !ok {
+		records = []interface{}{v}
+	}
+
+	for _, record := range records {
+		object, ok := record.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		value, ok := object[c.Param]
+		if !ok {
+			continue
+		}
+
+		code := fmt.Sprint(value)
+		if s, ok := value.(string); ok {
+			code = s
+		}
+
+		if code != c.code {
+			return fmt.Errorf("%w: body %s=%v", ErrCompanyMismatch, c.Param, value)
+		}
+	}
+
+	return nil
+}
+
+// Request a method from Millennium scoped to the company
+func (c *CompanyClient) Request(r RequestMethod) error {
+	r, err := c.scope(r)
+	if err != nil {
+		return err
+	}
+
+	return c.m.Request(r)
+}
+
+// Get requests a method using GET http method scoped to the company
+func (c *CompanyClient) Get(method string, params url.Values, response interface{}) (int, error) {
+	r, err := c.scope(RequestMethod{Method: method, Params: params})
+	if err != nil {
+		return 0, err
+	}
+
+	return c.m.Get(method, r.Params, response)
+}
+
+// Post requests a method using POST http method scoped to the company
+func (c *CompanyClient) Post(method string, body []byte, response interface{}) error {
+	return c.Request(RequestMethod{
+		HTTPMethod: POST,
+		Method:     method,
+		Body:       body,
+		Response:   &response,
+	})
+}
+
+// Put requests a method using PUT http method scoped to the company
+func (c *CompanyClient) Put(method string, body []byte, response interface{}) error {
+	return c.Request(RequestMethod{
+		HTTPMethod: PUT,
+		Method:     method,
+		Body:       body,
+		Response:   &response,
+	})
+}
+
+// Patch requests a method using PATCH http method scoped to the company
+func (c *CompanyClient) Patch(method string, params url.Values, body []byte, response interface{}) error {
+	return c.Request(RequestMethod{
+		HTTPMethod: PATCH,
+		Method:     method,
+		Params:     params,
+		Body:       body,
+		Response:   &response,
+	})
+}
+
+// Delete requests a method using DELETE http method scoped to the company
+func (c *CompanyClient) Delete(method string, params url.Values) error {
+	return c.Request(RequestMethod{
+		HTTPMethod: DELETE,
+		Method:     method,
+		Params:     params,
+	})
+}
+
+// Stream requests a method using GET http method scoped to the company,
+// sending each record to the returned channel
+func (c *CompanyClient) Stream(ctx context.Context, method string, params url.Values) (<-chan Record, <-chan error) {
+	r, err := c.scope(RequestMethod{Method: method, Params: params})
+	if err != nil {
+		records, errs := make(chan Record), make(chan error, 1)
+		errs <- err
+		close(records)
+		close(errs)
+		return records, errs
+	}
+
+	return c.m.Stream(ctx, method, r.Params)
+}
@@ -0,0 +1,75 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestWithCompany(t *testing.T) {
+	company := NewTestClient(t).WithCompany("001")
+
+	var params []map[string]string
+	if _, err := company.Get("test.params", url.Values{"produto": []string{"10"}}, &params); err != nil {
+		t.Fatal(err)
+	}
+
+	if params[0][CompanyParam] != "001" || params[0]["produto"] != "10" {
+		t.Errorf("Unexpected params %v", params[0])
+	}
+
+	records, errs := company.Stream(context.Background(), "test.params", nil)
+	for record := range records {
+		if string(record[CompanyParam]) != `"001"` {
+			t.Errorf("Unexpected stream record %s", record[CompanyParam])
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+
+	var r interface{}
+	if err := company.Post("test.success.POST", []byte(`{"cod_filial":"001","produto":10}`), &r); err != nil {
+		t.Error(err)
+	}
+
+	// Numeric codes are compared as written
+	numeric := NewTestClient(t).WithCompany("12345678")
+	if err := numeric.Post("test.success.POST", []byte(`[{"cod_filial":12345678},{"produto":10}]`), &r); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithCompanyMismatch(t *testing.T) {
+	company := NewTestClient(t).WithCompany("001")
+
+	var r interface{}
+	if _, err := company.Get("test.params", url.Values{CompanyParam: []string{"002"}}, &r); !errors.Is(err, ErrCompanyMismatch) {
+		t.Errorf("Expected ErrCompanyMismatch but got %v", err)
+	}
+
+	if err := company.Post("test.success.POST", []byte(`{"cod_filial":"002"}`), &r); !errors.Is(err, ErrCompanyMismatch) {
+		t.Errorf("Expected ErrCompanyMismatch but got %v", err)
+	}
+
+	if err := company.Post("test.success.POST", []byte(`[{"cod_filial":"001"},{"cod_filial":"002"}]`), &r); !errors.Is(err, ErrCompanyMismatch) {
+		t.Errorf("Expected ErrCompanyMismatch for a batch but got %v", err)
+	}
+
+	if err := company.Post("test.success.POST", []byte(`{"cod_filial":"001"`), &r); err == nil {
+		t.Error("Expected error for a body that can't be checked")
+	}
+
+	if err := company.Delete("test.success.DELETE", url.Values{CompanyParam: []string{"002"}}); !errors.Is(err, ErrCompanyMismatch) {
+		t.Errorf("Expected ErrCompanyMismatch but got %v", err)
+	}
+
+	records, errs := company.Stream(context.Background(), "test.params", url.Values{CompanyParam: []string{"002"}})
+	for range records {
+		t.Error("Expected no records")
+	}
+	if err := <-errs; !errors.Is(err, ErrCompanyMismatch) {
+		t.Errorf("Expected ErrCompanyMismatch but got %v", err)
+	}
+}
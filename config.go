@@ -0,0 +1,183 @@
+package millennium
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Azure/go-ntlmssp"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// MethodConfig overrides the client settings for a single Millennium method.
+// Zero values keep the client settings.
+type MethodConfig struct {
+	// Timeout replaces the client timeout
+	Timeout time.Duration
+
+	// HeaderTimeout replaces the timeout set by WithHeaderTimeout
+	HeaderTimeout time.Duration
+
+	// IdleTimeout replaces the timeout set by WithIdleTimeout
+	IdleTimeout time.Duration
+
+	// Schema validates the POST, PUT and PATCH bodies sent to the method
+	Schema *Schema
+
+	// PageSize replaces the page size used by ListAll
+	PageSize int
+
+	// PageKey is the field identifying the records, used to check the
+	// paging set by WithPagingCheck. Whole records are compared when empty.
+	PageKey string
+
+	// MaxRecords replaces the limit set by WithMaxRecords, use a negative
+	// value to allow any number of records
+	MaxRecords int
+
+	// RetryMax replaces the maximum number of retries, use a negative
+	// value to disable retries for the method
+	RetryMax int
+
+	// NonIdempotent marks methods whose writes, like stock reversals, should
+	// not be applied twice. Requests other than GET are then never retried.
+	NonIdempotent bool
+
+	// VerifyDelete is called when a DELETE to the method fails in a way
+	// that does not tell whether it was applied, like a timeout or a dropped
+	// connection. It should read the record back and report whether it is
+	// gone, in which case the DELETE succeeds.
+	VerifyDelete func(r RequestMethod) (deleted bool, err error)
+}
+
+// retryable reports if a request to the method can be sent again
+func (c MethodConfig) retryable(httpMethod string) bool {
+	return !c.NonIdempotent || httpMethod == http.MethodGet
+}
+
+// Configure sets the configuration used by every request to method,
+// replacing any configuration previously set for it
+func (m *Millennium) Configure(method string, config MethodConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.methods == nil {
+		m.methods = map[string]MethodConfig{}
+	}
+
+	m.methods[method] = config
+}
+
+// Config holds the client settings that can be changed while the client
+// runs, see Reconfigure
+type Config struct {
+	// Username and Password replace the stored credentials when not empty.
+	// With Session auth the current session is kept and the new credentials
+	// are used by the next login; NTLM and Basic auth use them right away.
+	Username string
+	Password string
+
+	// Methods replaces the configuration of every method when not nil
+	Methods map[string]MethodConfig
+
+	// Quotas replaces the quota of each caller listed, resetting its usage
+	Quotas map[string]Quota
+
+	// RateLimit replaces the rate limit set by WithRateLimit when not nil.
+	// Requests already waiting for the previous limit keep waiting for it.
+	RateLimit *RateLimit
+}
+
+// Reconfigure applies cfg to the client, so long-running services can
+// rotate passwords or tune methods without being restarted.
+// It is safe to call while requests are running; requests already sent
+// keep the settings they started with.
+func (m *Millennium) Reconfigure(cfg Config) {
+	if cfg.Username != "" || cfg.Password != "" {
+		m.updateCredentials(func(c *credentials) {
+			if cfg.Username != "" {
+				c.Username = cfg.Username
+			}
+
+			if cfg.Password != "" {
+				c.Password = cfg.Password
+			}
+		})
+	}
+
+	if cfg.Methods != nil {
+		methods := make(map[string]MethodConfig, len(cfg.Methods))
+		for method, config := range cfg.Methods {
+			methods[method] = config
+		}
+
+		m.mu.Lock()
+		m.methods = methods
+		m.mu.Unlock()
+	}
+
+	for caller, quota := range cfg.Quotas {
+		m.SetQuota(caller, quota)
+	}
+
+	if cfg.RateLimit != nil {
+		limiter := newRateLimiter(cfg.RateLimit.RPS, cfg.RateLimit.Burst)
+
+		m.mu.Lock()
+		m.rateLimit = limiter
+		m.mu.Unlock()
+	}
+}
+
+// methodConfig returns the configuration of method
+func (m *Millennium) methodConfig(method string) MethodConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.methods[method]
+}
+
+// clientFor returns the retryable client honoring the method configuration
+// and the authentication type, logging through the redaction policy
+func (m *Millennium) clientFor(config MethodConfig) *retryablehttp.Client {
+	ntlm := m.getCredentials().AuthType == NTLM
+	if config.RetryMax == 0 && !ntlm && m.Client.Logger == nil {
+		return m.Client
+	}
+
+	client := &retryablehttp.Client{
+		HTTPClient:      m.Client.HTTPClient,
+		Logger:          m.redaction.redactLogger(m.Client.Logger),
+		RetryWaitMin:    m.Client.RetryWaitMin,
+		RetryWaitMax:    m.Client.RetryWaitMax,
+		RetryMax:        m.Client.RetryMax,
+		RequestLogHook:  m.Client.RequestLogHook,
+		ResponseLogHook: m.Client.ResponseLogHook,
+		CheckRetry:      m.Client.CheckRetry,
+		Backoff:         m.Client.Backoff,
+		ErrorHandler:    m.Client.ErrorHandler,
+		PrepareRetry:    m.Client.PrepareRetry,
+	}
+
+	if config.RetryMax != 0 {
+		client.RetryMax = max(config.RetryMax, 0)
+	}
+
+	// NTLM wraps the configured transport for the request only, so the
+	// shared client is never changed while requests are in flight
+	if ntlm {
+		httpClient := *m.Client.HTTPClient
+		httpClient.Transport = ntlmssp.Negotiator{RoundTripper: transportOrDefault(httpClient.Transport)}
+		client.HTTPClient = &httpClient
+	}
+
+	return client
+}
+
+// transportOrDefault returns transport, http.DefaultTransport if nil
+func transportOrDefault(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		return http.DefaultTransport
+	}
+
+	return transport
+}
@@ -0,0 +1,153 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfigureRetryMax(t *testing.T) {
+	cases := []struct {
+		RetryMax int
+		Hits     int32
+	}{
+		{RetryMax: 0, Hits: RetryMax + 1},
+		{RetryMax: -1, Hits: 1},
+		{RetryMax: 1, Hits: 2},
+		{RetryMax: 5, Hits: 6},
+	}
+
+	for _, c := range cases {
+		server, hits := newCountingServer(t, http.StatusInternalServerError, `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Query error"}}}`)
+
+		client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Client.RetryWaitMin = time.Millisecond
+		client.Client.RetryWaitMax = time.Millisecond
+
+		client.Configure("test", MethodConfig{RetryMax: c.RetryMax})
+
+		var r interface{}
+		if _, err := client.Get("test", url.Values{}, &r); err == nil {
+			t.Error("Expected error")
+		}
+
+		if atomic.LoadInt32(hits) != c.Hits {
+			t.Errorf("RetryMax %d: expected %d attempts but got %d", c.RetryMax, c.Hits, *hits)
+		}
+	}
+}
+
+func TestConfigureTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("slow", url.Values{}, &r); err != nil {
+		t.Fatalf("Expected no error with client timeout but got %v", err)
+	}
+
+	client.Configure("slow", MethodConfig{Timeout: 50 * time.Millisecond})
+	if _, err := client.Get("slow", url.Values{}, &r); err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	client := NewTestClient(t)
+	if err := client.Login("correct_user", "old_password", Basic); err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.basicauth", url.Values{}, &r); err == nil {
+		t.Error("Expected error with the old password")
+	}
+
+	client.Configure("test.success.GET", MethodConfig{Timeout: time.Second})
+
+	client.Reconfigure(Config{
+		Password: "correct_password",
+		Methods: map[string]MethodConfig{
+			"test.basicauth": {Timeout: time.Minute},
+		},
+		Quotas: map[string]Quota{
+			"sync": {Requests: 1},
+		},
+	})
+
+	if _, err := client.Get("test.basicauth", url.Values{}, &r); err != nil {
+		t.Errorf("Expected success with the new password but got %v", err)
+	}
+
+	if creds := client.getCredentials(); creds.Username != "correct_user" {
+		t.Errorf("Expected username to be kept but got %s", creds.Username)
+	}
+
+	if config := client.methodConfig("test.basicauth"); config.Timeout != time.Minute {
+		t.Errorf("Expected new method config but got %+v", config)
+	}
+
+	if config := client.methodConfig("test.success.GET"); config.Timeout != 0 {
+		t.Errorf("Expected method config to be replaced but got %+v", config)
+	}
+
+	ctx := WithCaller(context.Background(), "sync")
+	if _, err := client.GetCtx(ctx, "test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetCtx(ctx, "test.success.GET", url.Values{}, &r); err == nil {
+		t.Error("Expected quota error")
+	}
+}
+
+func TestReconfigureRateLimit(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusOK, `{"odata.count":0,"value":[]}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRateLimit(0.1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the new limit the next request would wait 10 seconds
+	client.Reconfigure(Config{RateLimit: &RateLimit{RPS: 1000, Burst: 5}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetCtx(ctx, "test", url.Values{}, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *hits != 6 {
+		t.Errorf("Expected 6 requests sent but got %d", *hits)
+	}
+
+	client.Reconfigure(Config{RateLimit: &RateLimit{}})
+	if client.limiter() != nil {
+		t.Error("Expected the rate limit to be removed")
+	}
+}
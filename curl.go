@@ -0,0 +1,77 @@
+package millennium
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Clone returns a copy of the request that can be modified without
+// changing the original one. Params and Body are copied, while Response
+// still points to the same value.
+func (r RequestMethod) Clone() RequestMethod {
+	clone := r
+
+	if r.Params != nil {
+		clone.Params = cloneParams(r.Params)
+	}
+
+	if r.Body != nil {
+		clone.Body = append([]byte(nil), r.Body...)
+	}
+
+	return clone
+}
+
+// cloneParams returns a copy of params that can be changed freely
+func cloneParams(params url.Values) url.Values {
+	clone := url.Values{}
+	for key, values := range params {
+		clone[key] = append([]string(nil), values...)
+	}
+
+	return clone
+}
+
+// AsCurl returns a cURL command reproducing the request as the client would
+// send it, redacted by the client RedactionPolicy, to be shared in support tickets
+func (m *Millennium) AsCurl(r RequestMethod) (string, error) {
+	req, err := m.newRequest(m.requestContext(r), r.Clone())
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "curl -X %s %s", req.Method, shellQuote(m.redaction.redactURL(req.URL).String()))
+
+	header := m.redaction.redactHeader(req.Header)
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(&b, " -H %s", shellQuote(key+": "+value))
+		}
+	}
+
+	body, err := req.BodyBytes()
+	if err != nil {
+		return "", fmt.Errorf("unable to read request body: %w", err)
+	}
+
+	if len(body) > 0 {
+		fmt.Fprintf(&b, " --data-raw %s", shellQuote(string(bytes.TrimSpace(m.redaction.Redact(body)))))
+	}
+
+	return b.String(), nil
+}
+
+// shellQuote quotes s to be used as a single shell argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,68 @@
+package millennium
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRequestMethodClone(t *testing.T) {
+	original := RequestMethod{
+		HTTPMethod: POST,
+		Method:     "test.success.POST",
+		Params:     url.Values{"filial": []string{"1"}},
+		Body:       []byte(`{"test":"test"}`),
+	}
+
+	clone := original.Clone()
+	clone.Params.Set("filial", "2")
+	clone.Body[0] = '['
+
+	if original.Params.Get("filial") != "1" || string(original.Body) != `{"test":"test"}` {
+		t.Errorf("Original request changed: %+v", original)
+	}
+}
+
+func TestAsCurl(t *testing.T) {
+	client := NewTestClient(t)
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	r := RequestMethod{
+		HTTPMethod: POST,
+		Method:     "test.success.POST",
+		Params:     url.Values{"nome": []string{"d'água"}},
+		Body:       []byte(`{"test":"test"}`),
+	}
+
+	curl, err := client.AsCurl(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"curl -X POST '" + serverAddr + "/api/test.success.POST?",
+		"-H 'Wts-Session: REDACTED'",
+		`--data-raw '{"test":"test"}'`,
+		`nome=d%27%C3%A1gua`,
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(curl, e) {
+			t.Errorf("Expected %q in %s", e, curl)
+		}
+	}
+
+	if strings.Contains(curl, "00000000-0000-0000-0000-000000000000") {
+		t.Errorf("Session not redacted: %s", curl)
+	}
+
+	if r.Params.Has("$format") {
+		t.Error("AsCurl should not change the request params")
+	}
+
+	if _, err := client.AsCurl(RequestMethod{HTTPMethod: GET}); err == nil {
+		t.Error("Expected error for empty method")
+	}
+}
@@ -0,0 +1,206 @@
+package millennium
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateRequest is returned when a POST identical to a recent one is
+// made within the window set by WithDuplicateGuard
+var ErrDuplicateRequest = errors.New("duplicate request")
+
+// IdempotencyStore records the keys of the POST requests recently made, see
+// WithIdempotencyStore. Stores shared by many processes, or kept on disk,
+// protect against workers replaying their input after a crash.
+type IdempotencyStore interface {
+	// Claim records key, reporting false if it was already recorded less
+	// than ttl ago
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Release forgets key, so the request can be made again
+	Release(ctx context.Context, key string) error
+}
+
+// WithDuplicateGuard rejects with ErrDuplicateRequest the POST requests
+// with the same method, parameters and body of another one made less than
+// ttl ago, protecting against bugs that submit the same order twice.
+// Requests rejected by Millennium, or never sent, can be made again right
+// away, while the ones that may have been applied are kept for ttl.
+// Requests with a BodyFunc are not checked unless they have an
+// IdempotencyKey.
+func WithDuplicateGuard(ttl time.Duration) Option {
+	return WithIdempotencyStore(NewMemoryIdempotencyStore(), ttl)
+}
+
+// WithIdempotencyStore works as WithDuplicateGuard, keeping the requests
+// made in store, so they are still known after the process restarts
+func WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) Option {
+	return func(m *Millennium) {
+		m.dedup = &duplicateGuard{ttl: ttl, store: store}
+	}
+}
+
+// duplicateGuard rejects the POST requests already claimed in its store
+type duplicateGuard struct {
+	ttl   time.Duration
+	store IdempotencyStore
+}
+
+// checks reports if the request is checked by the guard
+func (g *duplicateGuard) checks(r RequestMethod) bool {
+	return r.HTTPMethod == POST && (r.BodyFunc == nil || r.IdempotencyKey != "")
+}
+
+// claim registers the request, returning a function to be called with its
+// outcome, or ErrDuplicateRequest if it was made recently
+func (g *duplicateGuard) claim(ctx context.Context, r RequestMethod) (func(err error), error) {
+	key := r.IdempotencyKey
+	if key == "" {
+		key = requestHash(r)
+	}
+
+	claimed, err := g.store.Claim(ctx, key, g.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency store: %w", err)
+	}
+
+	if !claimed {
+		return nil, ErrDuplicateRequest
+	}
+
+	return func(err error) {
+		if err == nil || ambiguous(err) || errors.Is(err, ErrDuplicateRequest) {
+			return
+		}
+
+		// The key is kept when it can not be released, rejecting the
+		// retries until it expires is safer than creating duplicates
+		_ = g.store.Release(context.WithoutCancel(ctx), key)
+	}, nil
+}
+
+// requestHash identifies a request by its method, parameters and body, with
+// the encrypted fields already decrypted
+func requestHash(r RequestMethod) string {
+	h := sha256.New()
+	h.Write([]byte(r.HTTPMethod))
+	h.Write([]byte{0})
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.Params.Encode()))
+	h.Write([]byte{0})
+	h.Write(r.Body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewMemoryIdempotencyStore returns an IdempotencyStore kept in memory,
+// which can be shared by the clients of a process
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{seen: map[string]time.Time{}}
+}
+
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// Claim implements IdempotencyStore
+func (s *memoryIdempotencyStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, at := range s.seen {
+		if now.Sub(at) >= ttl {
+			delete(s.seen, k)
+		}
+	}
+
+	if _, ok := s.seen[key]; ok {
+		return false, nil
+	}
+	s.seen[key] = now
+
+	return true, nil
+}
+
+// Release implements IdempotencyStore
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.seen, key)
+	return nil
+}
+
+// NewDirIdempotencyStore returns an IdempotencyStore keeping a file for
+// each key in dir, which is created if needed. Processes on the same host
+// may share the directory.
+func NewDirIdempotencyStore(dir string) (IdempotencyStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &dirIdempotencyStore{dir: dir}, nil
+}
+
+type dirIdempotencyStore struct {
+	dir string
+}
+
+// path returns the file of key, hashed to be a valid file name
+func (s *dirIdempotencyStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+// Claim implements IdempotencyStore, the file modification time telling
+// when the key was claimed
+func (s *dirIdempotencyStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	path := s.path(key)
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			return true, f.Close()
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return false, err
+		}
+
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if time.Since(info.ModTime()) < ttl {
+			return false, nil
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+}
+
+// Release implements IdempotencyStore
+func (s *dirIdempotencyStore) Release(_ context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDuplicateGuard(t *testing.T) {
+	var posts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/test.rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":{"lang":"pt-BR","value":"Pedido inválido"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"pedido":1}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		Name   string
+		Method string
+		First  []byte
+		Second []byte
+		Wait   time.Duration
+		Shed   bool
+		Err    error
+		Posts  int32
+	}{
+		{Name: "same payload", Method: "test.orders", First: []byte(`{"pedido":1}`), Second: []byte(`{"pedido":1}`), Err: ErrDuplicateRequest, Posts: 1},
+		{Name: "different payload", Method: "test.orders", First: []byte(`{"pedido":1}`), Second: []byte(`{"pedido":2}`), Posts: 2},
+		{Name: "after ttl", Method: "test.orders", First: []byte(`{"pedido":1}`), Second: []byte(`{"pedido":1}`), Wait: 60 * time.Millisecond, Posts: 2},
+		{Name: "rejected first", Method: "test.rejected", First: []byte(`{"pedido":1}`), Second: []byte(`{"pedido":1}`), Posts: 2},
+		{Name: "shed first", Method: "test.orders", First: []byte(`{"pedido":1}`), Second: []byte(`{"pedido":1}`), Wait: 20 * time.Millisecond, Shed: true, Posts: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			atomic.StoreInt32(&posts, 0)
+
+			opts := []Option{WithDuplicateGuard(50 * time.Millisecond)}
+			if c.Shed {
+				opts = append(opts, WithRateLimit(100, 1), WithLoadShedding(PriorityNormal))
+			}
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			if c.Shed {
+				// The only token is taken, so the first request is shed
+				_ = client.Post(c.Method, []byte(`{"pedido":0}`), &r)
+				if err := client.Post(c.Method, c.First, &r); !errors.Is(err, ErrLoadShed) {
+					t.Fatalf("Expected the first request to be shed but got %v", err)
+				}
+			} else {
+				_ = client.Post(c.Method, c.First, &r)
+			}
+
+			time.Sleep(c.Wait)
+
+			err = client.Post(c.Method, c.Second, &r)
+			if c.Err != nil && !errors.Is(err, c.Err) {
+				t.Errorf("Expected %v but got %v", c.Err, err)
+			}
+
+			if errors.Is(err, ErrDuplicateRequest) && c.Err == nil {
+				t.Errorf("Expected no duplicate but got %v", err)
+			}
+
+			if atomic.LoadInt32(&posts) != c.Posts {
+				t.Errorf("Expected %d posts but got %d", c.Posts, posts)
+			}
+		})
+	}
+}
+
+func TestDuplicateGuardFieldEncryption(t *testing.T) {
+	server, posts := newCountingServer(t, http.StatusOK, `{"ok":true}`)
+
+	c, _ := NewAESFieldCipher([]byte("0123456789abcdef"))
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithFieldEncryption(c, "cpf"), WithDuplicateGuard(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same record encrypted twice has different ciphertexts
+	var bodies [][]byte
+	for i := 0; i < 2; i++ {
+		body, err := client.encryptFields([]byte(`{"cpf":"12345678900"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, body)
+	}
+
+	var r interface{}
+	if err := client.Post("clientes", bodies[0], &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Post("clientes", bodies[1], &r); !errors.Is(err, ErrDuplicateRequest) {
+		t.Errorf("Expected ErrDuplicateRequest but got %v", err)
+	}
+
+	if *posts != 1 {
+		t.Errorf("Expected 1 post but got %d", *posts)
+	}
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	var posts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/test.rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":{"lang":"pt-BR","value":"Pedido inválido"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"pedido":1}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		Name   string
+		Method string
+		Keys   [2]string
+		Bodies [2]string
+		Err    error
+		Posts  int32
+	}{
+		{Name: "replayed after restart", Method: "test.orders", Bodies: [2]string{`{"pedido":1}`, `{"pedido":1}`}, Err: ErrDuplicateRequest, Posts: 1},
+		{Name: "same key", Method: "test.orders", Keys: [2]string{"pedido-1", "pedido-1"}, Bodies: [2]string{`{"pedido":1}`, `{"pedido":1,"obs":"x"}`}, Err: ErrDuplicateRequest, Posts: 1},
+		{Name: "different keys", Method: "test.orders", Keys: [2]string{"pedido-1", "pedido-2"}, Bodies: [2]string{`{"pedido":1}`, `{"pedido":1}`}, Posts: 2},
+		{Name: "rejected first", Method: "test.rejected", Bodies: [2]string{`{"pedido":1}`, `{"pedido":1}`}, Posts: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			atomic.StoreInt32(&posts, 0)
+			dir := t.TempDir()
+
+			for i := range c.Bodies {
+				// Each request is made by a new client, as after a restart
+				store, err := NewDirIdempotencyStore(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithIdempotencyStore(store, time.Hour))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var r interface{}
+				err = client.Request(RequestMethod{HTTPMethod: POST, Method: c.Method, Body: []byte(c.Bodies[i]), IdempotencyKey: c.Keys[i], Response: &r})
+
+				if i == 1 && c.Err != nil && !errors.Is(err, c.Err) {
+					t.Errorf("Expected %v but got %v", c.Err, err)
+				}
+
+				if errors.Is(err, ErrDuplicateRequest) && c.Err == nil {
+					t.Errorf("Expected no duplicate but got %v", err)
+				}
+			}
+
+			if atomic.LoadInt32(&posts) != c.Posts {
+				t.Errorf("Expected %d posts but got %d", c.Posts, posts)
+			}
+		})
+	}
+}
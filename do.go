@@ -0,0 +1,44 @@
+package millennium
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Do sends r with the authentication, headers and retries of the client,
+// bound to ctx, and returns the raw response, for payloads that are not
+// JSON or bodies streamed by the caller, who must close the response body.
+// Responses with error status are returned as they are, without an error.
+// r.Response, r.Meta and r.Context are ignored.
+func (m *Millennium) Do(ctx context.Context, r RequestMethod) (*http.Response, error) {
+	if err := m.checkMethod(r.Method); err != nil {
+		return nil, err
+	}
+
+	leave, err := m.enterServer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureSession(); err != nil {
+		leave()
+		return nil, err
+	}
+
+	req, err := m.newRequest(ctx, r)
+	if err != nil {
+		leave()
+		return nil, err
+	}
+
+	res, err := m.do(r.Method, req)
+	if err != nil {
+		leave()
+		return nil, fmt.Errorf("unable to make the request to Millennium: %w", err)
+	}
+
+	// The server can't be switched until the body is closed
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: leave}
+	return res, nil
+}
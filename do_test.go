@@ -0,0 +1,53 @@
+package millennium
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	client := NewTestClient(t)
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name       string
+		Method     string
+		StatusCode int
+	}{
+		{Name: "success", Method: "test.success.GET", StatusCode: http.StatusOK},
+		{Name: "error status", Method: "test.error400.GET", StatusCode: http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			res, err := client.Do(context.Background(), RequestMethod{
+				HTTPMethod: GET,
+				Method:     c.Method,
+				Params:     url.Values{},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != c.StatusCode {
+				t.Errorf("Expected status %d but got %d", c.StatusCode, res.StatusCode)
+			}
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(body) == 0 {
+				t.Error("Expected the raw body")
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package millennium
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched by ResponseError with errors.Is, so callers can
+// branch on the kind of failure instead of on the Portuguese messages
+var (
+	// ErrUnauthorized matches responses with 401 Unauthorized
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrSessionExpired matches 401 responses to requests sent with a WTS
+	// session, which is no longer accepted
+	ErrSessionExpired = errors.New("session expired")
+
+	// ErrNotFound matches responses with 404 Not Found
+	ErrNotFound = errors.New("not found")
+
+	// ErrServerError matches responses with 5xx status
+	ErrServerError = errors.New("server error")
+
+	// ErrThrottled matches responses with 429 Too Many Requests, or 503
+	// with Retry-After, telling the client to slow down. The delay asked is
+	// in the RetryAfter of the ResponseError, or of the last Attempt.
+	ErrThrottled = errors.New("throttled")
+
+	// ErrPermissionDenied matches responses in the CategoryPermission
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrValidation matches responses in the CategoryValidation
+	ErrValidation = errors.New("validation failed")
+
+	// ErrDuplicate matches responses in the CategoryDuplicate
+	ErrDuplicate = errors.New("duplicate record")
+
+	// ErrLockTimeout matches responses in the CategoryLockTimeout
+	ErrLockTimeout = errors.New("lock timeout")
+)
+
+// ErrorCategory is the kind of failure told by a Millennium error message
+type ErrorCategory int
+
+const (
+	// CategoryUnknown is used for messages not matching any pattern
+	CategoryUnknown ErrorCategory = iota
+
+	// CategoryPermission is used when the user is not allowed to run the
+	// method, like "PERMISSÃO NEGADA"
+	CategoryPermission
+
+	// CategoryValidation is used when the data sent was rejected
+	CategoryValidation
+
+	// CategoryDuplicate is used when the record already exists
+	CategoryDuplicate
+
+	// CategoryLockTimeout is used when the record was locked by another
+	// transaction, usually worth trying again later
+	CategoryLockTimeout
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryPermission:
+		return "permission"
+	case CategoryValidation:
+		return "validation"
+	case CategoryDuplicate:
+		return "duplicate"
+	case CategoryLockTimeout:
+		return "lock timeout"
+	}
+
+	return "unknown"
+}
+
+// ErrorPatterns maps the known Millennium messages to their category.
+// Patterns are matched in order against the message in lower case without
+// accents, so more specific patterns should come first. Integrations may
+// append the messages of their own customizations at startup.
+var ErrorPatterns = []ErrorPattern{
+	{Contains: "permissao negada", Category: CategoryPermission},
+	{Contains: "acesso negado", Category: CategoryPermission},
+	{Contains: "sem permissao", Category: CategoryPermission},
+	{Contains: "nao tem permissao", Category: CategoryPermission},
+	{Contains: "deadlock", Category: CategoryLockTimeout},
+	{Contains: "lock request time out", Category: CategoryLockTimeout},
+	{Contains: "lock timeout", Category: CategoryLockTimeout},
+	{Contains: "registro bloqueado", Category: CategoryLockTimeout},
+	{Contains: "registro em uso", Category: CategoryLockTimeout},
+	{Contains: "ja existe", Category: CategoryDuplicate},
+	{Contains: "ja cadastrad", Category: CategoryDuplicate},
+	{Contains: "duplicad", Category: CategoryDuplicate},
+	{Contains: "duplicate key", Category: CategoryDuplicate},
+	{Contains: "violation of primary key", Category: CategoryDuplicate},
+	{Contains: "violation of unique key", Category: CategoryDuplicate},
+	{Contains: "obrigatori", Category: CategoryValidation},
+	{Contains: "invalid", Category: CategoryValidation},
+	{Contains: "nao informad", Category: CategoryValidation},
+	{Contains: "deve ser informad", Category: CategoryValidation},
+	{Contains: "formato incorreto", Category: CategoryValidation},
+}
+
+// ErrorPattern is a Millennium message pattern, see ErrorPatterns
+type ErrorPattern struct {
+	// Contains is matched against the message in lower case without accents
+	Contains string
+
+	// Code, when not zero, must also match the Millennium error code
+	Code int
+
+	Category ErrorCategory
+}
+
+// accents folds the accented letters used in Portuguese messages
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+// Category classifies the error by its message and code, see ErrorPatterns.
+// Responses with 403 Forbidden are always in the CategoryPermission.
+func (r *ResponseError) Category() ErrorCategory {
+	message := accents.Replace(strings.ToLower(r.Err.Message.Value))
+
+	for _, pattern := range ErrorPatterns {
+		if pattern.Code != 0 && pattern.Code != r.Err.Code {
+			continue
+		}
+
+		if strings.Contains(message, pattern.Contains) {
+			return pattern.Category
+		}
+	}
+
+	if r.StatusCode == http.StatusForbidden || r.Err.Code == http.StatusForbidden {
+		return CategoryPermission
+	}
+
+	return CategoryUnknown
+}
+
+// Is reports if the response matches one of the sentinel errors
+func (r *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrSessionExpired:
+		return r.StatusCode == http.StatusUnauthorized && r.session
+	case ErrThrottled:
+		return throttled(r.StatusCode, r.RetryAfter)
+	case ErrPermissionDenied:
+		return r.Category() == CategoryPermission
+	case ErrValidation:
+		return r.Category() == CategoryValidation
+	case ErrDuplicate:
+		return r.Category() == CategoryDuplicate
+	case ErrLockTimeout:
+		return r.Category() == CategoryLockTimeout
+	}
+
+	return statusIs(r.StatusCode, target)
+}
+
+// Is reports if the last attempt matches one of the sentinel errors, as
+// server errors are only returned after the retries
+func (e *RetryError) Is(target error) bool {
+	if len(e.Attempts) == 0 {
+		return false
+	}
+
+	last := e.Attempts[len(e.Attempts)-1]
+	if target == ErrThrottled {
+		return throttled(last.StatusCode, last.RetryAfter)
+	}
+
+	return statusIs(last.StatusCode, target)
+}
+
+// throttled reports if a response asked the client to slow down
+func throttled(status int, retryAfter time.Duration) bool {
+	return status == http.StatusTooManyRequests || (status == http.StatusServiceUnavailable && retryAfter > 0)
+}
+
+// statusIs reports if an http status matches a sentinel error
+func statusIs(status int, target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	case ErrServerError:
+		return status >= 500
+	}
+
+	return false
+}
+
+// Code returns the Millennium error code, which may differ from StatusCode
+func (r *ResponseError) Code() int {
+	return r.Err.Code
+}
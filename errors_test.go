@@ -0,0 +1,143 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestResponseErrorSentinels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+		case "/api/test.unauthorized":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessão inválida"}}}`))
+		case "/api/test.notfound":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":{"lang":"pt-BR","value":"registro não encontrado"}}}`))
+		case "/api/test.gateway":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<html>Bad Request</html>`))
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		Name       string
+		Method     string
+		Session    bool
+		StatusCode int
+		Is         []error
+		IsNot      []error
+	}{
+		{Name: "unauthorized", Method: "test.unauthorized", StatusCode: 401, Is: []error{ErrUnauthorized}, IsNot: []error{ErrSessionExpired, ErrNotFound}},
+		{Name: "session expired", Method: "test.unauthorized", Session: true, StatusCode: 401, Is: []error{ErrUnauthorized, ErrSessionExpired}},
+		{Name: "not found", Method: "test.notfound", StatusCode: 404, Is: []error{ErrNotFound}, IsNot: []error{ErrUnauthorized, ErrServerError}},
+		{Name: "not json", Method: "test.gateway", StatusCode: 400, IsNot: []error{ErrNotFound, ErrServerError}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Session {
+				if err := client.Login("test", "test", Session); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var r interface{}
+			_, err = client.Get(c.Method, url.Values{"produto": {"1"}}, &r)
+
+			var resErr *ResponseError
+			if !errors.As(err, &resErr) {
+				t.Fatalf("Expected a ResponseError but got %v", err)
+			}
+
+			if resErr.StatusCode != c.StatusCode || len(resErr.Body) == 0 {
+				t.Errorf("Expected status %d and the raw body but got %d and %q", c.StatusCode, resErr.StatusCode, resErr.Body)
+			}
+
+			if u, err := url.Parse(resErr.URL); err != nil || u.Path != "/api/"+c.Method || u.Query().Get("produto") != "1" {
+				t.Errorf("Unexpected URL %s", resErr.URL)
+			}
+
+			for _, target := range c.Is {
+				if !errors.Is(err, target) {
+					t.Errorf("Expected error to be %v", target)
+				}
+			}
+
+			for _, target := range c.IsNot {
+				if errors.Is(err, target) {
+					t.Errorf("Expected error not to be %v", target)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryErrorSentinels(t *testing.T) {
+	client := NewTestClient(t)
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	var r interface{}
+	_, err := client.Get("test.error500.GET", url.Values{}, &r)
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("Expected %v but got %v", ErrServerError, err)
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error not to be %v", ErrNotFound)
+	}
+}
+
+func TestResponseErrorCategory(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Message    string
+		Code       int
+		StatusCode int
+		Category   ErrorCategory
+		Is         error
+	}{
+		{Name: "permission", Message: "PERMISSÃO NEGADA", Code: 401, StatusCode: 401, Category: CategoryPermission, Is: ErrPermissionDenied},
+		{Name: "forbidden", Message: "Forbidden", StatusCode: 403, Category: CategoryPermission, Is: ErrPermissionDenied},
+		{Name: "validation", Message: "Campo CODIGO é obrigatório", Code: 400, StatusCode: 400, Category: CategoryValidation, Is: ErrValidation},
+		{Name: "duplicate", Message: "Registro já existe", Code: 400, StatusCode: 400, Category: CategoryDuplicate, Is: ErrDuplicate},
+		{Name: "sql duplicate", Message: "Violation of PRIMARY KEY constraint 'PK_PRODUTOS'", StatusCode: 500, Category: CategoryDuplicate, Is: ErrDuplicate},
+		{Name: "lock timeout", Message: "Lock request time out period exceeded", StatusCode: 500, Category: CategoryLockTimeout, Is: ErrLockTimeout},
+		{Name: "unknown", Message: "registro não encontrado", Code: 404, StatusCode: 404, Category: CategoryUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := &ResponseError{StatusCode: c.StatusCode}
+			r.SetMessage(c.Message)
+			r.SetCode(c.Code)
+
+			if r.Category() != c.Category {
+				t.Errorf("Expected category %s but got %s", c.Category, r.Category())
+			}
+
+			for _, target := range []error{ErrPermissionDenied, ErrValidation, ErrDuplicate, ErrLockTimeout} {
+				if errors.Is(r, target) != (target == c.Is) {
+					t.Errorf("Expected errors.Is(%v) to be %v", target, target == c.Is)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package millennium
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FieldCipher encrypts and decrypts the value of sensitive fields.
+// The plaintext is the raw JSON value of the field, while the ciphertext is
+// stored as a JSON string.
+type FieldCipher interface {
+	Encrypt(field string, plaintext json.RawMessage) (string, error)
+	Decrypt(field string, ciphertext string) (json.RawMessage, error)
+}
+
+// WithFieldEncryption protects the given fields with cipher.
+// The fields are encrypted in the responses before they reach the
+// application, and decrypted in the POST bodies right before they are sent,
+// so the values are kept encrypted by anything storing them in between.
+func WithFieldEncryption(cipher FieldCipher, fields ...string) Option {
+	return func(m *Millennium) {
+		m.fieldCipher = cipher
+		m.encryptedFields = map[string]bool{}
+		for _, field := range fields {
+			m.encryptedFields[field] = true
+		}
+	}
+}
+
+// encryptFields encrypts the sensitive fields of a JSON body
+func (m *Millennium) encryptFields(body []byte) ([]byte, error) {
+	if m.fieldCipher == nil || len(body) == 0 {
+		return body, nil
+	}
+
+	return transformFields(body, m.isEncryptedField, func(field string, value json.RawMessage) (json.RawMessage, error) {
+		ciphertext, err := m.fieldCipher.Encrypt(field, value)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(ciphertext)
+	})
+}
+
+// decryptFields decrypts the sensitive fields of a JSON body
+func (m *Millennium) decryptFields(body []byte) ([]byte, error) {
+	if m.fieldCipher == nil || len(body) == 0 {
+		return body, nil
+	}
+
+	return transformFields(body, m.isEncryptedField, func(field string, value json.RawMessage) (json.RawMessage, error) {
+		var ciphertext string
+		if err := json.Unmarshal(value, &ciphertext); err != nil {
+			return nil, fmt.Errorf("encrypted field should be a string: %w", err)
+		}
+		return m.fieldCipher.Decrypt(field, ciphertext)
+	})
+}
+
+func (m *Millennium) isEncryptedField(field string) bool {
+	return m.encryptedFields[field]
+}
+
+// transformFields applies fn to the values of the given fields, at any depth
+// of a JSON document. Only the transformed values are replaced, so the order
+// of the fields and the bytes of the rest of the document are kept.
+// Bodies that are not JSON are returned untouched.
+func transformFields(body []byte, match func(field string) bool, fn func(field string, value json.RawMessage) (json.RawMessage, error)) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+
+	var out []byte
+	last := 0
+
+	// objects tells, for each open object or array, if it is an object
+	var objects []bool
+	inObject := func() bool { return len(objects) > 0 && objects[len(objects)-1] }
+	expectKey := false
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body, nil
+		}
+
+		switch token {
+		case json.Delim('{'):
+			objects = append(objects, true)
+			expectKey = true
+			continue
+		case json.Delim('['):
+			objects = append(objects, false)
+			expectKey = false
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			objects = objects[:len(objects)-1]
+			expectKey = inObject()
+			continue
+		}
+
+		key, ok := token.(string)
+		if !ok || !expectKey {
+			expectKey = inObject()
+			continue
+		}
+
+		expectKey = false
+		if !match(key) {
+			continue
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return body, nil
+		}
+
+		transformed, err := fn(key, value)
+		if err != nil {
+			return body, fmt.Errorf("unable to transform field %s: %w", key, err)
+		}
+
+		end := int(dec.InputOffset())
+		out = append(out, body[last:end-len(value)]...)
+		out = append(out, transformed...)
+		last = end
+		expectKey = true
+	}
+
+	// Truncated documents end without closing every object or array
+	if out == nil || len(objects) > 0 {
+		return body, nil
+	}
+
+	return append(out, body[last:]...), nil
+}
+
+// aesFieldCipher encrypts fields with AES-GCM
+type aesFieldCipher struct {
+	aead cipher.AEAD
+}
+
+// aesFieldPrefix marks the values encrypted by the AES field cipher
+const aesFieldPrefix = "enc:"
+
+// NewAESFieldCipher returns a FieldCipher using AES-GCM with key, which
+// should have 16, 24 or 32 bytes. The field name is authenticated along with
+// the value, so an encrypted value can't be moved to another field.
+func NewAESFieldCipher(key []byte) (FieldCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cipher: %w", err)
+	}
+
+	return &aesFieldCipher{aead: aead}, nil
+}
+
+func (c *aesFieldCipher) Encrypt(field string, plaintext json.RawMessage) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, []byte(field))
+	return aesFieldPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *aesFieldCipher) Decrypt(field string, ciphertext string) (json.RawMessage, error) {
+	if !strings.HasPrefix(ciphertext, aesFieldPrefix) {
+		return nil, errors.New("value is not encrypted")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, aesFieldPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, sealed, []byte(field))
+}
@@ -0,0 +1,122 @@
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAESFieldCipher(t *testing.T) {
+	c, err := NewAESFieldCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := c.Encrypt("cpf", json.RawMessage(`"12345678900"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(ciphertext, aesFieldPrefix) || strings.Contains(ciphertext, "12345678900") {
+		t.Errorf("Unexpected ciphertext %s", ciphertext)
+	}
+
+	plaintext, err := c.Decrypt("cpf", ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(plaintext) != `"12345678900"` {
+		t.Errorf("Unexpected plaintext %s", plaintext)
+	}
+
+	if _, err := c.Decrypt("cnpj", ciphertext); err == nil {
+		t.Error("Expected error decrypting value of another field")
+	}
+
+	if _, err := NewAESFieldCipher([]byte("short")); err == nil {
+		t.Error("Expected error with invalid key")
+	}
+}
+
+func TestWithFieldEncryption(t *testing.T) {
+	var received []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			received, _ = io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"nome":"Maria","cpf":"12345678900","enderecos":[{"cpf":"1"}]}]}`))
+	}))
+	defer server.Close()
+
+	c, _ := NewAESFieldCipher([]byte("0123456789abcdef"))
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithFieldEncryption(c, "cpf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clientes []map[string]interface{}
+	if _, err := client.Get("clientes", url.Values{}, &clientes); err != nil {
+		t.Fatal(err)
+	}
+
+	cpf, _ := clientes[0]["cpf"].(string)
+	if !strings.HasPrefix(cpf, aesFieldPrefix) || clientes[0]["nome"] != "Maria" {
+		t.Fatalf("Expected encrypted cpf but got %v", clientes[0])
+	}
+
+	nested := clientes[0]["enderecos"].([]interface{})[0].(map[string]interface{})
+	if nested["cpf"] == "1" {
+		t.Error("Expected nested cpf to be encrypted")
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"nome": "Maria", "cpf": cpf})
+	var r interface{}
+	if err := client.Post("clientes", body, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(received), `"cpf":"12345678900"`) {
+		t.Errorf("Expected decrypted cpf sent to Millennium but got %s", received)
+	}
+}
+
+func TestTransformFields(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Body     string
+		Expected string
+	}{
+		{Name: "order kept", Body: `{"z":1,"cpf":"123","a":{"cpf":"456","b":2}}`, Expected: `{"z":1,"cpf":"***","a":{"cpf":"***","b":2}}`},
+		{Name: "bytes kept", Body: `{"nome": "<A&B>", "valor": 1.50, "cpf" : "123"}`, Expected: `{"nome": "<A&B>", "valor": 1.50, "cpf" : "***"}`},
+		{Name: "arrays", Body: `[{"cpf":["1","2"]},{"nomes":["cpf"]}]`, Expected: `[{"cpf":"***"},{"nomes":["cpf"]}]`},
+		{Name: "values named as fields", Body: `{"nome":"cpf","cpf":null}`, Expected: `{"nome":"cpf","cpf":"***"}`},
+		{Name: "untouched", Body: `{"nome":"<A&B>"}`, Expected: `{"nome":"<A&B>"}`},
+		{Name: "not json", Body: `{"cpf":"123"`, Expected: `{"cpf":"123"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			body, err := transformFields([]byte(c.Body), func(field string) bool { return field == "cpf" }, func(string, json.RawMessage) (json.RawMessage, error) {
+				return json.RawMessage(`"***"`), nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(body) != c.Expected {
+				t.Errorf("Expected %s but got %s", c.Expected, body)
+			}
+		})
+	}
+}
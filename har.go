@@ -0,0 +1,249 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// HARRecorder captures the exchanges with Millennium in HAR format, which is
+// accepted by the Millennium support to diagnose server side issues.
+// Credentials and the fields of the client RedactionPolicy are redacted
+// from the captured exchanges.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder returns an empty HARRecorder
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// WithHARRecorder captures every request made by the client into recorder
+func WithHARRecorder(recorder *HARRecorder) Option {
+	return func(m *Millennium) {
+		m.har = recorder
+	}
+}
+
+// Len returns the number of captured exchanges
+func (r *HARRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+// Reset discards the captured exchanges
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// WriteTo writes the captured exchanges to w as a HAR document
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "millennium-go", Version: "1"},
+		Entries: append([]harEntry{}, r.entries...),
+	}}
+	r.mu.Unlock()
+
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(body)
+	return int64(n), err
+}
+
+func (r *HARRecorder) add(entry harEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entry)
+}
+
+// capture records the exchange once the response body is closed
+func (r *HARRecorder) capture(policy *RedactionPolicy, started time.Time, req *retryablehttp.Request, res *http.Response, err error) *http.Response {
+	reqBody, _ := req.BodyBytes()
+
+	entry := harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         policy.redactURL(req.URL).String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(policy, req.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Cache:   struct{}{},
+		Comment: OperationFrom(req.Context()),
+	}
+
+	for name, values := range policy.redactURL(req.URL).Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	if len(reqBody) > 0 {
+		entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(policy.Redact(reqBody))}
+	}
+
+	if err != nil {
+		entry.Response = harResponse{
+			StatusText: err.Error(),
+			Cookies:    []harNameValue{},
+			Headers:    []harNameValue{},
+			BodySize:   -1,
+		}
+		entry.Time = msSince(started)
+		entry.Timings = harTimings{Wait: entry.Time}
+		r.add(entry)
+		return res
+	}
+
+	waited := msSince(started)
+	body := &harBody{ReadCloser: res.Body}
+	body.done = func() {
+		entry.Response = harResponse{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: res.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(policy, res.Header),
+			Content: harContent{
+				Size:     body.buf.Len(),
+				MimeType: res.Header.Get("Content-Type"),
+				Text:     string(policy.Redact(body.buf.Bytes())),
+			},
+			HeadersSize: -1,
+			BodySize:    body.buf.Len(),
+		}
+		entry.Time = msSince(started)
+		entry.Timings = harTimings{Wait: waited, Receive: entry.Time - waited}
+		r.add(entry)
+	}
+
+	res.Body = body
+	return res
+}
+
+// harBody copies the response body while it is read
+type harBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func()
+	once sync.Once
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+func harHeaders(policy *RedactionPolicy, header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, values := range policy.redactHeader(header) {
+		for _, value := range values {
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,66 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHARRecorder(t *testing.T) {
+	recorder := NewHARRecorder()
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithHARRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{"filial": []string{"1"}}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Post("test.success.POST", []byte(`{"test":"test"}`), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Len() != 3 {
+		t.Fatalf("Expected 3 entries but got %d", recorder.Len())
+	}
+
+	var buf bytes.Buffer
+	if _, err := recorder.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "00000000-0000-0000-0000-000000000000") || strings.Contains(buf.String(), "TEST/TEST") {
+		t.Error("Credentials not redacted from HAR")
+	}
+
+	var doc harDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	get := doc.Log.Entries[1]
+	if get.Request.Method != "GET" || get.Response.Status != 200 || !strings.Contains(get.Response.Content.Text, `"odata.count"`) {
+		t.Errorf("Unexpected GET entry %+v", get)
+	}
+
+	post := doc.Log.Entries[2]
+	if post.Request.PostData == nil || post.Request.PostData.Text != `{"test":"test"}` {
+		t.Errorf("Unexpected POST entry %+v", post.Request)
+	}
+
+	recorder.Reset()
+	if recorder.Len() != 0 {
+		t.Error("Expected no entries after reset")
+	}
+}
@@ -0,0 +1,208 @@
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Health is the state of the link between the client and Millennium
+type Health string
+
+// Health states, from the most recent request results
+const (
+	HealthOK          Health = "OK"
+	HealthDegraded    Health = "DEGRADED"
+	HealthAuthFailed  Health = "AUTH_FAILED"
+	HealthUnreachable Health = "UNREACHABLE"
+)
+
+const (
+	// HealthWindow is the number of recent results considered by Health
+	HealthWindow = 10
+
+	// HealthUnreachableAfter is the number of consecutive network errors
+	// needed to consider Millennium unreachable
+	HealthUnreachableAfter = 3
+)
+
+type healthResult int
+
+const (
+	healthSuccess healthResult = iota
+	healthFailure
+	healthAuthFailure
+	healthNetworkFailure
+)
+
+// healthTracker keeps the most recent request results in a ring buffer
+type healthTracker struct {
+	mu      sync.Mutex
+	results [HealthWindow]healthResult
+	next    int
+	count   int
+}
+
+func (h *healthTracker) record(result healthResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.results[h.next] = result
+	h.next = (h.next + 1) % HealthWindow
+	if h.count < HealthWindow {
+		h.count++
+	}
+}
+
+// recordResponse classifies the outcome of a request to Millennium
+func (h *healthTracker) recordResponse(res *http.Response, err error) {
+	switch {
+	case err != nil:
+		h.record(healthNetworkFailure)
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		h.record(healthAuthFailure)
+	case res.StatusCode >= 500:
+		h.record(healthFailure)
+	default:
+		h.record(healthSuccess)
+	}
+}
+
+func (h *healthTracker) state() Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 {
+		return HealthOK
+	}
+
+	// Walk from the most recent result to the oldest one
+	failures, consecutiveNetwork := 0, 0
+	for i := 0; i < h.count; i++ {
+		result := h.results[(h.next-1-i+HealthWindow)%HealthWindow]
+
+		if i == 0 && result == healthAuthFailure {
+			return HealthAuthFailed
+		}
+
+		if result == healthNetworkFailure && consecutiveNetwork == i {
+			consecutiveNetwork++
+		}
+
+		if result != healthSuccess {
+			failures++
+		}
+	}
+
+	if consecutiveNetwork >= HealthUnreachableAfter {
+		return HealthUnreachable
+	}
+
+	if failures > 0 {
+		return HealthDegraded
+	}
+
+	return HealthOK
+}
+
+// Health returns the state of the link with Millennium based on the
+// most recent requests
+func (m *Millennium) Health() Health {
+	return m.health.state()
+}
+
+// HealthHandler returns an http.Handler to be used as readiness endpoint.
+// It responds 200 while Millennium is reachable and the credentials are
+// accepted, and 503 otherwise.
+func (m *Millennium) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := m.Health()
+
+		w.Header().Set("Content-Type", "application/json")
+		if health == HealthAuthFailed || health == HealthUnreachable {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(struct {
+			Status Health `json:"status"`
+		}{health})
+	})
+}
+
+// PingResult is the outcome of Ping
+type PingResult struct {
+	// Reachable tells Millennium answered, even with an error status
+	Reachable bool
+
+	// Latency is the time until the response headers were received
+	Latency time.Duration
+
+	// StatusCode of the response, zero when not reachable
+	StatusCode int
+}
+
+// Ping sends a single request to SessionKeepAliveMethod, without retries,
+// and reports if Millennium is reachable and how long it took, to be used
+// by readiness probes before starting the synchronizations.
+// It returns an error when Millennium is not reachable or rejects the
+// request, like an *ResponseError matching ErrUnauthorized for rejected
+// credentials. Sessions not requested yet are requested first.
+func (m *Millennium) Ping(ctx context.Context) (PingResult, error) {
+	if err := m.begin(ctx); err != nil {
+		return PingResult{}, err
+	}
+	defer m.inflight.Done()
+
+	if err := m.ensureSession(); err != nil {
+		return PingResult{}, err
+	}
+
+	req, err := m.newRequest(ctx, RequestMethod{
+		HTTPMethod: GET,
+		Method:     SessionKeepAliveMethod,
+		Params:     url.Values{"$top": {"1"}},
+	})
+	if err != nil {
+		return PingResult{}, err
+	}
+
+	// Bounded by the client timeouts, like the other requests, but sent
+	// without retries
+	deadlines := m.newDeadlines(ctx, m.methodConfig(SessionKeepAliveMethod))
+	defer deadlines.release()
+
+	started := time.Now()
+	res, err := m.Client.HTTPClient.Do(req.Request.WithContext(deadlines.ctx))
+	deadlines.received()
+	result := PingResult{Latency: time.Since(started)}
+	if err != nil {
+		err = deadlines.wrap(err)
+	}
+
+	if ctx.Err() == nil {
+		m.health.recordResponse(res, err)
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("unable to reach Millennium: %w", err)
+	}
+	defer res.Body.Close()
+
+	result.Reachable, result.StatusCode = true, res.StatusCode
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return result, fmt.Errorf("unable to read body from Millennium response: %w", err)
+	}
+
+	if res.StatusCode >= 400 {
+		return result, m.responseError(res, body)
+	}
+
+	return result, nil
+}
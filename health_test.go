@@ -0,0 +1,152 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHealthState(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK}
+	unauthorized := &http.Response{StatusCode: http.StatusUnauthorized}
+	serverError := &http.Response{StatusCode: http.StatusInternalServerError}
+	networkError := errors.New("connection refused")
+
+	type result struct {
+		Response *http.Response
+		Err      error
+	}
+
+	cases := []struct {
+		Name    string
+		Results []result
+		Expect  Health
+	}{
+		{Name: "no results", Expect: HealthOK},
+		{Name: "success", Results: []result{{Response: ok}}, Expect: HealthOK},
+		{Name: "recent failure", Results: []result{{Response: serverError}, {Response: ok}}, Expect: HealthDegraded},
+		{Name: "auth failed", Results: []result{{Response: ok}, {Response: unauthorized}}, Expect: HealthAuthFailed},
+		{Name: "few network errors", Results: []result{{Err: networkError}, {Err: networkError}}, Expect: HealthDegraded},
+		{Name: "unreachable", Results: []result{{Response: ok}, {Err: networkError}, {Err: networkError}, {Err: networkError}}, Expect: HealthUnreachable},
+		{Name: "recovered", Results: []result{{Err: networkError}, {Err: networkError}, {Err: networkError}, {Response: ok}}, Expect: HealthDegraded},
+		{
+			Name: "failure out of window",
+			Results: append(
+				[]result{{Response: serverError}},
+				[]result{{Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}, {Response: ok}}...,
+			),
+			Expect: HealthOK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var h healthTracker
+			for _, r := range c.Results {
+				h.recordResponse(r.Response, r.Err)
+			}
+
+			if state := h.state(); state != c.Expect {
+				t.Errorf("Expected %s but got %s", c.Expect, state)
+			}
+		})
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	client := NewTestClient(t)
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	client.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 but got %d", rec.Code)
+	}
+
+	if err := client.Login("wrong", "wrong", Session); err == nil {
+		t.Fatal("Expected login error")
+	}
+
+	rec = httptest.NewRecorder()
+	client.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 but got %d", rec.Code)
+	}
+
+	if client.Health() != HealthAuthFailed {
+		t.Errorf("Expected %s but got %s", HealthAuthFailed, client.Health())
+	}
+}
+
+func TestPing(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Status      int
+		Closed      bool
+		Reachable   bool
+		ExpectError error
+	}{
+		{Name: "ok", Status: http.StatusOK, Reachable: true},
+		{Name: "unauthorized", Status: http.StatusUnauthorized, Reachable: true, ExpectError: ErrUnauthorized},
+		{Name: "unreachable", Closed: true},
+		{Name: "client closed", ExpectError: ErrClosed},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(c.Status)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Closed {
+				server.Close()
+			}
+
+			if c.ExpectError == ErrClosed {
+				if err := client.Close(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := client.Ping(context.Background())
+			switch {
+			case c.Closed && err == nil:
+				t.Error("Expected error for an unreachable server")
+			case c.ExpectError != nil && !errors.Is(err, c.ExpectError):
+				t.Errorf("Expected %v but got %v", c.ExpectError, err)
+			case !c.Closed && c.ExpectError == nil && err != nil:
+				t.Fatal(err)
+			}
+
+			if result.Reachable != c.Reachable {
+				t.Errorf("Expected reachable %v but got %v", c.Reachable, result.Reachable)
+			}
+
+			if c.Reachable && (result.StatusCode != c.Status || path != "/api/"+SessionKeepAliveMethod) {
+				t.Errorf("Expected status %d from %s but got %d from %s", c.Status, SessionKeepAliveMethod, result.StatusCode, path)
+			}
+
+			if c.ExpectError != ErrClosed && result.Latency <= 0 {
+				t.Errorf("Expected latency but got %v", result.Latency)
+			}
+		})
+	}
+}
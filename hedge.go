@@ -0,0 +1,119 @@
+package millennium
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// WithHedging sends a second copy of the GET requests still waiting for a
+// response after delay, using whichever response arrives first and
+// canceling the other, masking the occasional multi-second stalls of
+// Millennium. Only GET requests are hedged, as they can be safely sent twice.
+// The copy takes a slot of WithScheduler, and is not sent when none is free.
+func WithHedging(delay time.Duration) Option {
+	return func(m *Millennium) {
+		m.hedgeDelay = delay
+	}
+}
+
+// send makes the request with client, hedging it when enabled
+func (m *Millennium) send(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+	if m.hedgeDelay <= 0 || request.Method != http.MethodGet {
+		return client.Do(request)
+	}
+
+	return m.hedge(client, request, m.hedgeDelay)
+}
+
+type hedgeResult struct {
+	res    *http.Response
+	err    error
+	index  int
+	cancel context.CancelFunc
+}
+
+// hedge sends request and, if no response arrived after delay, a copy of it,
+// returning the first successful response. The slot taken by the copy is
+// released once the losing copy is drained.
+func (m *Millennium) hedge(client *retryablehttp.Client, request *retryablehttp.Request, delay time.Duration) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+
+	launch := func() {
+		ctx, cancel := context.WithCancel(request.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+
+		go func() {
+			res, err := client.Do(request.WithContext(ctx))
+			results <- hedgeResult{res: res, err: err, index: index, cancel: cancel}
+		}()
+	}
+
+	launch()
+	pending := 1
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	release := func() {}
+
+	for {
+		select {
+		case <-timer.C:
+			// Hedging is skipped when the scheduler is full
+			if m.scheduler != nil {
+				if !m.scheduler.tryAcquire() {
+					continue
+				}
+				release = m.scheduler.release
+			}
+
+			launch()
+			pending++
+		case r := <-results:
+			pending--
+
+			// Wait for the other copy when this one failed
+			if r.err != nil && pending > 0 {
+				continue
+			}
+
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+
+			go func(pending int) {
+				drainHedges(results, pending)
+				release()
+			}(pending)
+
+			if r.err != nil {
+				r.cancel()
+				return nil, r.err
+			}
+
+			// The winner context lives until its body is closed
+			r.res.Body = &cancelBody{ReadCloser: r.res.Body, cancel: r.cancel}
+			return r.res, nil
+		}
+	}
+}
+
+// drainHedges closes the responses of the copies that lost the race
+func drainHedges(results <-chan hedgeResult, pending int) {
+	for ; pending > 0; pending-- {
+		r := <-results
+		if r.res != nil {
+			_, _ = io.Copy(io.Discard, r.res.Body)
+			r.res.Body.Close()
+		}
+		r.cancel()
+	}
+}
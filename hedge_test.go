@@ -0,0 +1,88 @@
+package millennium
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedging(t *testing.T) {
+	cases := []struct {
+		Name       string
+		HTTPMethod HTTPMethod
+		Stall      bool
+		Slots      int
+		Hits       int32
+		Max        time.Duration
+	}{
+		{Name: "stalled GET", HTTPMethod: GET, Stall: true, Hits: 2, Max: 200 * time.Millisecond},
+		{Name: "fast GET", HTTPMethod: GET, Hits: 1, Max: 200 * time.Millisecond},
+		{Name: "POST not hedged", HTTPMethod: POST, Stall: true, Hits: 1, Max: time.Second},
+		{Name: "free slot", HTTPMethod: GET, Stall: true, Slots: 2, Hits: 2, Max: 200 * time.Millisecond},
+		{Name: "no free slot", HTTPMethod: GET, Stall: true, Slots: 1, Hits: 1, Max: time.Second},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var hits, canceled int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The server only notices the client went away once the body is read
+				_, _ = io.Copy(io.Discard, r.Body)
+
+				// Only the first request stalls
+				if atomic.AddInt32(&hits, 1) == 1 && c.Stall {
+					select {
+					case <-r.Context().Done():
+						atomic.AddInt32(&canceled, 1)
+						return
+					case <-time.After(300 * time.Millisecond):
+					}
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"produto":1}]}`))
+			}))
+			defer server.Close()
+
+			opts := []Option{WithHedging(20 * time.Millisecond)}
+			if c.Slots > 0 {
+				opts = append(opts, WithMaxConcurrentRequests(c.Slots))
+			}
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			started := time.Now()
+			var r interface{}
+			if err := client.Request(RequestMethod{HTTPMethod: c.HTTPMethod, Method: "test", Body: []byte(`{}`), Response: &r}); err != nil {
+				t.Fatal(err)
+			}
+
+			if elapsed := time.Since(started); elapsed > c.Max {
+				t.Errorf("Expected a response within %s but took %s", c.Max, elapsed)
+			}
+
+			if atomic.LoadInt32(&hits) != c.Hits {
+				t.Errorf("Expected %d requests but got %d", c.Hits, hits)
+			}
+
+			if c.Hits == 2 {
+				deadline := time.Now().Add(time.Second)
+				for atomic.LoadInt32(&canceled) == 0 && time.Now().Before(deadline) {
+					time.Sleep(5 * time.Millisecond)
+				}
+
+				if atomic.LoadInt32(&canceled) != 1 {
+					t.Error("Expected the stalled request to be canceled")
+				}
+			}
+		})
+	}
+}
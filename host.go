@@ -0,0 +1,32 @@
+package millennium
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// WithHostOverride sends name as the Host header and as the TLS server name
+// (SNI) of every request, verifying the certificate against it, so
+// Millennium can be reached through an IP address while its certificate is
+// issued for a hostname
+func WithHostOverride(name string) Option {
+	return func(m *Millennium) {
+		m.hostOverride = name
+
+		if transport, ok := m.Client.HTTPClient.Transport.(*http.Transport); ok {
+			m.configureTransport(transport)
+		}
+	}
+}
+
+// configureTransport applies the client settings to a transport
+func (m *Millennium) configureTransport(transport *http.Transport) {
+	if m.hostOverride == "" {
+		return
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport.TLSClientConfig.ServerName = m.hostOverride
+}
@@ -0,0 +1,56 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithHostOverride(t *testing.T) {
+	var host atomic.Value
+	host.Store("")
+
+	// The test certificate is issued for example.com and 127.0.0.1
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host.Store(r.Host)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	}))
+	defer server.Close()
+
+	roots := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	cases := []struct {
+		Name  string
+		Host  string
+		Error bool
+	}{
+		{Name: "certificate host", Host: "example.com"},
+		{Name: "other host", Host: "millennium.example.org", Error: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithHostOverride(c.Host))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.Client.HTTPClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+			client.Client.RetryMax = 0
+
+			var r interface{}
+			_, err = client.Get("test", nil, &r)
+			if (err != nil) != c.Error {
+				t.Fatalf("Expected error to be %v but got %v", c.Error, err)
+			}
+
+			if !c.Error && host.Load() != c.Host {
+				t.Errorf("Expected Host %q but got %q", c.Host, host.Load())
+			}
+		})
+	}
+}
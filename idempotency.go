@@ -0,0 +1,55 @@
+package millennium
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ambiguous reports if a failed request may still have been applied by
+// Millennium, as opposed to failures rejected by Millennium or requests
+// that were never sent
+func ambiguous(err error) bool {
+	var notSent *notSentError
+	if errors.As(err, &notSent) {
+		return false
+	}
+
+	var resErr *ResponseError
+	if errors.As(err, &resErr) {
+		return resErr.Err.Code >= 500 || resErr.StatusCode >= 500
+	}
+
+	var schemaErr *SchemaError
+	return !errors.Is(err, ErrClosed) && !errors.Is(err, ErrQuotaExceeded) && !errors.Is(err, ErrURLTooLong) && !errors.As(err, &schemaErr)
+}
+
+// notSentError wraps the errors of the requests rejected by the client
+// before being sent, like the ones shed under load, which Millennium never
+// received
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string { return e.err.Error() }
+
+func (e *notSentError) Unwrap() error { return e.err }
+
+// verifyDelete checks with the method VerifyDelete whether a failed DELETE
+// was applied anyway
+func (m *Millennium) verifyDelete(r RequestMethod, err error) error {
+	verify := m.methodConfig(r.Method).VerifyDelete
+	if verify == nil || !ambiguous(err) {
+		return err
+	}
+
+	deleted, verifyErr := verify(r.Clone())
+	if verifyErr != nil {
+		return fmt.Errorf("%w (unable to verify the delete: %v)", err, verifyErr)
+	}
+
+	if deleted {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNonIdempotentDelete(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusInternalServerError, `{}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	if err := client.Delete("estoque.estorna", url.Values{}); err == nil {
+		t.Error("Expected error")
+	}
+
+	if atomic.LoadInt32(hits) != RetryMax+1 {
+		t.Errorf("Expected %d attempts but got %d", RetryMax+1, *hits)
+	}
+
+	atomic.StoreInt32(hits, 0)
+	client.Configure("estoque.estorna", MethodConfig{NonIdempotent: true})
+
+	if err := client.Delete("estoque.estorna", url.Values{}); err == nil {
+		t.Error("Expected error")
+	}
+
+	if atomic.LoadInt32(hits) != 1 {
+		t.Errorf("Expected a single attempt but got %d", *hits)
+	}
+}
+
+func TestVerifyDelete(t *testing.T) {
+	verifyErr := errors.New("verify failed")
+
+	cases := []struct {
+		Name        string
+		Status      int
+		Deleted     bool
+		VerifyErr   error
+		Verified    bool
+		ExpectError bool
+	}{
+		{Name: "deleted", Status: http.StatusInternalServerError, Deleted: true, Verified: true},
+		{Name: "not deleted", Status: http.StatusInternalServerError, Verified: true, ExpectError: true},
+		{Name: "verify error", Status: http.StatusInternalServerError, VerifyErr: verifyErr, Verified: true, ExpectError: true},
+		{Name: "rejected", Status: http.StatusBadRequest, Deleted: true, ExpectError: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, _ := newCountingServer(t, c.Status, `{"error":{"code":400,"message":{"lang":"pt-BR","value":"erro"}}}`)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			verified := false
+			client.Configure("estoque.estorna", MethodConfig{
+				NonIdempotent: true,
+				VerifyDelete: func(r RequestMethod) (bool, error) {
+					verified = true
+					if r.Params.Get("estoque") != "1" {
+						t.Errorf("Unexpected params %v", r.Params)
+					}
+					return c.Deleted, c.VerifyErr
+				},
+			})
+
+			err = client.Delete("estoque.estorna", url.Values{"estoque": {"1"}})
+			if (err != nil) != c.ExpectError {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			if verified != c.Verified {
+				t.Errorf("Expected verified %v but got %v", c.Verified, verified)
+			}
+
+			if c.VerifyErr != nil && (err == nil || !strings.Contains(err.Error(), c.VerifyErr.Error())) {
+				t.Errorf("Expected verify error in %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+//go:build go1.23
+
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+)
+
+// errStopIteration stops the listing when the consumer breaks the loop
+var errStopIteration = errors.New("iteration stopped")
+
+// Iterate requests a method using GET http method page by page, like
+// ListAll, yielding the records one at a time as T, so only one page is held
+// in memory. An error ends the iteration after being yielded with the zero
+// value of T. Breaking the loop stops requesting pages.
+func Iterate[T any](ctx context.Context, m *Millennium, method string, params url.Values) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		_, err := m.listPages(ctx, method, params, func(page []json.RawMessage) error {
+			for _, raw := range page {
+				var record T
+				if err := json.Unmarshal(raw, &record); err != nil {
+					return fmt.Errorf("unable to unmarshal JSON: %w", err)
+				}
+
+				if !yield(record, nil) {
+					return errStopIteration
+				}
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build go1.23
+
+package millennium
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIterate(t *testing.T) {
+	server, pages := newPagedServer(t, 23, true, 0)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithPageSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type record struct {
+		Number int
+	}
+
+	t.Run("all records", func(t *testing.T) {
+		i := 0
+		for record, err := range Iterate[record](context.Background(), client, "test", nil) {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if record.Number != i {
+				t.Errorf("Expected record %d but got %d", i, record.Number)
+			}
+			i++
+		}
+
+		if i != 23 {
+			t.Errorf("Expected 23 records but got %d", i)
+		}
+	})
+
+	t.Run("break", func(t *testing.T) {
+		atomic.StoreInt32(pages, 0)
+
+		for record := range Iterate[record](context.Background(), client, "test", nil) {
+			if record.Number == 5 {
+				break
+			}
+		}
+
+		if atomic.LoadInt32(pages) != 1 {
+			t.Errorf("Expected 1 page but got %d", *pages)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		client := NewTestClient(t)
+
+		count := 0
+		for _, err := range Iterate[record](context.Background(), client, "test.error400", nil) {
+			count++
+			if err == nil {
+				t.Error("Expected error but got nil")
+			}
+		}
+
+		if count != 1 {
+			t.Errorf("Expected 1 error but got %d yields", count)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package millennium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// JoinConcurrency is the default number of detail requests sent at once by
+// GetJoined
+const JoinConcurrency = 4
+
+// Join describes the detail method fetched for each record by GetJoined
+type Join struct {
+	// Method is the detail method
+	Method string
+
+	// Params are sent with every detail request
+	Params url.Values
+
+	// Key is the field of the list record identifying its detail
+	Key string
+
+	// Param receives the key on the detail request, defaults to Key
+	Param string
+
+	// Field receives the detail records, defaults to Method
+	Field string
+
+	// Concurrency limits the detail requests sent at once, defaults to
+	// JoinConcurrency
+	Concurrency int
+}
+
+// GetJoined requests a list method and, for each record, the detail method
+// of join, adding the detail records to the record field join.Field.
+// Many Millennium list methods omit nested data, like the items of an
+// order, so the details are fetched concurrently. Repeated keys are
+// requested only once. Records without the key are returned untouched.
+func (m *Millennium) GetJoined(method string, params url.Values, join Join) ([]Record, error) {
+	if join.Param == "" {
+		join.Param = join.Key
+	}
+
+	if join.Field == "" {
+		join.Field = join.Method
+	}
+
+	if join.Concurrency <= 0 {
+		join.Concurrency = JoinConcurrency
+	}
+
+	var records []Record
+	if _, err := m.Get(method, params, &records); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	details := map[string]json.RawMessage{}
+	for _, record := range records {
+		key, ok := joinKey(record[join.Key])
+		if !ok {
+			continue
+		}
+
+		if _, seen := details[key]; !seen {
+			details[key] = nil
+			keys = append(keys, key)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		sem      = make(chan struct{}, join.Concurrency)
+	)
+
+	for _, key := range keys {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			detailParams := cloneParams(join.Params)
+			detailParams.Set(join.Param, key)
+
+			var detail json.RawMessage
+			_, err := m.Get(join.Method, detailParams, &detail)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("unable to get %s for %s=%s: %w", join.Method, join.Key, key, err)
+				}
+				return
+			}
+
+			details[key] = detail
+		}(key)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	for _, record := range records {
+		if key, ok := joinKey(record[join.Key]); ok {
+			record[join.Field] = details[key]
+		}
+	}
+
+	return records, nil
+}
+
+// joinKey returns the text of a record key, unquoting strings
+func joinKey(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", false
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, true
+	}
+
+	return strings.TrimSpace(string(raw)), true
+}
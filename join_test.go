@@ -0,0 +1,88 @@
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetJoined(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		hits = map[string]int{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/pedidos.lista":
+			fmt.Fprint(w, `{"odata.count":4,"value":[{"pedido":1},{"pedido":2},{"pedido":1},{"nome":"sem pedido"}]}`)
+		case "/api/pedidos.itens":
+			pedido := r.URL.Query().Get("pedido")
+
+			mu.Lock()
+			hits[pedido]++
+			mu.Unlock()
+
+			if r.URL.Query().Get("filial") != "1" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"code":400,"message":{"lang":"pt-BR","value":"filial"}}}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{"odata.count":1,"value":[{"produto":"P%s"}]}`, pedido)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := client.GetJoined("pedidos.lista", url.Values{}, Join{
+		Method: "pedidos.itens",
+		Params: url.Values{"filial": {"1"}},
+		Key:    "pedido",
+		Field:  "itens",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records but got %d", len(records))
+	}
+
+	for i, expected := range []string{`[{"produto":"P1"}]`, `[{"produto":"P2"}]`, `[{"produto":"P1"}]`, ``} {
+		if got := string(records[i]["itens"]); got != expected {
+			t.Errorf("Record %d: expected %s but got %s", i, expected, got)
+		}
+	}
+
+	if hits["1"] != 1 || hits["2"] != 1 {
+		t.Errorf("Expected one detail request per key but got %v", hits)
+	}
+
+	var item struct {
+		Produto string
+	}
+	var items []Record
+	if err := json.Unmarshal(records[1]["itens"], &items); err != nil {
+		t.Fatal(err)
+	}
+	if err := items[0].ScanStruct(&item); err != nil || item.Produto != "P2" {
+		t.Errorf("Unexpected item %+v: %v", item, err)
+	}
+
+	if _, err := client.GetJoined("pedidos.lista", url.Values{}, Join{Method: "pedidos.itens", Key: "pedido"}); err == nil {
+		t.Error("Expected error")
+	}
+}
@@ -0,0 +1,82 @@
+package millennium
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// SessionKeepAliveMethod is the method requested by the session keepalive.
+// Any cheap method the user is allowed to call can be set instead.
+var SessionKeepAliveMethod = "millenium.utils.ping"
+
+// WithSessionKeepAlive requests SessionKeepAliveMethod every interval while
+// the client holds a WTS session, so sessions of clients idle between
+// synchronizations do not expire. The keepalive runs until
+// StopSessionKeepAlive or Close is called.
+func WithSessionKeepAlive(interval time.Duration) Option {
+	return func(m *Millennium) {
+		m.keepAlive.interval = interval
+	}
+}
+
+// keepAlive is the state of the session keepalive goroutine
+type keepAlive struct {
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+	once     sync.Once
+}
+
+// startKeepAlive starts the session keepalive, if set
+func (m *Millennium) startKeepAlive() {
+	if m.keepAlive.interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(m.Context)
+	m.keepAlive.cancel = cancel
+	m.keepAlive.done = make(chan struct{})
+
+	go func() {
+		defer close(m.keepAlive.done)
+
+		for {
+			select {
+			case <-m.after(m.keepAlive.interval):
+				m.pingSession(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// pingSession requests SessionKeepAliveMethod if there is a session to keep
+func (m *Millennium) pingSession(ctx context.Context) {
+	creds := m.getCredentials()
+	if creds.AuthType != Session || creds.Session == "" {
+		return
+	}
+
+	var r interface{}
+	if _, err := m.GetCtx(ctx, SessionKeepAliveMethod, url.Values{"$top": {"1"}}, &r); err != nil && ctx.Err() == nil {
+		if logger, ok := m.Client.Logger.(retryablehttp.Logger); ok {
+			logger.Printf("[WARN] unable to keep the Millennium session alive: %v", err)
+		}
+	}
+}
+
+// StopSessionKeepAlive stops the session keepalive started by
+// WithSessionKeepAlive, waiting for a ping in progress to be canceled
+func (m *Millennium) StopSessionKeepAlive() {
+	if m.keepAlive.cancel == nil {
+		return
+	}
+
+	m.keepAlive.once.Do(m.keepAlive.cancel)
+	<-m.keepAlive.done
+}
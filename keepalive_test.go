@@ -0,0 +1,64 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionKeepAlive(t *testing.T) {
+	var pings int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+	})
+	mux.HandleFunc("/api/"+SessionKeepAliveMethod, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("WTS-Session") == "" {
+			t.Error("Expected the keepalive to send the session")
+		}
+		atomic.AddInt32(&pings, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithSessionKeepAlive(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&pings) != 0 {
+		t.Fatal("Expected no pings before the login")
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if atomic.LoadInt32(&pings) < 2 {
+		t.Fatalf("Expected the session to be pinged but got %d pings", pings)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := atomic.LoadInt32(&pings)
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&pings) != stopped {
+		t.Errorf("Expected no pings after Close but got %d", atomic.LoadInt32(&pings)-stopped)
+	}
+
+	// Stopping again is harmless
+	client.StopSessionKeepAlive()
+}
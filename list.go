@@ -0,0 +1,137 @@
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// DefaultPageSize is the number of records requested per page by ListAll
+const DefaultPageSize = 500
+
+// MinPageSize is the smallest page requested by ListAll when halving the
+// pages that time out
+const MinPageSize = 10
+
+// WithPageSize sets the number of records requested per page by ListAll,
+// which can be overridden per method with MethodConfig.PageSize
+func WithPageSize(size int) Option {
+	return func(m *Millennium) {
+		m.pageSize = size
+	}
+}
+
+// ListAll requests a method using GET http method page by page, with $top
+// and $skip, until every record reported by odata.count is fetched,
+// appending the records of each page to the slice pointed by response.
+// Any $top or $skip in params is replaced. It returns the number of records
+// appended.
+// Pages that time out, or are answered with 504 Gateway Timeout, are
+// requested again with half the size, down to MinPageSize, and the smaller
+// size is kept for the next pages.
+func (m *Millennium) ListAll(method string, params url.Values, response interface{}) (int, error) {
+	return m.ListAllCtx(m.Context, method, params, response)
+}
+
+// ListAllCtx is ListAll bound to ctx instead of the client context
+func (m *Millennium) ListAllCtx(ctx context.Context, method string, params url.Values, response interface{}) (int, error) {
+	v := reflect.ValueOf(response)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return 0, errors.New("response should be a non-nil pointer to a slice")
+	}
+
+	slice := v.Elem()
+	elem := slice.Type().Elem()
+
+	return m.listPages(ctx, method, params, func(page []json.RawMessage) error {
+		for _, raw := range page {
+			item := reflect.New(elem)
+			if err := json.Unmarshal(raw, item.Interface()); err != nil {
+				return fmt.Errorf("unable to unmarshal JSON: %w", err)
+			}
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+		return nil
+	})
+}
+
+// pageSizeFor returns the page size used for method
+func (m *Millennium) pageSizeFor(method string) int {
+	if size := m.methodConfig(method).PageSize; size > 0 {
+		return size
+	}
+
+	if m.pageSize > 0 {
+		return m.pageSize
+	}
+
+	return DefaultPageSize
+}
+
+// listPages requests method page by page, calling fn with the records of
+// each page, and returns the number of records fetched
+func (m *Millennium) listPages(ctx context.Context, method string, params url.Values, fn func(page []json.RawMessage) error) (int, error) {
+	size := m.pageSizeFor(method)
+	fetched := 0
+	check := m.newPagingChecker(method)
+
+	for {
+		pageParams := cloneParams(params)
+		pageParams.Set("$top", strconv.Itoa(size))
+		pageParams.Set("$skip", strconv.Itoa(fetched))
+
+		var page []json.RawMessage
+		count, err := m.GetCtx(ctx, method, pageParams, &page)
+		if err != nil {
+			// Huge pages of wide entities are the usual cause of timeouts
+			if size > MinPageSize && ctx.Err() == nil && pageTimedOut(err) {
+				size = max(size/2, MinPageSize)
+				continue
+			}
+			return fetched, err
+		}
+
+		if err := check.page(fetched, page); err != nil {
+			return fetched, err
+		}
+
+		if err := fn(page); err != nil {
+			return fetched, err
+		}
+		fetched += len(page)
+
+		// With odata.count the listing goes on until every record is
+		// fetched, as servers capping $top return short pages before the
+		// end. Without it a short page is the last one.
+		if len(page) == 0 || (count > 0 && fetched >= count) || (count == 0 && len(page) < size) {
+			return fetched, check.done(count)
+		}
+	}
+}
+
+// pageTimedOut reports if a page failed by taking too long, either on the
+// client or on a gateway in front of Millennium
+func pageTimedOut(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrHeaderTimeout) || errors.Is(err, ErrIdleTimeout) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) && len(retryErr.Attempts) > 0 {
+		return retryErr.Attempts[len(retryErr.Attempts)-1].StatusCode == http.StatusGatewayTimeout
+	}
+
+	var resErr *ResponseError
+	return errors.As(err, &resErr) && (resErr.StatusCode == http.StatusGatewayTimeout || resErr.Err.Code == http.StatusGatewayTimeout)
+}
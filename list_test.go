@@ -0,0 +1,182 @@
+package millennium
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPagedServer returns a server holding total records, paged by $top and
+// $skip, and the number of pages requested
+// newPagedServer serves total records, capping $top at maxTop when not zero
+func newPagedServer(t *testing.T, total int, reportCount bool, maxTop int) (*httptest.Server, *int32) {
+	var pages int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pages, 1)
+
+		top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+		skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+		if maxTop > 0 {
+			top = min(top, maxTop)
+		}
+
+		var values []string
+		for i := skip; i < total && i < skip+top; i++ {
+			values = append(values, fmt.Sprintf(`{"number":%d}`, i))
+		}
+
+		count := 0
+		if reportCount {
+			count = total
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"odata.count":%d,"value":[%s]}`, count, strings.Join(values, ","))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &pages
+}
+
+func TestListAll(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Total       int
+		PageSize    int
+		ReportCount bool
+		MaxTop      int
+		Pages       int32
+	}{
+		{Name: "partial last page", Total: 23, PageSize: 10, ReportCount: true, Pages: 3},
+		{Name: "exact pages", Total: 20, PageSize: 10, ReportCount: true, Pages: 2},
+		{Name: "without count", Total: 20, PageSize: 10, Pages: 3},
+		{Name: "empty", Total: 0, PageSize: 10, ReportCount: true, Pages: 1},
+		{Name: "capped pages", Total: 23, PageSize: 10, ReportCount: true, MaxTop: 4, Pages: 6},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, pages := newPagedServer(t, c.Total, c.ReportCount, c.MaxTop)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithPageSize(c.PageSize))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			records := []struct {
+				Number int
+			}{{Number: -1}}
+
+			count, err := client.ListAll("test", url.Values{"$top": {"1"}}, &records)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != c.Total || len(records) != c.Total+1 {
+				t.Fatalf("Expected %d records but got %d (%d in slice)", c.Total, count, len(records))
+			}
+
+			for i, record := range records[1:] {
+				if record.Number != i {
+					t.Errorf("Expected record %d but got %d", i, record.Number)
+				}
+			}
+
+			if atomic.LoadInt32(pages) != c.Pages {
+				t.Errorf("Expected %d pages but got %d", c.Pages, *pages)
+			}
+		})
+	}
+}
+
+func TestListAllMethodPageSize(t *testing.T) {
+	server, pages := newPagedServer(t, 10, true, 0)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Configure("test", MethodConfig{PageSize: 2})
+
+	var records []Record
+	if _, err := client.ListAll("test", nil, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 10 || atomic.LoadInt32(pages) != 5 {
+		t.Errorf("Expected 10 records in 5 pages but got %d in %d", len(records), *pages)
+	}
+
+	var notSlice Record
+	if _, err := client.ListAll("test", nil, &notSlice); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestListAllHalvesTimedOutPages(t *testing.T) {
+	cases := []struct {
+		Name    string
+		MaxTop  int
+		Records int
+		Error   bool
+	}{
+		{Name: "halved until it fits", MaxTop: 10, Records: 23},
+		{Name: "floor reached", MaxTop: 5, Error: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			paged, _ := newPagedServer(t, 23, true, 0)
+			target, _ := url.Parse(paged.URL)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+				if top > c.MaxTop {
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+
+				r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+				res, err := http.Get(r.URL.String())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer res.Body.Close()
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.Copy(w, res.Body)
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithPageSize(40))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.Client.RetryWaitMin = time.Millisecond
+			client.Client.RetryWaitMax = time.Millisecond
+
+			var records []struct {
+				Number int
+			}
+
+			count, err := client.ListAll("test", nil, &records)
+			if (err != nil) != c.Error {
+				t.Fatalf("Expected error to be %v but got %v", c.Error, err)
+			}
+
+			if count != c.Records || len(records) != c.Records {
+				t.Errorf("Expected %d records but got %d (%d in slice)", c.Records, count, len(records))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package millennium
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LookupTable caches reference data from Millennium, like units, warehouses
+// or payment terms, indexed by a key field. The method is requested on the
+// first Get and again once the TTL expires. It is safe for concurrent use,
+// and goroutines asking for an expired table wait for a single request.
+type LookupTable struct {
+	// Params are sent with every request of the method
+	Params url.Values
+
+	m      *Millennium
+	method string
+	key    string
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	records map[string]Record
+	loaded  time.Time
+	call    *sharedCall
+}
+
+// NewLookupTable returns a LookupTable loading method and indexing its
+// records by the key field. A zero ttl keeps the records until Refresh is
+// called.
+func (m *Millennium) NewLookupTable(method string, key string, ttl time.Duration) *LookupTable {
+	return &LookupTable{Params: url.Values{}, m: m, method: method, key: key, ttl: ttl}
+}
+
+// Get returns the record with code, loading the table if it was never loaded
+// or if it expired. If the reload fails, the error is returned and the next
+// Get tries again.
+func (t *LookupTable) Get(code string) (Record, bool, error) {
+	t.mu.Lock()
+	fresh := t.records != nil && (t.ttl <= 0 || time.Since(t.loaded) < t.ttl)
+	t.mu.Unlock()
+
+	if !fresh {
+		if err := t.Refresh(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.records[code]
+	return record, ok, nil
+}
+
+// Refresh reloads the table from Millennium.
+// Concurrent calls share a single request.
+func (t *LookupTable) Refresh() error {
+	t.mu.Lock()
+	if call := t.call; call != nil {
+		t.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+
+	call := &sharedCall{done: make(chan struct{})}
+	t.call = call
+	t.mu.Unlock()
+
+	records, err := t.load()
+
+	t.mu.Lock()
+	if err == nil {
+		t.records = records
+		t.loaded = time.Now()
+	}
+	t.call = nil
+	t.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+	return err
+}
+
+func (t *LookupTable) load() (map[string]Record, error) {
+	var list []Record
+	if _, err := t.m.Get(t.method, cloneParams(t.Params), &list); err != nil {
+		return nil, fmt.Errorf("unable to load lookup table %s: %w", t.method, err)
+	}
+
+	records := make(map[string]Record, len(list))
+	for _, record := range list {
+		if key, ok := joinKey(record[t.key]); ok {
+			records[key] = record
+		}
+	}
+
+	return records, nil
+}
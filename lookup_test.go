@@ -0,0 +1,79 @@
+package millennium
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupTable(t *testing.T) {
+	var hits, fail int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":400,"message":{"lang":"pt-BR","value":"erro"}}}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"odata.count":2,"value":[{"unidade":"UN","descricao":"Unidade %d"},{"unidade":"CX","descricao":"Caixa"}]}`, n)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := client.NewLookupTable("unidades.lista", "unidade", 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, ok, err := table.Get("UN"); err != nil || !ok {
+				t.Errorf("Expected record but got %v %v", ok, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("Expected a single load but got %d", hits)
+	}
+
+	if _, ok, err := table.Get("KG"); err != nil || ok {
+		t.Errorf("Expected no record but got %v %v", ok, err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	record, _, err := table.Get("UN")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var unidade struct {
+		Descricao string
+	}
+	if err := record.ScanStruct(&unidade); err != nil || unidade.Descricao != "Unidade 2" {
+		t.Errorf("Expected reloaded record but got %+v %v", unidade, err)
+	}
+
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(60 * time.Millisecond)
+
+	if _, _, err := table.Get("UN"); err == nil {
+		t.Error("Expected error")
+	}
+}
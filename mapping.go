@@ -0,0 +1,279 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Converter transforms a JSON value while it is mapped. Values are the ones
+// produced by encoding/json, with numbers as json.Number.
+type Converter func(value interface{}) (interface{}, error)
+
+// FieldMapping maps a field of the source document to a field of the target.
+// Paths are dotted field names, like cliente.nome.
+type FieldMapping struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+
+	// Converter is the name of the converter applied to the value
+	Converter string `json:"converter,omitempty"`
+
+	// Reverse is the name of the converter applied when mapping back, see
+	// Mapping.Reverse
+	Reverse string `json:"reverse,omitempty"`
+
+	// Default is used when the source field is missing or null
+	Default json.RawMessage `json:"default,omitempty"`
+
+	// Required fails the mapping when the source field is missing and there
+	// is no default
+	Required bool `json:"required,omitempty"`
+}
+
+// Mapping declares how documents of an external system, like an e-commerce
+// order, turn into Millennium bodies and back. Since it is plain data, it
+// can be loaded from a file with ParseMapping and changed without code
+// changes. Fields not listed are dropped.
+type Mapping struct {
+	Fields []FieldMapping `json:"fields"`
+
+	converters map[string]Converter
+}
+
+// ParseMapping reads a Mapping from its JSON form:
+//
+//	{"fields":[{"source":"sku","target":"produto","converter":"upper"}]}
+func ParseMapping(data []byte) (*Mapping, error) {
+	var mapping Mapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("unable to parse mapping: %w", err)
+	}
+
+	return &mapping, nil
+}
+
+// RegisterConverter makes a converter available to the fields of the
+// mapping, replacing any built-in converter with the same name.
+// The built-in converters are string, number, br_number, bool, upper, lower
+// and trim.
+func (mp *Mapping) RegisterConverter(name string, converter Converter) {
+	if mp.converters == nil {
+		mp.converters = map[string]Converter{}
+	}
+
+	mp.converters[name] = converter
+}
+
+// Reverse returns the mapping from the target back to the source, using
+// the Reverse converter of each field. Defaults and required fields only
+// apply to the original direction.
+func (mp *Mapping) Reverse() *Mapping {
+	reverse := &Mapping{
+		Fields:     make([]FieldMapping, len(mp.Fields)),
+		converters: mp.converters,
+	}
+
+	for i, field := range mp.Fields {
+		reverse.Fields[i] = FieldMapping{
+			Source:    field.Target,
+			Target:    field.Source,
+			Converter: field.Reverse,
+			Reverse:   field.Converter,
+		}
+	}
+
+	return reverse
+}
+
+// Apply maps a JSON object, or each object of a JSON array, returning the
+// mapped JSON ready to be sent with Post
+func (mp *Mapping) Apply(data []byte) ([]byte, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unable to decode document: %w", err)
+	}
+
+	var mapped interface{}
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		object, err := mp.applyObject(v)
+		if err != nil {
+			return nil, err
+		}
+		mapped = object
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unable to map item %d: expected object", i)
+			}
+
+			mappedItem, err := mp.applyObject(object)
+			if err != nil {
+				return nil, fmt.Errorf("unable to map item %d: %w", i, err)
+			}
+			items[i] = mappedItem
+		}
+		mapped = items
+	default:
+		return nil, fmt.Errorf("unable to map document: expected object or array")
+	}
+
+	return json.Marshal(mapped)
+}
+
+func (mp *Mapping) applyObject(src map[string]interface{}) (map[string]interface{}, error) {
+	dst := map[string]interface{}{}
+
+	for _, field := range mp.Fields {
+		value, ok := lookupPath(src, field.Source)
+		if !ok || value == nil {
+			switch {
+			case len(field.Default) > 0:
+				dec := json.NewDecoder(bytes.NewReader(field.Default))
+				dec.UseNumber()
+				if err := dec.Decode(&value); err != nil {
+					return nil, fmt.Errorf("invalid default of field %s: %w", field.Source, err)
+				}
+			case field.Required:
+				return nil, fmt.Errorf("missing required field %s", field.Source)
+			default:
+				continue
+			}
+		}
+
+		if field.Converter != "" {
+			converter, err := mp.converter(field.Converter)
+			if err != nil {
+				return nil, err
+			}
+
+			if value, err = converter(value); err != nil {
+				return nil, fmt.Errorf("unable to convert field %s: %w", field.Source, err)
+			}
+		}
+
+		setPath(dst, field.Target, value)
+	}
+
+	return dst, nil
+}
+
+func (mp *Mapping) converter(name string) (Converter, error) {
+	if converter, ok := mp.converters[name]; ok {
+		return converter, nil
+	}
+
+	if converter, ok := builtinConverters[name]; ok {
+		return converter, nil
+	}
+
+	return nil, fmt.Errorf("unknown converter %s", name)
+}
+
+func lookupPath(object map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = object
+
+	for _, name := range strings.Split(path, ".") {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = current[name]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func setPath(object map[string]interface{}, path string, value interface{}) {
+	names := strings.Split(path, ".")
+
+	for _, name := range names[:len(names)-1] {
+		next, ok := object[name].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			object[name] = next
+		}
+		object = next
+	}
+
+	object[names[len(names)-1]] = value
+}
+
+var builtinConverters = map[string]Converter{
+	"string": func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		return nil, fmt.Errorf("expected scalar but got %s", jsonType(value))
+	},
+	"number": func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case json.Number:
+			return v, nil
+		case string:
+			if _, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", v)
+			}
+			return json.Number(strings.TrimSpace(v)), nil
+		}
+		return nil, fmt.Errorf("expected number but got %s", jsonType(value))
+	},
+	"br_number": func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case json.Number:
+			return v, nil
+		case string:
+			f, err := ParseBRNumber(v)
+			if err != nil {
+				return nil, err
+			}
+			return json.Number(strconv.FormatFloat(f, 'f', -1, 64)), nil
+		}
+		return nil, fmt.Errorf("expected number but got %s", jsonType(value))
+	},
+	"bool": func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case json.Number:
+			return v.String() != "0", nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "1", "s", "sim":
+				return true, nil
+			case "false", "0", "n", "nao", "não":
+				return false, nil
+			}
+			return nil, fmt.Errorf("invalid boolean %q", v)
+		}
+		return nil, fmt.Errorf("expected boolean but got %s", jsonType(value))
+	},
+	"upper": stringConverter(strings.ToUpper),
+	"lower": stringConverter(strings.ToLower),
+	"trim":  stringConverter(strings.TrimSpace),
+}
+
+func stringConverter(fn func(string) string) Converter {
+	return func(value interface{}) (interface{}, error) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string but got %s", jsonType(value))
+		}
+		return fn(s), nil
+	}
+}
@@ -0,0 +1,89 @@
+package millennium
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapping(t *testing.T) {
+	mapping, err := ParseMapping([]byte(`{"fields":[
+		{"source":"id","target":"cod_pedido","converter":"string","reverse":"number"},
+		{"source":"customer.name","target":"cliente.nome","converter":"upper"},
+		{"source":"total","target":"valor","converter":"br_number","reverse":"discount"},
+		{"source":"gift","target":"presente","converter":"bool"},
+		{"source":"channel","target":"canal","default":"site"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapping.RegisterConverter("discount", func(value interface{}) (interface{}, error) {
+		return value, nil
+	})
+
+	body, err := mapping.Apply([]byte(`{"id":42,"customer":{"name":"Maria"},"total":"1.234,50","gift":"S","ignored":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"cod_pedido": "42",
+		"cliente":    map[string]interface{}{"nome": "MARIA"},
+		"valor":      1234.5,
+		"presente":   true,
+		"canal":      "site",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+	}
+
+	back, err := mapping.Reverse().Apply(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(back), `"id":42`) || !strings.Contains(string(back), `"customer":{"name":"MARIA"}`) {
+		t.Errorf("Unexpected reverse mapping %s", back)
+	}
+
+	batch, err := mapping.Apply([]byte(`[{"id":"1"},{"id":"2","channel":"loja"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(batch) != `[{"canal":"site","cod_pedido":"1"},{"canal":"loja","cod_pedido":"2"}]` {
+		t.Errorf("Unexpected batch mapping %s", batch)
+	}
+}
+
+func TestMappingErrors(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Fields []FieldMapping
+		Body   string
+	}{
+		{Name: "required", Fields: []FieldMapping{{Source: "id", Target: "cod", Required: true}}, Body: `{}`},
+		{Name: "unknown converter", Fields: []FieldMapping{{Source: "id", Target: "cod", Converter: "x"}}, Body: `{"id":1}`},
+		{Name: "invalid number", Fields: []FieldMapping{{Source: "id", Target: "cod", Converter: "br_number"}}, Body: `{"id":"1.2.3"}`},
+		{Name: "invalid item", Fields: []FieldMapping{{Source: "id", Target: "cod"}}, Body: `[1]`},
+		{Name: "invalid document", Fields: []FieldMapping{{Source: "id", Target: "cod"}}, Body: `"x"`},
+		{Name: "invalid json", Fields: []FieldMapping{{Source: "id", Target: "cod"}}, Body: `{`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			mapping := &Mapping{Fields: c.Fields}
+			if _, err := mapping.Apply([]byte(c.Body)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
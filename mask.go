@@ -0,0 +1,41 @@
+package millennium
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// WithFieldMask replaces the value of the given fields, at any depth of the
+// responses, with replacement before they reach the application, so the
+// client sees less than the Millennium user can, like salaries and cost
+// prices. A nil replacement strips the values, decoding them as null.
+// Field names are case insensitive.
+func WithFieldMask(replacement interface{}, fields ...string) Option {
+	return func(m *Millennium) {
+		mask, err := json.Marshal(replacement)
+		if err != nil {
+			mask = []byte("null")
+		}
+
+		m.fieldMask = mask
+		m.maskedFields = map[string]bool{}
+		for _, field := range fields {
+			m.maskedFields[strings.ToLower(field)] = true
+		}
+	}
+}
+
+// maskFields replaces the masked fields of a JSON body
+func (m *Millennium) maskFields(body []byte) ([]byte, error) {
+	if len(m.maskedFields) == 0 || len(body) == 0 {
+		return body, nil
+	}
+
+	return transformFields(body, m.isMaskedField, func(string, json.RawMessage) (json.RawMessage, error) {
+		return m.fieldMask, nil
+	})
+}
+
+func (m *Millennium) isMaskedField(field string) bool {
+	return m.maskedFields[strings.ToLower(field)]
+}
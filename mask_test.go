@@ -0,0 +1,72 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithFieldMask(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"nome":"Maria","Salario":5000,"precos":[{"custo":10.5,"venda":20}]}]}`))
+	}))
+	defer server.Close()
+
+	type record struct {
+		Nome    string      `json:"nome"`
+		Salario interface{} `json:"Salario"`
+		Precos  []struct {
+			Custo interface{} `json:"custo"`
+			Venda float64     `json:"venda"`
+		} `json:"precos"`
+	}
+
+	cases := []struct {
+		Name        string
+		Replacement interface{}
+		Expected    interface{}
+	}{
+		{Name: "strip", Replacement: nil, Expected: nil},
+		{Name: "placeholder", Replacement: "***", Expected: "***"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithFieldMask(c.Replacement, "salario", "custo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(r record) {
+				if r.Nome != "Maria" || len(r.Precos) != 1 || r.Precos[0].Venda != 20 {
+					t.Errorf("Expected other fields untouched but got %+v", r)
+				}
+
+				if r.Salario != c.Expected || r.Precos[0].Custo != c.Expected {
+					t.Errorf("Expected masked fields to be %v but got %v and %v", c.Expected, r.Salario, r.Precos[0].Custo)
+				}
+			}
+
+			var records []record
+			if _, err := client.Get("test", nil, &records); err != nil {
+				t.Fatal(err)
+			}
+			check(records[0])
+
+			streamed, errs := client.Stream(context.Background(), "test", nil)
+			for r := range streamed {
+				var rec record
+				if err := r.ScanStruct(&rec); err != nil {
+					t.Fatal(err)
+				}
+				check(rec)
+			}
+			if err := <-errs; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyRecords is returned when a response has more records than
+// allowed by WithMaxRecords or MethodConfig.MaxRecords
+var ErrTooManyRecords = errors.New("too many records in the response")
+
+// WithMaxRecords aborts the decoding of responses with more than n records,
+// returning ErrTooManyRecords, protecting services made for small lookups
+// from decoding a whole table because of a bad filter. It applies to Get,
+// Stream, StreamXML and the helpers built on them, each page counted on its
+// own by ListAll. Use MethodConfig.MaxRecords to change it per method.
+func WithMaxRecords(n int) Option {
+	return func(m *Millennium) {
+		m.maxRecords = max(n, 0)
+	}
+}
+
+// recordLimit returns the maximum number of records of a response of
+// method, zero if unlimited
+func (m *Millennium) recordLimit(method string) int {
+	if limit := m.methodConfig(method).MaxRecords; limit != 0 {
+		return max(limit, 0)
+	}
+
+	return m.maxRecords
+}
+
+// tooManyRecords reports a response of method over limit
+func tooManyRecords(method string, limit int) error {
+	return fmt.Errorf("%w: %s returned more than %d records, check the filter", ErrTooManyRecords, method, limit)
+}
+
+// recordCounter returns a func to be called with each record of a response
+// of method, failing once there are too many
+func (m *Millennium) recordCounter(method string) func() error {
+	limit, count := m.recordLimit(method), 0
+
+	return func() error {
+		count++
+		if limit > 0 && count > limit {
+			return tooManyRecords(method, limit)
+		}
+
+		return nil
+	}
+}
+
+// checkRecords counts the records of the value array of a response of
+// method, without decoding them
+func (m *Millennium) checkRecords(method string, value json.RawMessage) error {
+	if m.recordLimit(method) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(value))
+	if token, err := dec.Token(); err != nil || token != json.Delim('[') {
+		// Not a list, left to the decoding of the response
+		return nil
+	}
+
+	count := m.recordCounter(method)
+	for dec.More() {
+		if err := count(); err != nil {
+			return err
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil
+		}
+	}
+
+	return nil
+}
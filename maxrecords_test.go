@@ -0,0 +1,84 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestMaxRecords(t *testing.T) {
+	server, _ := newCountingServer(t, 200, `{"odata.count":3,"value":[{"id":1},{"id":2},{"id":3}]}`)
+
+	cases := []struct {
+		Name        string
+		MaxRecords  int
+		MethodLimit int
+		Stream      bool
+		ExpectError bool
+	}{
+		{Name: "unlimited"},
+		{Name: "at the limit", MaxRecords: 3},
+		{Name: "over the limit", MaxRecords: 2, ExpectError: true},
+		{Name: "method limit", MethodLimit: 1, ExpectError: true},
+		{Name: "method unlimited", MaxRecords: 2, MethodLimit: -1},
+		{Name: "stream over the limit", MaxRecords: 2, Stream: true, ExpectError: true},
+		{Name: "stream at the limit", MaxRecords: 3, Stream: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithMaxRecords(c.MaxRecords))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.Configure("produtos.lista", MethodConfig{MaxRecords: c.MethodLimit})
+
+			received := 0
+			if c.Stream {
+				records, errs := client.Stream(context.Background(), "produtos.lista", url.Values{})
+				for range records {
+					received++
+				}
+				err = <-errs
+			} else {
+				var records []map[string]interface{}
+				_, err = client.Get("produtos.lista", url.Values{}, &records)
+				received = len(records)
+			}
+
+			if c.ExpectError {
+				if !errors.Is(err, ErrTooManyRecords) {
+					t.Errorf("Expected ErrTooManyRecords but got %v", err)
+				}
+				if !c.Stream && received != 0 {
+					t.Errorf("Expected nothing decoded but got %d records", received)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if received != 3 {
+				t.Errorf("Expected 3 records but got %d", received)
+			}
+		})
+	}
+}
+
+func TestMaxRecordsNoValue(t *testing.T) {
+	server, _ := newCountingServer(t, 200, `{}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithMaxRecords(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []map[string]interface{}
+	if _, err := client.Get("produtos.lista", url.Values{}, &records); err == nil {
+		t.Error("Expected error for a response without value")
+	}
+}
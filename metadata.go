@@ -0,0 +1,188 @@
+package millennium
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MetadataMethod is the Millennium method serving the OData $metadata
+// document
+const MetadataMethod = "$metadata"
+
+// Metadata is the OData $metadata document of Millennium, describing the
+// entities and methods available to the user
+type Metadata struct {
+	Entities     []MetadataType
+	ComplexTypes []MetadataType
+	Methods      []MetadataFunction
+}
+
+// MetadataType is an entity or complex type of the $metadata document
+type MetadataType struct {
+	Namespace  string
+	Name       string
+	Key        []string
+	Properties []MetadataProperty
+}
+
+// MetadataFunction is a method (function import) of the $metadata document
+type MetadataFunction struct {
+	Name       string
+	HTTPMethod string
+	ReturnType string
+	Parameters []MetadataProperty
+}
+
+// MetadataProperty is a field of a type or a parameter of a method
+type MetadataProperty struct {
+	Name string
+
+	// Type is the EDM type, like Edm.String or Collection(ns.Type)
+	Type      string
+	Nullable  bool
+	MaxLength string
+}
+
+// Entity returns the entity named name, ignoring case
+func (md *Metadata) Entity(name string) (MetadataType, bool) {
+	for _, t := range md.Entities {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+
+	return MetadataType{}, false
+}
+
+// Method returns the method named name, ignoring case
+func (md *Metadata) Method(name string) (MetadataFunction, bool) {
+	for _, f := range md.Methods {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+
+	return MetadataFunction{}, false
+}
+
+// Property returns the property named name, ignoring case
+func (t MetadataType) Property(name string) (MetadataProperty, bool) {
+	for _, p := range t.Properties {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+
+	return MetadataProperty{}, false
+}
+
+// Metadata requests and parses the $metadata document of Millennium, to
+// discover the entities, fields and methods available
+func (m *Millennium) Metadata(ctx context.Context) (*Metadata, error) {
+	res, err := m.Do(ctx, RequestMethod{HTTPMethod: GET, Method: MetadataMethod})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read body from Millennium response: %w", err)
+	}
+
+	if res.StatusCode >= 400 {
+		return nil, m.responseError(res, body)
+	}
+
+	return ParseMetadata(body)
+}
+
+// edmx is the XML layout of the $metadata document
+type edmx struct {
+	Schemas []struct {
+		Namespace    string     `xml:"Namespace,attr"`
+		EntityTypes  []edmxType `xml:"EntityType"`
+		ComplexTypes []edmxType `xml:"ComplexType"`
+		Functions    []struct {
+			Name       string         `xml:"Name,attr"`
+			HTTPMethod string         `xml:"HttpMethod,attr"`
+			ReturnType string         `xml:"ReturnType,attr"`
+			Parameters []edmxProperty `xml:"Parameter"`
+		} `xml:"EntityContainer>FunctionImport"`
+	} `xml:"DataServices>Schema"`
+}
+
+type edmxType struct {
+	Name string `xml:"Name,attr"`
+	Key  []struct {
+		Name string `xml:"Name,attr"`
+	} `xml:"Key>PropertyRef"`
+	Properties []edmxProperty `xml:"Property"`
+}
+
+type edmxProperty struct {
+	Name      string `xml:"Name,attr"`
+	Type      string `xml:"Type,attr"`
+	Nullable  string `xml:"Nullable,attr"`
+	MaxLength string `xml:"MaxLength,attr"`
+}
+
+// ParseMetadata parses an OData $metadata document, like one saved before
+func ParseMetadata(doc []byte) (*Metadata, error) {
+	var document edmx
+	if err := xml.Unmarshal(doc, &document); err != nil {
+		return nil, fmt.Errorf("invalid $metadata: %w", err)
+	}
+
+	if len(document.Schemas) == 0 {
+		return nil, fmt.Errorf("invalid $metadata: no schema found")
+	}
+
+	md := &Metadata{}
+	for _, schema := range document.Schemas {
+		for _, t := range schema.EntityTypes {
+			md.Entities = append(md.Entities, t.metadata(schema.Namespace))
+		}
+
+		for _, t := range schema.ComplexTypes {
+			md.ComplexTypes = append(md.ComplexTypes, t.metadata(schema.Namespace))
+		}
+
+		for _, f := range schema.Functions {
+			function := MetadataFunction{Name: f.Name, HTTPMethod: f.HTTPMethod, ReturnType: f.ReturnType}
+			for _, p := range f.Parameters {
+				function.Parameters = append(function.Parameters, p.metadata())
+			}
+			md.Methods = append(md.Methods, function)
+		}
+	}
+
+	return md, nil
+}
+
+func (t edmxType) metadata(namespace string) MetadataType {
+	md := MetadataType{Namespace: namespace, Name: t.Name}
+	for _, key := range t.Key {
+		md.Key = append(md.Key, key.Name)
+	}
+
+	for _, p := range t.Properties {
+		md.Properties = append(md.Properties, p.metadata())
+	}
+
+	return md
+}
+
+// metadata returns the property, nullable unless stated otherwise as
+// defined by OData
+func (p edmxProperty) metadata() MetadataProperty {
+	return MetadataProperty{
+		Name:      p.Name,
+		Type:      p.Type,
+		Nullable:  p.Nullable != "false",
+		MaxLength: p.MaxLength,
+	}
+}
@@ -0,0 +1,108 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testMetadata = `<?xml version="1.0" encoding="utf-8"?>
+<edmx:Edmx Version="1.0" xmlns:edmx="http://schemas.microsoft.com/ado/2007/06/edmx">
+  <edmx:DataServices>
+    <Schema Namespace="millenium" xmlns="http://schemas.microsoft.com/ado/2008/09/edm">
+      <EntityType Name="produtos">
+        <Key><PropertyRef Name="produto"/></Key>
+        <Property Name="produto" Type="Edm.Int32" Nullable="false"/>
+        <Property Name="descricao" Type="Edm.String" MaxLength="60"/>
+      </EntityType>
+      <ComplexType Name="endereco">
+        <Property Name="cep" Type="Edm.String"/>
+      </ComplexType>
+      <EntityContainer Name="millenium">
+        <FunctionImport Name="produtos.lista" ReturnType="Collection(millenium.produtos)" m:HttpMethod="GET" xmlns:m="http://schemas.microsoft.com/ado/2007/08/dataservices/metadata">
+          <Parameter Name="produto" Type="Edm.Int32"/>
+        </FunctionImport>
+      </EntityContainer>
+    </Schema>
+  </edmx:DataServices>
+</edmx:Edmx>`
+
+func TestMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/$metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(testMetadata))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := client.Metadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	produtos, ok := md.Entity("Produtos")
+	if !ok {
+		t.Fatal("Expected the produtos entity")
+	}
+
+	expected := MetadataType{
+		Namespace: "millenium",
+		Name:      "produtos",
+		Key:       []string{"produto"},
+		Properties: []MetadataProperty{
+			{Name: "produto", Type: "Edm.Int32"},
+			{Name: "descricao", Type: "Edm.String", Nullable: true, MaxLength: "60"},
+		},
+	}
+	if !reflect.DeepEqual(produtos, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, produtos)
+	}
+
+	if p, ok := produtos.Property("DESCRICAO"); !ok || p.MaxLength != "60" {
+		t.Errorf("Unexpected property %+v", p)
+	}
+
+	if len(md.ComplexTypes) != 1 || md.ComplexTypes[0].Name != "endereco" {
+		t.Errorf("Unexpected complex types %+v", md.ComplexTypes)
+	}
+
+	method, ok := md.Method("produtos.lista")
+	if !ok || method.ReturnType != "Collection(millenium.produtos)" || method.HTTPMethod != "GET" || len(method.Parameters) != 1 {
+		t.Errorf("Unexpected method %+v", method)
+	}
+
+	if _, ok := md.Method("produtos.exclui"); ok {
+		t.Error("Expected unknown method to be missing")
+	}
+}
+
+func TestParseMetadataInvalid(t *testing.T) {
+	for _, doc := range []string{"", "{}", "<edmx/>"} {
+		if _, err := ParseMetadata([]byte(doc)); err == nil {
+			t.Errorf("Expected error for %q", doc)
+		}
+	}
+
+	server, _ := newCountingServer(t, http.StatusUnauthorized, `{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessao invalida"}}}`)
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Metadata(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized but got %v", err)
+	}
+}
@@ -6,16 +6,18 @@ package millennium
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/Azure/go-ntlmssp"
 	"github.com/hashicorp/go-retryablehttp"
 )
 
@@ -27,8 +29,17 @@ const (
 	NTLM    AuthType = "NTLM"
 	Basic   AuthType = "BASIC"
 	Session AuthType = "SESSION"
+	Token   AuthType = "TOKEN"
+
+	// Stateless sends the credentials with every request, instead of
+	// creating a session, for very low frequency integrations
+	Stateless AuthType = "STATELESS"
 )
 
+// StatelessParam is the parameter sent as false by the Stateless requests,
+// so Millennium answers them without creating a session
+const StatelessParam = "efetuar_login"
+
 // HTTPMethod type to communicate with Millennium
 type HTTPMethod string
 
@@ -36,6 +47,8 @@ type HTTPMethod string
 const (
 	GET    HTTPMethod = "GET"
 	POST   HTTPMethod = "POST"
+	PUT    HTTPMethod = "PUT"
+	PATCH  HTTPMethod = "PATCH"
 	DELETE HTTPMethod = "DELETE"
 )
 
@@ -56,21 +69,167 @@ type Millennium struct {
 
 	Timeout time.Duration
 
-	// Headers is a map of headers to pass to requests
+	// authMu guards headers and credentials, changed by Login while
+	// requests are being built
+	authMu sync.RWMutex
+
+	// Headers is a map of headers to pass to requests.
+	// Each request gets a copy, so changes don't reach in-flight requests.
 	headers http.Header
 
 	// credentials store the user data
-	credentials struct {
-		Username string
-		Password string
-		AuthType AuthType
-		Session  string
-	}
+	credentials credentials
+
+	// mu guards closed, which is set once Close is called, and methods
+	mu     sync.Mutex
+	closed bool
+
+	// methods holds the per-method configuration set by Configure
+	methods map[string]MethodConfig
+
+	// inflight tracks the requests that still have a response body open
+	inflight sync.WaitGroup
+
+	// health tracks the recent results to report the client health
+	health healthTracker
+
+	// retryClassifier decides which failed requests are retried
+	retryClassifier RetryClassifier
+
+	// scheduler limits the concurrent requests when set by WithScheduler
+	scheduler *scheduler
+
+	// shedPriority is the highest priority shed under load, see WithLoadShedding
+	shedPriority *Priority
+
+	// maxQueued limits the requests waiting for the scheduler, see
+	// WithMaxQueuedRequests
+	maxQueued *int
+
+	// rateLimit paces the attempts when set by WithRateLimit
+	rateLimit *rateLimiter
+
+	// hedgeDelay is the delay before hedging a GET, see WithHedging
+	hedgeDelay time.Duration
+
+	// balancer distributes the GET requests, see WithBalancer
+	balancer *balancer
+
+	// maxRecords limits the records of a response, see WithMaxRecords
+	maxRecords int
+
+	// shapes records the fields of a sample of the responses, see
+	// WithShapeSampling
+	shapes *shapeSampler
+
+	// errorTranslator translates the error messages, see WithErrorTranslator
+	errorTranslator ErrorTranslator
+
+	// accounting keeps the usage and quota of each caller
+	accounting accounting
+
+	// retryHook receives the attempts of each request
+	retryHook RetryHook
+
+	// clock and backoff are used to wait between retries when set by WithClock
+	clock   Clock
+	backoff retryablehttp.Backoff
+
+	// har captures the exchanges when set by WithHARRecorder
+	har *HARRecorder
+
+	// sampleHook receives the outcome of every request
+	sampleHook SampleHook
+
+	// fieldCipher protects the encryptedFields set by WithFieldEncryption
+	fieldCipher     FieldCipher
+	encryptedFields map[string]bool
+
+	// fieldMask replaces the maskedFields set by WithFieldMask
+	fieldMask    json.RawMessage
+	maskedFields map[string]bool
+
+	// redaction hides credentials and personal data from debug output
+	redaction *RedactionPolicy
+
+	// checksumHeader carries the body checksum to be verified, if set
+	checksumHeader string
+
+	// authLayers authenticate requests on top of the Millennium authentication
+	authLayers []AuthLayer
+
+	// lazyLogin defers the session login to the first request, see WithLazyLogin
+	lazyLogin bool
+	login     sessionLoginState
+
+	// relogin renews expired sessions, see WithReloginOnUnauthorized
+	relogin bool
+
+	// keepAlive pings the session, see WithSessionKeepAlive
+	keepAlive keepAlive
+
+	// dedup rejects repeated POST requests, see WithDuplicateGuard
+	dedup *duplicateGuard
+
+	// canary traces a sample of the requests, see WithCanary
+	canary *canarySampler
+
+	// payloads keeps the payload sizes of each method
+	payloads payloadTracker
+
+	// defaultParams are sent by every request, see WithDefaultParams
+	defaultParams url.Values
+
+	// pagingCheck looks for unstable paging, see WithPagingCheck
+	pagingCheck bool
+	pagingHook  PagingHook
+
+	// maxURLLength limits the request URLs, see WithMaxURLLength
+	maxURLLength int
+
+	// gate holds the requests while the server is switched, see SwitchServer
+	gate serverGate
+
+	// shadow mirrors read requests, see WithShadow
+	shadow *shadowMirror
+
+	// hostOverride replaces the Host header and TLS server name, see WithHostOverride
+	hostOverride string
+
+	// headerTimeout and idleTimeout split the client timeout, see WithIdleTimeout
+	headerTimeout time.Duration
+	idleTimeout   time.Duration
+
+	// tenant labels the samples and logs of the client, see WithTenant
+	tenant string
+
+	// methodPolicy restricts the methods called, see WithMethodPolicy
+	methodPolicy *MethodPolicy
+
+	// slo tracks the latency objectives set by WithSLO
+	slo sloTracker
+
+	// pageSize is the page size used by ListAll, see WithPageSize
+	pageSize int
+}
+
+// credentials store the user data
+type credentials struct {
+	Username string
+	Password string
+	AuthType AuthType
+	Session  string
+	Token    string
 }
 
 // ResponseLogin type is the standard response struct from login requests
 type ResponseLogin struct {
 	Session string `json:"session"`
+
+	// ExpiresIn and ExpiresAt tell when the session expires, when sent by
+	// the server, in seconds or as a date
+	ExpiresIn int    `json:"expires_in,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
 }
 
 // ResponseGet type is the standard response struct from GET requests
@@ -88,6 +247,30 @@ type ResponseError struct {
 			Value string `json:"value"`
 		} `json:"message"`
 	} `json:"error"`
+
+	// StatusCode is the http status of the response
+	StatusCode int `json:"-"`
+
+	// URL of the request, without credentials and with the parameters of
+	// the redaction policy hidden
+	URL string `json:"-"`
+
+	// Body is the raw response body
+	Body []byte `json:"-"`
+
+	// RetryAfter is the delay asked by throttled responses, see ErrThrottled
+	RetryAfter time.Duration `json:"-"`
+
+	// Lang is the language of the message, as sent by Millennium, like pt-BR
+	Lang string `json:"-"`
+
+	// Translated is the message translated by Millennium, see
+	// TranslateHeader, or by the translator set by WithErrorTranslator.
+	// It is empty when not translated, Error keeps the original message.
+	Translated string `json:"-"`
+
+	// session tells if the request was sent with a WTS session
+	session bool
 }
 
 func (r *ResponseError) String() string {
@@ -114,7 +297,7 @@ func Client(server string, timeout time.Duration) (*Millennium, error) {
 }
 
 // NewClient returns a new Millennium instance with the server address and timeout
-func NewClient(ctx context.Context, server string, timeout time.Duration) (*Millennium, error) {
+func NewClient(ctx context.Context, server string, timeout time.Duration, opts ...Option) (*Millennium, error) {
 	if server == "" {
 		return nil, errors.New("no server address defined")
 	}
@@ -128,6 +311,7 @@ func NewClient(ctx context.Context, server string, timeout time.Duration) (*Mill
 		Context:    ctx,
 		Timeout:    timeout,
 		headers:    http.Header{},
+		redaction:  DefaultRedactionPolicy(),
 	}
 
 	if m.Context == nil {
@@ -136,12 +320,22 @@ func NewClient(ctx context.Context, server string, timeout time.Duration) (*Mill
 
 	m.Client = m.setClient()
 
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.startKeepAlive()
+
 	return m, nil
 }
 
 func (m *Millennium) setClient() *retryablehttp.Client {
 	client := retryablehttp.NewClient()
 	client.RetryMax = RetryMax
+	client.Backoff = honorRetryAfter(retryablehttp.DefaultBackoff)
+	client.CheckRetry = m.checkRetry
+	client.RequestLogHook = m.requestLogHook
+	client.HTTPClient.CheckRedirect = RedirectFollow.checkRedirect
 
 	return client
 }
@@ -150,32 +344,64 @@ func (m *Millennium) setClient() *retryablehttp.Client {
 // server should be a valid URL with Millennium port, like: https://127.0.0.1:6018
 func (m *Millennium) Login(username string, password string, authType AuthType) error {
 	// Set Username and Password in credentials
-	m.credentials.Username = username
-	m.credentials.Password = password
+	m.updateCredentials(func(c *credentials) {
+		c.Username = username
+		c.Password = password
+	})
+
+	// Stateless requests never carry a session, even one from a previous login
+	if authType == Stateless {
+		m.updateCredentials(func(c *credentials) { c.Session = "" })
+		m.delHeader("WTS-Session")
+	}
 
-	// If AuthType equals NTLM then set client transport to ntlm negotiator
-	if authType == NTLM {
-		m.Client.HTTPClient.Transport = ntlmssp.Negotiator{
-			RoundTripper: &http.Transport{},
+	if authType == Session {
+		// With lazy login the session is only requested by the first request
+		if m.lazyLogin {
+			m.updateCredentials(func(c *credentials) { c.AuthType = authType })
+			m.setSessionPending()
+			return nil
 		}
-	} else if authType == Session {
-		var responseLogin ResponseLogin
-		m.headers.Set("WTS-Authorization", fmt.Sprintf("%s/%s", strings.ToUpper(m.credentials.Username), strings.ToUpper(m.credentials.Password)))
-		if err := m.Post("login", []byte{}, &responseLogin); err != nil {
+
+		if err := m.sessionLogin(); err != nil {
 			return err
 		}
+	}
 
-		m.headers.Del("WTS-Authorization")
+	m.updateCredentials(func(c *credentials) { c.AuthType = authType })
 
-		m.credentials.Session = responseLogin.Session
-		m.headers.Set("WTS-Session", m.credentials.Session)
+	return nil
+}
+
+// LoginWithDomain logs in to Millennium with NTLM using a Windows domain
+// account, so the negotiation carries domain instead of relying on the
+// server default. Login also accepts NTLM usernames as DOMAIN\user.
+func (m *Millennium) LoginWithDomain(domain string, username string, password string) error {
+	if domain == "" {
+		return errors.New("domain could not be empty")
+	}
+
+	return m.Login(domain+`\`+username, password, NTLM)
+}
+
+// LoginWithToken authenticates every request with a long-lived integration
+// token, sent in the WTS-Authorization header, without requesting a session
+func (m *Millennium) LoginWithToken(token string) error {
+	if token == "" {
+		return errors.New("token could not be empty")
 	}
 
-	m.credentials.AuthType = authType
+	m.updateCredentials(func(c *credentials) {
+		*c = credentials{AuthType: Token, Token: token}
+	})
+	m.delHeader("WTS-Session")
 
 	return nil
 }
 
+// BodyFunc returns a new reader of a request body, called before every attempt
+type BodyFunc func() (io.Reader, error)
+
 // RequestMethod receive data to pass to Request function
 type RequestMethod struct {
 	HTTPMethod HTTPMethod
@@ -183,16 +409,124 @@ type RequestMethod struct {
 	Params     url.Values
 	Body       []byte
 	Response   interface{}
+
+	// BodyFunc generates the body of each attempt, replacing Body, for
+	// bodies read from non-seekable sources. Generated bodies are not
+	// validated against the method Schema nor have fields decrypted.
+	BodyFunc BodyFunc
+
+	// Priority of the request, overriding the one carried by the context
+	Priority Priority
+
+	// IdempotencyKey identifies the POST for WithDuplicateGuard and
+	// WithIdempotencyStore, replacing the hash of its method, parameters
+	// and body. Use the id of the input record, like the order number, so
+	// replays after a restart are rejected even if the body changed.
+	IdempotencyKey string
+
+	// Meta receives information about the response, when set
+	Meta *ResponseMeta
+
+	// Context of the request, the client context is used when nil
+	Context context.Context
+}
+
+// requestContext returns the context of r, falling back to the client context
+func (m *Millennium) requestContext(r RequestMethod) context.Context {
+	if r.Context != nil {
+		return r.Context
+	}
+
+	return m.Context
 }
 
 // Request a method from Millennium
 func (m *Millennium) Request(r RequestMethod) (err error) {
+	// Ensure Response defined if http methods are GET, POST, PUT or PATCH
+	if r.Response == nil && (r.HTTPMethod == GET || r.HTTPMethod == POST || r.HTTPMethod == PUT || r.HTTPMethod == PATCH) {
+		return errors.New("response should have something to point to")
+	}
+
+	if err := m.checkMethod(r.Method); err != nil {
+		return err
+	}
+
+	if m.dedup != nil && m.dedup.checks(r) {
+		// Encrypted fields change on every encryption, the plaintext is hashed
+		plain := r
+		if plain.Body, err = m.decryptFields(r.Body); err != nil {
+			return err
+		}
+
+		done, claimErr := m.dedup.claim(m.requestContext(r), plain)
+		if claimErr != nil {
+			return claimErr
+		}
+		defer func() { done(err) }()
+	}
+
+	// Mirrored requests need the checksum of the primary response
+	var mirrored *RequestMethod
+	if m.shadow.sampled(r) {
+		clone := r.Clone()
+		mirrored = &clone
+
+		if r.Meta == nil {
+			r.Meta = &ResponseMeta{}
+		}
+	}
+
+	ctx := m.requestContext(r)
+	sent := false
+	err = m.onServer(ctx, func() error {
+		if err := m.ensureSession(); err != nil {
+			return err
+		}
+
+		req, err := m.newRequest(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		sent = true
+		return m.sendRequest(r.Method, req, &r.Response, r.Meta)
+	})
+	if err != nil && sent && r.HTTPMethod == DELETE {
+		return m.verifyDelete(r, err)
+	}
+
+	// Mirrored requests are in flight too, so Close waits for them
+	if err == nil && mirrored != nil && m.begin(context.Background()) == nil {
+		go func(meta ResponseMeta) {
+			defer m.inflight.Done()
+			m.shadow.mirror(*mirrored, meta)
+		}(*r.Meta)
+	}
+
+	return err
+}
+
+// newRequest builds the http request to Millennium with the default parameters,
+// headers and authentication
+func (m *Millennium) newRequest(ctx context.Context, r RequestMethod) (*retryablehttp.Request, error) {
+	// Sensitive fields are kept encrypted until the body is sent
+	body, err := m.decryptFields(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	// Transform body of type []byte to io.Reader
-	bodyReader := bytes.NewReader(r.Body)
+	bodyReader := bytes.NewReader(body)
 
 	// Ensure that the Millennium method is defined before request
 	if r.Method == "" {
-		return errors.New("requested method could not be empty")
+		return nil, errors.New("requested method could not be empty")
+	}
+
+	if r.BodyFunc == nil && (r.HTTPMethod == POST || r.HTTPMethod == PUT || r.HTTPMethod == PATCH) {
+		if err := m.validateBody(r.Method, body, r.HTTPMethod == PATCH); err != nil {
+			return nil, err
+		}
 	}
 
 	// Ensure Params set if it is empty (nil)
@@ -200,76 +534,368 @@ func (m *Millennium) Request(r RequestMethod) (err error) {
 		r.Params = url.Values{}
 	}
 
-	// Ensure Response defined if http methods are GET or POST
-	if r.Response == nil && (r.HTTPMethod == http.MethodPost || r.HTTPMethod == http.MethodGet) {
-		return errors.New("response should have something to point to")
+	// Default parameters of the client fill the ones not given
+	if len(m.defaultParams) > 0 {
+		r.Params = cloneParams(r.Params)
+		for key, values := range m.defaultParams {
+			if _, ok := r.Params[key]; !ok {
+				r.Params[key] = append([]string(nil), values...)
+			}
+		}
+	}
+
+	// Stateless requests ask Millennium not to create a session
+	if m.getCredentials().AuthType == Stateless {
+		r.Params = cloneParams(r.Params)
+		r.Params.Set(StatelessParam, "false")
 	}
 
-	// Add default parameters for Millennium request
-	r.Params.Add("$format", "json")
+	// Add default parameters for Millennium request, JSON unless another
+	// format was asked, like by StreamXML
+	if r.Params.Get("$format") == "" {
+		r.Params.Set("$format", "json")
+	}
 	r.Params.Add("$dateformat", "iso")
 
 	// Start a new request
 	requestMethod := string(r.HTTPMethod)
-	requestURL := fmt.Sprintf("%s/api/%s?%s", m.ServerAddr, r.Method, r.Params.Encode())
-	requestBody := bodyReader
+	requestURL := fmt.Sprintf("%s/api/%s?%s", m.serverFor(r.HTTPMethod), r.Method, r.Params.Encode())
+	if err := m.checkURLLength(r.Method, requestURL); err != nil {
+		return nil, err
+	}
+
+	var requestBody interface{} = bodyReader
+
+	// Bodies from BodyFunc are generated again for each attempt
+	if r.BodyFunc != nil {
+		requestBody = retryablehttp.ReaderFunc(r.BodyFunc)
+	}
 
-	req, err := retryablehttp.NewRequestWithContext(m.Context, requestMethod, requestURL, requestBody)
+	if r.Priority != PriorityNormal {
+		ctx = WithPriority(ctx, r.Priority)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, requestMethod, requestURL, requestBody)
 
 	if err != nil {
-		return fmt.Errorf("unable to start new request to Millennium: %w", err)
+		return nil, fmt.Errorf("unable to start new request to Millennium: %w", err)
 	}
 
-	if m.headers != nil {
-		req.Header = m.headers
+	req.Header = m.headerSnapshot()
+
+	if m.hostOverride != "" {
+		req.Host = m.hostOverride
 	}
 
 	// If authType is NTLM or Basic, set basic auth on request
-	if m.credentials.AuthType == NTLM || m.credentials.AuthType == Basic {
-		req.SetBasicAuth(m.credentials.Username, m.credentials.Password)
+	creds := m.getCredentials()
+	if creds.AuthType == NTLM || creds.AuthType == Basic {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	// Integration tokens are sent by every request
+	if creds.AuthType == Token {
+		req.Header.Set("WTS-Authorization", creds.Token)
+	}
+
+	// Stateless requests carry the same credentials as the login request
+	if creds.AuthType == Stateless {
+		req.Header.Set("WTS-Authorization", wtsAuthorization(creds))
+	}
+
+	if err := m.applyAuthLayers(req.Request); err != nil {
+		return nil, err
 	}
 
-	return m.sendRequest(req, &r.Response)
+	return req, nil
 }
 
-func (m *Millennium) sendRequest(request *retryablehttp.Request, response interface{}) error {
-	// Request using the client
-	ctx, cancel := context.WithTimeout(request.Context(), m.Timeout)
+func (m *Millennium) sendRequest(method string, request *retryablehttp.Request, response interface{}, meta *ResponseMeta) error {
+	if meta != nil {
+		started := time.Now()
+		attempts := 0
+		request = request.WithContext(withAttemptCount(request.Context(), &attempts))
+
+		defer func() {
+			meta.Duration = time.Since(started)
+			meta.Retries = max(attempts-1, 0)
+		}()
+	}
+
+	relogged := false
+	for attempt := 0; ; attempt++ {
+		res, err := m.do(method, request)
+		if err != nil {
+			return err
+		}
+
+		// Expired sessions are renewed and the request sent again, only once
+		if !relogged && m.shouldRelogin(method, res) {
+			relogged = true
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+
+			if err := m.renewSession(request.Header.Get("WTS-Session")); err != nil {
+				return err
+			}
+
+			request.Header.Set("WTS-Session", m.getCredentials().Session)
+			attempt--
+			continue
+		}
+
+		// Truncated bodies are only detected once read, so they are retried here
+		err = m.getResponse(method, res, &response, meta)
+		if !errors.Is(err, ErrTruncatedResponse) || attempt >= m.Client.RetryMax || !m.methodConfig(method).retryable(request.Method) {
+			return err
+		}
+	}
+}
+
+// do sends the request using the client, limited by the client timeout or
+// the one configured for the method.
+// The timeout is released only when the response body is closed.
+func (m *Millennium) do(method string, request *retryablehttp.Request) (*http.Response, error) {
+	// Requests rejected before being sent are never applied by Millennium
+	if err := m.begin(request.Context()); err != nil {
+		return nil, &notSentError{err}
+	}
+
+	caller := callerFrom(request.Context())
+	if err := m.accounting.admit(caller, request.ContentLength); err != nil {
+		m.inflight.Done()
+		return nil, &notSentError{err}
+	}
+
+	// The rate limit is waited before taking a slot, not while holding it
+	paced, err := m.admitRate(request.Context())
+	if err != nil {
+		m.inflight.Done()
+		return nil, &notSentError{fmt.Errorf("unable to schedule request: %w", err)}
+	}
+	request = request.WithContext(paced)
+
+	if m.scheduler != nil {
+		if err := m.schedule(request.Context()); err != nil {
+			m.inflight.Done()
+			return nil, &notSentError{fmt.Errorf("unable to schedule request: %w", err)}
+		}
+	}
+
+	config := m.methodConfig(method)
+	if !config.retryable(request.Method) {
+		config.RetryMax = -1
+	}
+
+	parent := request.Context()
+	deadlines := m.newDeadlines(parent, config)
+	ctx, attempts := withAttemptRecorder(deadlines.ctx)
+
+	var canary *canaryTrace
+	if m.canary.sampled() {
+		canary = m.canary.start(method)
+		ctx = httptrace.WithClientTrace(ctx, canary.clientTrace())
+	}
 	request = request.WithContext(ctx)
-	defer cancel()
 
-	res, err := m.Client.Do(request)
+	started := time.Now()
+	balanced := m.balancer.begin(request.URL.String())
+	res, err := m.send(m.clientFor(config), request)
+	deadlines.received()
+	if count := attemptCountFrom(parent); count != nil {
+		n, _ := attempts.last()
+		*count += n
+	}
+	if err != nil {
+		err = deadlines.wrap(err)
+	}
+	err = m.reportAttempts(method, attempts, err)
+
+	if m.har != nil {
+		res = m.har.capture(m.redaction, started, request, res, err)
+	}
+
+	if canary != nil {
+		res = canary.finish(m.canary.hook, m.redaction, request, res, err)
+	}
+
+	// Requests canceled by the caller say nothing about Millennium health
+	if parent.Err() == nil {
+		m.health.recordResponse(res, err)
+		m.sample(request.Context(), method, started, res, err)
+		m.slo.record(method, time.Since(started))
+	}
+
 	if err != nil {
-		return fmt.Errorf("unable to send request: %w", err)
+		balanced()
+		deadlines.release()
+		m.end()
+		return nil, fmt.Errorf("unable to send request: %w", err)
+	}
+
+	res.Body = deadlines.body(res.Body)
+	var received int64
+	res.Body = &countingBody{ReadCloser: res.Body, count: func(n int64) {
+		atomic.AddInt64(&received, n)
+		m.accounting.received(caller, n)
+	}}
+
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: func() {
+		m.payloads.record(method, request.ContentLength, atomic.LoadInt64(&received))
+		balanced()
+		deadlines.release()
+		m.end()
+	}}
+	return res, nil
+}
+
+// headerSnapshot returns a copy of the client headers for a new request
+func (m *Millennium) headerSnapshot() http.Header {
+	m.authMu.RLock()
+	defer m.authMu.RUnlock()
+
+	if m.headers == nil {
+		return http.Header{}
 	}
 
-	return m.getResponse(res, &response)
+	return m.headers.Clone()
+}
+
+// setHeader sets a header sent by every new request
+func (m *Millennium) setHeader(key string, value string) {
+	m.authMu.Lock()
+	defer m.authMu.Unlock()
+
+	if m.headers == nil {
+		m.headers = http.Header{}
+	}
+
+	m.headers.Set(key, value)
+}
+
+// delHeader removes a header sent by every new request
+func (m *Millennium) delHeader(key string) {
+	m.authMu.Lock()
+	defer m.authMu.Unlock()
+
+	m.headers.Del(key)
+}
+
+// getCredentials returns a copy of the client credentials
+func (m *Millennium) getCredentials() credentials {
+	m.authMu.RLock()
+	defer m.authMu.RUnlock()
+
+	return m.credentials
+}
+
+// updateCredentials changes the client credentials with fn
+func (m *Millennium) updateCredentials(fn func(c *credentials)) {
+	m.authMu.Lock()
+	defer m.authMu.Unlock()
+
+	fn(&m.credentials)
+}
+
+// end releases the resources held by a request once it is done
+func (m *Millennium) end() {
+	if m.scheduler != nil {
+		m.scheduler.release()
+	}
+
+	m.inflight.Done()
+}
+
+// cancelBody releases the request context once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.cancel)
+	return err
 }
 
 // Will handle the response from Millennium for GET requests
-func (m *Millennium) getResponse(res *http.Response, output interface{}) error {
+func (m *Millennium) getResponse(method string, res *http.Response, output interface{}, meta *ResponseMeta) error {
+	defer res.Body.Close()
+
 	// Convert the response body to []byte
-	bodyRes, err := io.ReadAll(res.Body)
+	bodyRes, err := readBody(res)
 	if err != nil {
-		return fmt.Errorf("unable to read body from Millennium response: %w", err)
+		return err
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode >= 400 {
-		var resErr ResponseError
-		if err = json.Unmarshal(bodyRes, &resErr); err != nil {
-			return fmt.Errorf("got error %d but unable to unmarshal error response: %w", res.StatusCode, err)
+	if meta != nil || m.checksumHeader != "" {
+		sum := checksum(bodyRes)
+		if meta != nil {
+			meta.StatusCode = res.StatusCode
+			meta.Header = res.Header
+			meta.Checksum = hex.EncodeToString(sum)
+		}
+
+		if err := m.verifyChecksum(res, sum); err != nil {
+			return err
 		}
+	}
+
+	if res.StatusCode >= 400 {
+		return m.responseError(res, bodyRes)
+	}
+
+	// Protect sensitive fields before they reach the application
+	if bodyRes, err = m.maskFields(bodyRes); err != nil {
+		return err
+	}
+
+	if bodyRes, err = m.encryptFields(bodyRes); err != nil {
+		return err
+	}
 
-		return &resErr
+	if m.shapes.sampled() {
+		m.shapes.recorder.recordBody(method, bodyRes)
 	}
 
 	// Unmarshal the response JSON to interface pointer
-	return json.Unmarshal(bodyRes, &output)
+	return decodeJSON(bodyRes, &output)
+}
+
+// responseError decodes the error returned by Millennium
+func (m *Millennium) responseError(res *http.Response, body []byte) error {
+	var resErr ResponseError
+	if err := json.Unmarshal(body, &resErr); err != nil {
+		resErr = ResponseError{}
+		resErr.SetMessage(fmt.Sprintf("got error %d but unable to unmarshal error response: %v", res.StatusCode, err))
+	}
+
+	resErr.StatusCode = res.StatusCode
+	resErr.Body = body
+	resErr.Lang = resErr.Err.Message.Lang
+	m.translateError(&resErr, res)
+	resErr.RetryAfter, _ = throttledFor(res)
+	if res.Request != nil {
+		resErr.URL = m.redaction.redactURL(res.Request.URL).Redacted()
+		resErr.session = res.Request.Header.Get("WTS-Session") != ""
+	}
+
+	return &resErr
 }
 
 // Get requests a method using GET http method
 func (m *Millennium) Get(method string, params url.Values, response interface{}) (int, error) {
+	return m.GetCtx(m.Context, method, params, response)
+}
+
+// GetCtx requests a method using GET http method, bound to ctx instead of
+// the client context
+func (m *Millennium) GetCtx(ctx context.Context, method string, params url.Values, response interface{}) (int, error) {
+	return m.get(ctx, method, params, response, nil)
+}
+
+func (m *Millennium) get(ctx context.Context, method string, params url.Values, response interface{}, meta *ResponseMeta) (int, error) {
 	var res ResponseGet
 
 	// Send a GET request to Millennium server
@@ -278,12 +904,24 @@ func (m *Millennium) Get(method string, params url.Values, response interface{})
 		Method:     method,
 		Params:     params,
 		Response:   &res,
+		Meta:       meta,
+		Context:    ctx,
 	})
 
 	if err != nil {
 		return 0, fmt.Errorf("unable to make the request to Millennium: %w", err)
 	}
 
+	// Responses without records, like an empty object, can't be decoded
+	if res.Value == nil {
+		return 0, errors.New("unable to unmarshal JSON: response has no value")
+	}
+
+	// Responses over the limit are rejected before being decoded
+	if err := m.checkRecords(method, *res.Value); err != nil {
+		return 0, err
+	}
+
 	// Unmarshal response values to response parameter
 	if err := json.Unmarshal(*res.Value, response); err != nil {
 		return 0, fmt.Errorf("unable to unmarshal JSON: %w", err)
@@ -295,20 +933,72 @@ func (m *Millennium) Get(method string, params url.Values, response interface{})
 
 // Post requests a method using POST http method
 func (m *Millennium) Post(method string, body []byte, response interface{}) error {
+	return m.PostCtx(m.Context, method, body, response)
+}
+
+// PostCtx requests a method using POST http method, bound to ctx instead of
+// the client context
+func (m *Millennium) PostCtx(ctx context.Context, method string, body []byte, response interface{}) error {
 	return m.Request(RequestMethod{
 		HTTPMethod: POST,
 		Method:     method,
 		Params:     url.Values{},
 		Body:       body,
 		Response:   &response,
+		Context:    ctx,
+	})
+}
+
+// Put requests a method using PUT http method, used by the methods that
+// update records
+func (m *Millennium) Put(method string, body []byte, response interface{}) error {
+	return m.PutCtx(m.Context, method, body, response)
+}
+
+// PutCtx requests a method using PUT http method, bound to ctx instead of
+// the client context
+func (m *Millennium) PutCtx(ctx context.Context, method string, body []byte, response interface{}) error {
+	return m.Request(RequestMethod{
+		HTTPMethod: PUT,
+		Method:     method,
+		Params:     url.Values{},
+		Body:       body,
+		Response:   &response,
+		Context:    ctx,
+	})
+}
+
+// Patch requests a method using PATCH http method, sending a partial body
+// to update only some fields of a record. params may be nil.
+func (m *Millennium) Patch(method string, params url.Values, body []byte, response interface{}) error {
+	return m.PatchCtx(m.Context, method, params, body, response)
+}
+
+// PatchCtx requests a method using PATCH http method, bound to ctx instead
+// of the client context
+func (m *Millennium) PatchCtx(ctx context.Context, method string, params url.Values, body []byte, response interface{}) error {
+	return m.Request(RequestMethod{
+		HTTPMethod: PATCH,
+		Method:     method,
+		Params:     params,
+		Body:       body,
+		Response:   &response,
+		Context:    ctx,
 	})
 }
 
 // Delete requests a method using DELETE http method
 func (m *Millennium) Delete(method string, params url.Values) error {
+	return m.DeleteCtx(m.Context, method, params)
+}
+
+// DeleteCtx requests a method using DELETE http method, bound to ctx
+// instead of the client context
+func (m *Millennium) DeleteCtx(ctx context.Context, method string, params url.Values) error {
 	return m.Request(RequestMethod{
 		HTTPMethod: DELETE,
 		Method:     method,
 		Params:     params,
+		Context:    ctx,
 	})
 }
@@ -1,13 +1,18 @@
 package millennium
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -136,6 +141,50 @@ func (s *mockHTTPServer) Start() *httptest.Server {
 			Body:       s.jsonError("Internal Server Error", http.StatusInternalServerError),
 		})
 	})
+	mux.HandleFunc("/api/test.success.PUT", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			s.writeOutput(&writeOutputParams{
+				Writer:     w,
+				Request:    r,
+				StatusCode: http.StatusMethodNotAllowed,
+				Body:       s.jsonError("Method Not Allowed", http.StatusMethodNotAllowed),
+			})
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		s.writeOutput(&writeOutputParams{
+			Writer:  w,
+			Request: r,
+			Body:    body,
+		})
+	})
+	mux.HandleFunc("/api/test.success.PATCH", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Query().Get("produto") != "A" {
+			s.writeOutput(&writeOutputParams{
+				Writer:     w,
+				Request:    r,
+				StatusCode: http.StatusBadRequest,
+				Body:       s.jsonError("Bad Request", http.StatusBadRequest),
+			})
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		s.writeOutput(&writeOutputParams{
+			Writer:  w,
+			Request: r,
+			Body:    body,
+		})
+	})
+	mux.HandleFunc("/api/test.error.PUT", func(w http.ResponseWriter, r *http.Request) {
+		s.writeOutput(&writeOutputParams{
+			Writer:     w,
+			Request:    r,
+			StatusCode: http.StatusBadRequest,
+			Body:       s.jsonError("Bad Request", http.StatusBadRequest),
+		})
+	})
 	mux.HandleFunc("/api/test.success.DELETE", func(w http.ResponseWriter, r *http.Request) {
 		s.writeOutput(&writeOutputParams{
 			Writer:  w,
@@ -151,6 +200,45 @@ func (s *mockHTTPServer) Start() *httptest.Server {
 			Body:       s.jsonError("Query error", http.StatusInternalServerError),
 		})
 	})
+	mux.HandleFunc("/api/test.stream", func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		body.WriteString(`{"odata.count":200,"value":[`)
+		for i := 0; i < 200; i++ {
+			if i > 0 {
+				body.WriteByte(',')
+			}
+			fmt.Fprintf(&body, `{"number":%d,"string":"test","bool":true}`, i)
+		}
+		body.WriteString(`]}`)
+
+		s.writeOutput(&writeOutputParams{
+			Writer:  w,
+			Request: r,
+			Body:    body.Bytes(),
+		})
+	})
+	mux.HandleFunc("/api/test.export", func(w http.ResponseWriter, r *http.Request) {
+		s.writeOutput(&writeOutputParams{
+			Writer:  w,
+			Request: r,
+			Body: []byte(`{"odata.count":2,"value":[` +
+				`{"codigo":"001","preco":12.5,"ativo":true,"data":"2024-01-02T12:00:00","obs":null},` +
+				`{"codigo":"<002>","preco":3,"ativo":false,"obs":"x","itens":[1, 2]}]}`),
+		})
+	})
+	mux.HandleFunc("/api/test.params", func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		for key := range r.URL.Query() {
+			params[key] = r.URL.Query().Get(key)
+		}
+
+		value, _ := json.Marshal([]map[string]string{params})
+		s.writeOutput(&writeOutputParams{
+			Writer:  w,
+			Request: r,
+			Body:    []byte(fmt.Sprintf(`{"odata.count":1,"value":%s}`, value)),
+		})
+	})
 	mux.HandleFunc("/api/test.basicauth", func(w http.ResponseWriter, r *http.Request) {
 		username, password, ok := r.BasicAuth()
 		if !ok || username != "correct_user" || password != "correct_password" {
@@ -295,6 +383,102 @@ func TestLogin(t *testing.T) {
 	}
 }
 
+func TestLoginWithDomain(t *testing.T) {
+	client := NewTestClient(t)
+
+	if err := client.LoginWithDomain("", "test", "test"); err == nil {
+		t.Error("Expected error for an empty domain")
+	}
+
+	if err := client.LoginWithDomain("CORP", "test", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := client.newRequest(context.Background(), RequestMethod{HTTPMethod: GET, Method: "test.success.GET"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The NTLM negotiator reads the domain from the basic auth username
+	if username, _, _ := req.BasicAuth(); username != `CORP\test` {
+		t.Errorf("Expected username %q but got %q", `CORP\test`, username)
+	}
+}
+
+func TestStateless(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/login" && r.URL.Query().Get("efetuar_login") == "" {
+			_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+			return
+		}
+
+		if r.URL.Path == "/api/login" || r.Header.Get("WTS-Authorization") != "TEST/TEST" || r.Header.Get("WTS-Session") != "" || r.URL.Query().Get("efetuar_login") != "false" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"PERMISSÃO NEGADA"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A session from a previous login is not sent by stateless requests
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Stateless); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.headerSnapshot().Get("WTS-Session") != "" || client.getCredentials().Session != "" {
+		t.Error("Expected the session to be dropped by the stateless login")
+	}
+
+	for i := 0; i < 2; i++ {
+		var r interface{}
+		if _, err := client.Get("test", url.Values{}, &r); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLoginWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/login" || r.Header.Get("WTS-Authorization") != "integration-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"PERMISSÃO NEGADA"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.LoginWithToken(""); err == nil {
+		t.Error("Expected error for an empty token")
+	}
+
+	if err := client.LoginWithToken("integration-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestNTLM(t *testing.T) {
 	client := NewTestClient(t)
 	err := client.Login("test", "test", NTLM)
@@ -535,6 +719,85 @@ func TestPost(t *testing.T) {
 	}
 }
 
+func TestPut(t *testing.T) {
+	client := NewTestClient(t)
+
+	type ResponseTestPUT struct {
+		Produto string `json:"produto"`
+		Preco   int    `json:"preco"`
+	}
+
+	cases := []struct {
+		Method      string
+		Body        []byte
+		ExpectError bool
+	}{
+		{
+			Method:      "test.success.PUT",
+			Body:        []byte(`{"produto":"A","preco":10}`),
+			ExpectError: false,
+		},
+		{
+			Method:      "test.error.PUT",
+			Body:        []byte(`{"produto":"A","preco":10}`),
+			ExpectError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Method, func(t *testing.T) {
+			var res ResponseTestPUT
+			err := client.Put(c.Method, c.Body, &res)
+			if (err == nil) == c.ExpectError {
+				t.Error(err)
+			}
+
+			if !c.ExpectError && (res.Produto != "A" || res.Preco != 10) {
+				t.Errorf("Unexpected response %+v", res)
+			}
+		})
+	}
+
+	if err := client.Request(RequestMethod{HTTPMethod: PUT, Method: "test.success.PUT"}); err == nil {
+		t.Error("Expected error without response")
+	}
+}
+
+func TestPatch(t *testing.T) {
+	client := NewTestClient(t)
+
+	var res struct {
+		Preco int `json:"preco"`
+	}
+
+	if err := client.Patch("test.success.PATCH", url.Values{"produto": {"A"}}, []byte(`{"preco":12}`), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Preco != 12 {
+		t.Errorf("Unexpected response %+v", res)
+	}
+
+	if err := client.Patch("test.success.PATCH", nil, []byte(`{"preco":12}`), &res); err == nil {
+		t.Error("Expected error without params")
+	}
+
+	// Partial bodies skip the required fields of the schema
+	client.Configure("test.success.PATCH", MethodConfig{Schema: &Schema{Fields: []SchemaField{
+		{Name: "produto", Type: FieldString, Required: true},
+		{Name: "preco", Type: FieldNumber},
+	}}})
+
+	if err := client.Patch("test.success.PATCH", url.Values{"produto": {"A"}}, []byte(`{"preco":12}`), &res); err != nil {
+		t.Error(err)
+	}
+
+	var schemaErr *SchemaError
+	if err := client.Patch("test.success.PATCH", url.Values{"produto": {"A"}}, []byte(`{"preco":"12"}`), &res); !errors.As(err, &schemaErr) {
+		t.Errorf("Expected SchemaError but got %v", err)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	client := NewTestClient(t)
 
@@ -567,3 +830,88 @@ func TestDelete(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestBodyFunc(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"pedido":1}` {
+			t.Errorf("Unexpected body %q", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":{"code":500,"message":{"lang":"pt-BR","value":"Erro"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	calls := 0
+	var r interface{}
+	err = client.Request(RequestMethod{
+		HTTPMethod: POST,
+		Method:     "test.stream",
+		BodyFunc: func() (io.Reader, error) {
+			calls++
+			return strings.NewReader(`{"pedido":1}`), nil
+		},
+		Response: &r,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Expected 2 attempts but got %d", attempts)
+	}
+
+	if calls < 2 {
+		t.Errorf("Expected the body to be generated for each attempt but got %d calls", calls)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	client := NewTestClient(t)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var r interface{}
+	if _, err := client.GetCtx(canceled, "test.success.GET", url.Values{}, &r); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetCtx but got %v", err)
+	}
+
+	if err := client.PostCtx(canceled, "test.success.POST", []byte(`{}`), &r); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from PostCtx but got %v", err)
+	}
+
+	if err := client.DeleteCtx(canceled, "test.success.DELETE", url.Values{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from DeleteCtx but got %v", err)
+	}
+
+	err := client.Request(RequestMethod{
+		HTTPMethod: GET,
+		Method:     "test.success.GET",
+		Response:   &r,
+		Context:    canceled,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from Request but got %v", err)
+	}
+
+	// The client context is still used by calls without their own
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package millenniumtest provides utilities to test integrations built
+// with the Millennium client.
+package millenniumtest
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Chaos sets how often each failure is injected by ChaosTransport.
+// Rates go from 0 (never) to 1 (every request).
+type Chaos struct {
+	// Latency is added before the request is sent, at LatencyRate
+	Latency     time.Duration
+	LatencyRate float64
+
+	// TimeoutRate of requests that hang until their context is done
+	TimeoutRate float64
+
+	// ServerErrorRate of requests answered with a Millennium 500 error
+	ServerErrorRate float64
+
+	// MalformedJSONRate of responses that have their body truncated
+	MalformedJSONRate float64
+
+	// ResetRate of requests that fail with a connection reset
+	ResetRate float64
+
+	// Seed of the random generator, to make the failures reproducible
+	Seed int64
+}
+
+// ChaosTransport is an http.RoundTripper injecting failures into the requests
+// sent to Millennium, so integrations can verify their resilience logic.
+// It should be set as the transport of the client HTTPClient.
+type ChaosTransport struct {
+	// Transport used to send the requests, http.DefaultTransport if nil
+	Transport http.RoundTripper
+
+	Chaos Chaos
+
+	once sync.Once
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewChaosTransport returns a ChaosTransport wrapping transport
+func NewChaosTransport(transport http.RoundTripper, chaos Chaos) *ChaosTransport {
+	return &ChaosTransport{Transport: transport, Chaos: chaos}
+}
+
+// hit reports if a failure with the given rate should be injected
+func (t *ChaosTransport) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	t.once.Do(func() {
+		t.rand = rand.New(rand.NewSource(t.Chaos.Seed))
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rand.Float64() < rate
+}
+
+// RoundTrip sends the request, injecting the configured failures
+func (t *ChaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hit(t.Chaos.LatencyRate) {
+		select {
+		case <-time.After(t.Chaos.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if t.hit(t.Chaos.TimeoutRate) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+
+	if t.hit(t.Chaos.ResetRate) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
+
+	if t.hit(t.Chaos.ServerErrorRate) {
+		return &http.Response{
+			Status:     "500 Internal Server Error",
+			StatusCode: http.StatusInternalServerError,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{"error":{"code":500,"message":{"lang":"pt-BR","value":"Chaos injected error"}}}`))),
+			Request:    req,
+		}, nil
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.hit(t.Chaos.MalformedJSONRate) {
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		body = body[:len(body)/2]
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		res.ContentLength = int64(len(body))
+		res.Header.Del("Content-Length")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,113 @@
+package millenniumtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestChaosTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		Name  string
+		Chaos Chaos
+		Check func(t *testing.T, res *http.Response, err error)
+	}{
+		{
+			Name:  "no chaos",
+			Chaos: Chaos{},
+			Check: func(t *testing.T, res *http.Response, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				var v interface{}
+				if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			Name:  "latency",
+			Chaos: Chaos{Latency: 20 * time.Millisecond, LatencyRate: 1},
+			Check: func(t *testing.T, res *http.Response, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			Name:  "timeout",
+			Chaos: Chaos{TimeoutRate: 1},
+			Check: func(t *testing.T, res *http.Response, err error) {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Expected deadline exceeded but got %v", err)
+				}
+			},
+		},
+		{
+			Name:  "reset",
+			Chaos: Chaos{ResetRate: 1},
+			Check: func(t *testing.T, res *http.Response, err error) {
+				if !errors.Is(err, syscall.ECONNRESET) {
+					t.Errorf("Expected connection reset but got %v", err)
+				}
+			},
+		},
+		{
+			Name:  "server error",
+			Chaos: Chaos{ServerErrorRate: 1},
+			Check: func(t *testing.T, res *http.Response, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.StatusCode != http.StatusInternalServerError {
+					t.Errorf("Expected status 500 but got %d", res.StatusCode)
+				}
+			},
+		},
+		{
+			Name:  "malformed json",
+			Chaos: Chaos{MalformedJSONRate: 1},
+			Check: func(t *testing.T, res *http.Response, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(res.Body)
+				if json.Valid(body) {
+					t.Errorf("Expected malformed body but got %s", body)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{Transport: NewChaosTransport(nil, c.Chaos)}
+			res, err := client.Do(req)
+			if res != nil {
+				defer res.Body.Close()
+			}
+
+			c.Check(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package millenniumtest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fabiomatavelli/millennium-go"
+	"github.com/fabiomatavelli/millennium-go/millenniumtest"
+)
+
+var _ millennium.Clock = (*millenniumtest.FakeClock)(nil)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := millenniumtest.NewFakeClock(start)
+
+	ch := clock.After(time.Minute)
+	clock.Advance(30 * time.Second)
+
+	select {
+	case <-ch:
+		t.Fatal("Timer fired too early")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+
+	if now := <-ch; !now.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected %v but got %v", start.Add(time.Minute), now)
+	}
+}
+
+func TestFakeClockRetries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"message":{"lang":"pt-BR","value":"Unavailable"}}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	}))
+	defer server.Close()
+
+	clock := millenniumtest.NewFakeClock(time.Now())
+	client, err := millennium.NewClient(context.Background(), server.URL, 5*time.Second, millennium.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The default backoff waits an hour, only the fake clock can make it in time
+	client.Client.RetryWaitMin = time.Hour
+	client.Client.RetryWaitMax = time.Hour
+
+	done := make(chan error, 1)
+	go func() {
+		var r interface{}
+		_, err := client.Get("test", url.Values{}, &r)
+		done <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&hits) != 3 {
+		t.Errorf("Expected 3 attempts but got %d", hits)
+	}
+}
@@ -0,0 +1,48 @@
+package millennium
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// GetNDJSON requests a method using GET http method and writes each entry of
+// the response to w as newline delimited JSON, converting the records as they
+// arrive, ready to be piped into tools like jq or BigQuery loads.
+// The fields of the client redaction policy are hidden, as the records
+// leave the process. It returns the number of records written.
+func (m *Millennium) GetNDJSON(method string, params url.Values, w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+
+	var (
+		line  bytes.Buffer
+		count int
+	)
+
+	err := m.streamValues(m.Context, method, params, func(raw json.RawMessage) error {
+		line.Reset()
+		if err := json.Compact(&line, raw); err != nil {
+			return fmt.Errorf("unable to compact record: %w", err)
+		}
+
+		if _, err := bw.Write(m.redaction.Redact(line.Bytes())); err != nil {
+			return fmt.Errorf("unable to write record: %w", err)
+		}
+
+		if err := bw.WriteByte('\n'); err != nil {
+			return fmt.Errorf("unable to write record: %w", err)
+		}
+
+		count++
+		return nil
+	})
+
+	if flushErr := bw.Flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("unable to write record: %w", flushErr)
+	}
+
+	return count, err
+}
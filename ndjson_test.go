@@ -0,0 +1,65 @@
+package millennium
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetNDJSON(t *testing.T) {
+	client := NewTestClient(t)
+
+	var buf bytes.Buffer
+	count, err := client.GetNDJSON("test.stream", url.Values{}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 200 {
+		t.Errorf("Expected 200 records but got %d", count)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid line %s: %v", scanner.Text(), err)
+		}
+
+		if record["number"] != float64(lines) {
+			t.Errorf("Expected record %d but got %v", lines, record["number"])
+		}
+		lines++
+	}
+
+	if lines != count {
+		t.Errorf("Expected %d lines but got %d", count, lines)
+	}
+
+	if _, err := client.GetNDJSON("test.error400.GET", url.Values{}, &buf); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestGetNDJSONRedaction(t *testing.T) {
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithRedaction("number"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	count, err := client.GetNDJSON("test.stream", url.Values{}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if redacted := strings.Count(buf.String(), `"number":"REDACTED"`); count == 0 || redacted != count {
+		t.Errorf("Expected %d redacted records but got %d", count, redacted)
+	}
+}
@@ -0,0 +1,109 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BRNumber is a float that also decodes numbers sent by Millennium as
+// strings in the Brazilian format, like "1.234,56".
+// Plain JSON numbers are decoded as usual and null or empty strings decode
+// to zero. It can be used as field type with Get or Record.ScanStruct.
+type BRNumber float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *BRNumber) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) == 0 || data[0] != '"' {
+		var f float64
+		if err := json.Unmarshal(data, &f); err != nil {
+			return err
+		}
+
+		*n = BRNumber(f)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(s) == "" {
+		*n = 0
+		return nil
+	}
+
+	f, err := ParseBRNumber(s)
+	if err != nil {
+		return err
+	}
+
+	*n = BRNumber(f)
+	return nil
+}
+
+// ParseBRNumber parses a number in the Brazilian format, where dots group
+// the thousands and a comma separates the decimals ("-1.234,56").
+// Dots are always read as thousands separators, so "1.500" is 1500 and a
+// misplaced dot like in "12.50" is reported as an error instead of being
+// silently read as a decimal separator.
+func ParseBRNumber(s string) (float64, error) {
+	value := strings.TrimSpace(s)
+
+	sign := ""
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		sign, value = value[:1], value[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(value, ",")
+	if hasFraction && !isDigits(fraction) {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	if strings.Contains(integer, ".") {
+		groups := strings.Split(integer, ".")
+		for i, group := range groups {
+			if !isDigits(group) || (i == 0 && len(group) > 3) || (i > 0 && len(group) != 3) {
+				return 0, fmt.Errorf("invalid number %q", s)
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+
+	if !isDigits(integer) {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	if hasFraction {
+		integer += "." + fraction
+	}
+
+	f, err := strconv.ParseFloat(sign+integer, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", s, err)
+	}
+
+	return f, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+package millennium
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseBRNumber(t *testing.T) {
+	cases := []struct {
+		Value       string
+		Expected    float64
+		ExpectError bool
+	}{
+		{Value: "1.234,56", Expected: 1234.56},
+		{Value: "-1.234.567,8", Expected: -1234567.8},
+		{Value: " 0,5 ", Expected: 0.5},
+		{Value: "1.500", Expected: 1500},
+		{Value: "42", Expected: 42},
+		{Value: "+7,00", Expected: 7},
+		{Value: "12.50", ExpectError: true},
+		{Value: "1234.567", ExpectError: true},
+		{Value: "1,2,3", ExpectError: true},
+		{Value: "1,", ExpectError: true},
+		{Value: ",5", ExpectError: true},
+		{Value: "abc", ExpectError: true},
+		{Value: "", ExpectError: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Value, func(t *testing.T) {
+			f, err := ParseBRNumber(c.Value)
+			if c.ExpectError {
+				if err == nil {
+					t.Errorf("Expected error but got %v", f)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if f != c.Expected {
+				t.Errorf("Expected %v but got %v", c.Expected, f)
+			}
+		})
+	}
+}
+
+func TestBRNumberUnmarshal(t *testing.T) {
+	var r Record
+	body := []byte(`{"preco":"1.234,56","custo":10.5,"desconto":null,"frete":"","peso":"x"}`)
+	if err := json.Unmarshal(body, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	var target struct {
+		Preco    BRNumber
+		Custo    BRNumber
+		Desconto BRNumber
+		Frete    BRNumber
+	}
+
+	if err := r.ScanStruct(&target); err != nil {
+		t.Fatal(err)
+	}
+
+	if target.Preco != 1234.56 || target.Custo != 10.5 || target.Desconto != 0 || target.Frete != 0 {
+		t.Errorf("Unexpected result %+v", target)
+	}
+
+	var invalid struct {
+		Peso BRNumber
+	}
+
+	if err := r.ScanStruct(&invalid); err == nil {
+		t.Error("Expected error")
+	}
+}
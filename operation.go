@@ -0,0 +1,23 @@
+package millennium
+
+import (
+	"context"
+)
+
+type operationKey struct{}
+
+// WithOperation returns a copy of ctx tagging the requests made with it as
+// part of a business operation, like "sync-orders". The operation is set in
+// the samples and HAR entries of these requests, so the low level method
+// calls can be grouped by what triggered them.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// OperationFrom returns the operation carried by ctx, an empty string if
+// none. It can be used by hooks receiving the request context, like a
+// RetryClassifier or an AuthLayer.
+func OperationFrom(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
+}
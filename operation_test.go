@@ -0,0 +1,50 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithOperation(t *testing.T) {
+	var samples []Sample
+	recorder := NewHARRecorder()
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second,
+		WithSampleHook(func(s Sample) { samples = append(samples, s) }),
+		WithHARRecorder(recorder),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithOperation(context.Background(), "sync-orders")
+	if OperationFrom(ctx) != "sync-orders" || OperationFrom(context.Background()) != "" {
+		t.Fatal("Unexpected operation from context")
+	}
+
+	var r interface{}
+	if _, err := client.GetCtx(ctx, "test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(samples) != 2 || samples[0].Operation != "sync-orders" || samples[1].Operation != "" {
+		t.Errorf("Unexpected samples %+v", samples)
+	}
+
+	var buf bytes.Buffer
+	if _, err := recorder.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Count(buf.String(), `"comment": "sync-orders"`) != 1 {
+		t.Errorf("Expected a single HAR entry with the operation in %s", buf.String())
+	}
+}
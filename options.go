@@ -0,0 +1,11 @@
+package millennium
+
+// Option configures optional behaviors of a Millennium client
+type Option func(m *Millennium)
+
+// WithRetryClassifier replaces the policy deciding which failed requests are retried
+func WithRetryClassifier(classifier RetryClassifier) Option {
+	return func(m *Millennium) {
+		m.retryClassifier = classifier
+	}
+}
@@ -0,0 +1,150 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnstablePaging is returned by ListAll when the paging check finds
+// records repeated or missing between pages and no hook is set
+var ErrUnstablePaging = errors.New("unstable paging")
+
+// PagingIssue describes records repeated or missing between the pages of
+// a listing, usually caused by an ordering that is not stable, making
+// $skip return the same records twice while others are never returned
+type PagingIssue struct {
+	Method string
+
+	// Page is the zero based page where the issue was found, and Skip its
+	// offset
+	Page int
+	Skip int
+
+	// Duplicates are the keys returned by previous pages again
+	Duplicates []string
+
+	// Missing is the number of records reported by odata.count that were
+	// never returned, only known at the end of the listing
+	Missing int
+}
+
+func (i PagingIssue) Error() string {
+	if i.Missing > 0 {
+		return fmt.Sprintf("%v: %s missed %d records", ErrUnstablePaging, i.Method, i.Missing)
+	}
+
+	return fmt.Sprintf("%v: %s page %d (skip %d) repeated %d records", ErrUnstablePaging, i.Method, i.Page, i.Skip, len(i.Duplicates))
+}
+
+func (i PagingIssue) Unwrap() error {
+	return ErrUnstablePaging
+}
+
+// PagingHook receives the issues found by the paging check
+type PagingHook func(issue PagingIssue)
+
+// WithPagingCheck checks the listings of ListAll for records repeated or
+// missing between pages, identified by the method PageKey. Issues are sent
+// to hook or, when hook is nil, fail the listing with a PagingIssue.
+// It is meant for debugging, as the keys of every record listed are kept
+// in memory.
+func WithPagingCheck(hook PagingHook) Option {
+	return func(m *Millennium) {
+		m.pagingCheck = true
+		m.pagingHook = hook
+	}
+}
+
+// pagingChecker tracks the keys returned by a listing
+type pagingChecker struct {
+	method string
+	key    string
+	hook   PagingHook
+	seen   map[string]bool
+	pages  int
+}
+
+// newPagingChecker returns the checker of a listing, nil if disabled
+func (m *Millennium) newPagingChecker(method string) *pagingChecker {
+	if !m.pagingCheck {
+		return nil
+	}
+
+	return &pagingChecker{
+		method: method,
+		key:    m.methodConfig(method).PageKey,
+		hook:   m.pagingHook,
+		seen:   map[string]bool{},
+	}
+}
+
+// page checks the records of the next page
+func (c *pagingChecker) page(skip int, page []json.RawMessage) error {
+	if c == nil {
+		return nil
+	}
+
+	issue := PagingIssue{Method: c.method, Page: c.pages, Skip: skip}
+	c.pages++
+
+	for _, raw := range page {
+		key, err := c.recordKey(raw)
+		if err != nil {
+			return err
+		}
+
+		if c.seen[key] {
+			issue.Duplicates = append(issue.Duplicates, key)
+			continue
+		}
+		c.seen[key] = true
+	}
+
+	if len(issue.Duplicates) == 0 {
+		return nil
+	}
+
+	return c.report(issue)
+}
+
+// done checks that every record counted was returned
+func (c *pagingChecker) done(count int) error {
+	if c == nil || count <= len(c.seen) {
+		return nil
+	}
+
+	return c.report(PagingIssue{Method: c.method, Page: c.pages, Missing: count - len(c.seen)})
+}
+
+func (c *pagingChecker) report(issue PagingIssue) error {
+	if c.hook == nil {
+		return issue
+	}
+
+	c.hook(issue)
+	return nil
+}
+
+func (c *pagingChecker) recordKey(raw json.RawMessage) (string, error) {
+	if c.key == "" {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return "", fmt.Errorf("unable to decode record: %w", err)
+		}
+		return compact.String(), nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", fmt.Errorf("unable to decode record: %w", err)
+	}
+
+	value, ok := fields[c.key]
+	if !ok {
+		return "", fmt.Errorf("record has no %s field to check the paging", c.key)
+	}
+
+	return string(bytes.TrimSpace(value)), nil
+}
@@ -0,0 +1,92 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithPagingCheck(t *testing.T) {
+	// newServer lists 20 records, shifting the pages after the first one
+	// back by shift records, as an unstable ordering does
+	newServer := func(shift int) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+			skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+			if skip > 0 {
+				skip -= shift
+			}
+
+			var values []string
+			for i := skip; i < 20 && i < skip+top; i++ {
+				values = append(values, fmt.Sprintf(`{"codigo":%d,"nome":"produto"}`, i))
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"odata.count":20,"value":[%s]}`, strings.Join(values, ","))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	cases := []struct {
+		Name       string
+		Shift      int
+		Hook       bool
+		Issues     int
+		Duplicates []string
+		Error      bool
+	}{
+		{Name: "stable"},
+		{Name: "unstable with hook", Shift: 1, Hook: true, Issues: 2, Duplicates: []string{"9"}},
+		{Name: "unstable without hook", Shift: 1, Error: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var issues []PagingIssue
+			var hook PagingHook
+			if c.Hook {
+				hook = func(issue PagingIssue) {
+					issues = append(issues, issue)
+				}
+			}
+
+			client, err := NewClient(context.Background(), newServer(c.Shift).URL, 5*time.Second, WithPageSize(10), WithPagingCheck(hook))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.Configure("test", MethodConfig{PageKey: "codigo"})
+
+			var records []Record
+			_, err = client.ListAll("test", nil, &records)
+			if (err != nil) != c.Error {
+				t.Fatalf("Expected error to be %v but got %v", c.Error, err)
+			}
+
+			if c.Error && !errors.Is(err, ErrUnstablePaging) {
+				t.Errorf("Expected %v but got %v", ErrUnstablePaging, err)
+			}
+
+			if len(issues) != c.Issues {
+				t.Fatalf("Expected %d issues but got %+v", c.Issues, issues)
+			}
+
+			if c.Issues > 0 {
+				if fmt.Sprint(issues[0].Duplicates) != fmt.Sprint(c.Duplicates) || issues[0].Page != 1 {
+					t.Errorf("Expected duplicates %v on page 1 but got %+v", c.Duplicates, issues[0])
+				}
+
+				if issues[1].Missing != 1 {
+					t.Errorf("Expected 1 missing record but got %+v", issues[1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,642 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// parquetMagic opens and closes every Parquet file
+const parquetMagic = "PAR1"
+
+// parquetKind is the type of a Parquet column, mapped to its physical and
+// converted types
+type parquetKind int
+
+const (
+	parquetString parquetKind = iota
+	parquetBool
+	parquetInt64
+	parquetDouble
+	parquetTimestamp
+	parquetJSON
+)
+
+// Parquet physical types
+const (
+	parquetTypeBoolean   = 0
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+)
+
+// Parquet converted types
+const (
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+	parquetConvertedJSON            = 19
+)
+
+// Parquet encodings
+const (
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+)
+
+func (k parquetKind) physicalType() int32 {
+	switch k {
+	case parquetBool:
+		return parquetTypeBoolean
+	case parquetInt64, parquetTimestamp:
+		return parquetTypeInt64
+	case parquetDouble:
+		return parquetTypeDouble
+	default:
+		return parquetTypeByteArray
+	}
+}
+
+// convertedType returns the converted type of the column, if any
+func (k parquetKind) convertedType() (int32, bool) {
+	switch k {
+	case parquetString:
+		return parquetConvertedUTF8, true
+	case parquetTimestamp:
+		return parquetConvertedTimestampMillis, true
+	case parquetJSON:
+		return parquetConvertedJSON, true
+	default:
+		return 0, false
+	}
+}
+
+type parquetColumn struct {
+	Name string
+	Kind parquetKind
+
+	// Field is the struct field name the column comes from, if any
+	Field string
+}
+
+// ExportParquet requests a method using GET http method and writes the
+// response to w as a Parquet file with a single row group.
+//
+// When schema is a struct, or a pointer to one, its exported fields define
+// the columns: they are named and matched as in Record.ScanStruct, and typed
+// from the Go type of the field. Values that do not fit the field type fail
+// the export. When schema is nil the columns are inferred from the response:
+// they follow the order the fields appear in, and a column is boolean,
+// integer, double or timestamp when every value of it is, nested objects and
+// arrays are JSON and everything else is text.
+//
+// Every column is optional, so null and missing values are exported as
+// nulls. The fields of the client redaction policy are hidden, as the
+// records leave the process, and exported as text. It returns the number of records written.
+//
+// Parquet stores the values column by column, so the records are kept in
+// memory until the response ends, about the size of the response.
+func (m *Millennium) ExportParquet(method string, params url.Values, w io.Writer, schema interface{}) (int, error) {
+	var columns []parquetColumn
+	if schema != nil {
+		var err error
+		if columns, err = parquetStructColumns(schema); err != nil {
+			return 0, err
+		}
+	}
+
+	var (
+		records []Record
+		names   []string
+		seen    = map[string]bool{}
+	)
+
+	err := m.streamValues(m.Context, method, params, func(raw json.RawMessage) error {
+		fields, err := orderedFields(m.redaction.Redact(raw))
+		if err != nil {
+			return err
+		}
+
+		record := make(Record, len(fields))
+		for _, field := range fields {
+			record[field.Name] = field.Value
+			if !seen[field.Name] {
+				seen[field.Name] = true
+				names = append(names, field.Name)
+			}
+		}
+
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if schema == nil {
+		columns = inferParquetColumns(names, records)
+	}
+
+	// Redacted values are text, whatever the type of the field
+	for i := range columns {
+		if m.redaction.Match(columns[i].Name) {
+			columns[i].Kind = parquetString
+		}
+	}
+
+	if err := writeParquet(w, columns, records); err != nil {
+		return 0, fmt.Errorf("unable to write parquet file: %w", err)
+	}
+
+	return len(records), nil
+}
+
+// parquetStructColumns returns the columns defined by the fields of a struct
+func parquetStructColumns(schema interface{}) ([]parquetColumn, error) {
+	t := reflect.TypeOf(schema)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema should be a struct, got %s", t.Kind())
+	}
+
+	var columns []parquetColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && recordFieldTag(field) == "" {
+			embedded, err := parquetStructColumns(reflect.Zero(field.Type).Interface())
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, embedded...)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := recordFieldTag(field)
+		if name == "-" {
+			continue
+		}
+
+		column := parquetColumn{Name: name, Kind: parquetFieldKind(field.Type), Field: field.Name}
+		if column.Name == "" {
+			column.Name = snakeCase(field.Name)
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// parquetFieldKind returns the column type of a struct field type
+func parquetFieldKind(t reflect.Type) parquetKind {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return parquetTimestamp
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return parquetBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return parquetInt64
+	case reflect.Float32, reflect.Float64:
+		return parquetDouble
+	case reflect.String:
+		return parquetString
+	default:
+		return parquetJSON
+	}
+}
+
+// inferParquetColumns types each column from the values of the records
+func inferParquetColumns(names []string, records []Record) []parquetColumn {
+	columns := make([]parquetColumn, len(names))
+	for i, name := range names {
+		var kinds []parquetKind
+		for _, record := range records {
+			value := bytes.TrimSpace(record[name])
+			if len(value) == 0 || value[0] == 'n' {
+				continue
+			}
+			kinds = append(kinds, parquetValueKind(value))
+		}
+
+		columns[i] = parquetColumn{Name: name, Kind: commonParquetKind(kinds)}
+	}
+
+	return columns
+}
+
+// parquetValueKind returns the narrowest column type of a JSON value
+func parquetValueKind(value json.RawMessage) parquetKind {
+	switch value[0] {
+	case 't', 'f':
+		return parquetBool
+	case '"':
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			if _, ok := parseParquetTime(s); ok {
+				return parquetTimestamp
+			}
+		}
+		return parquetString
+	case '{', '[':
+		return parquetJSON
+	default:
+		if _, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			return parquetInt64
+		}
+		return parquetDouble
+	}
+}
+
+// commonParquetKind returns the column type that holds all the kinds,
+// widening integers to doubles and mixed kinds to text
+func commonParquetKind(kinds []parquetKind) parquetKind {
+	if len(kinds) == 0 {
+		return parquetString
+	}
+
+	common := kinds[0]
+	for _, kind := range kinds[1:] {
+		switch {
+		case kind == common:
+		case kind == parquetDouble && common == parquetInt64, kind == parquetInt64 && common == parquetDouble:
+			common = parquetDouble
+		default:
+			return parquetString
+		}
+	}
+
+	return common
+}
+
+// parseParquetTime parses an ISO date, taking dates without a time zone
+// as UTC
+func parseParquetTime(s string) (time.Time, bool) {
+	for _, layout := range xlsxDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// writeParquet writes the records as an uncompressed Parquet file with one
+// plain encoded data page per column
+func writeParquet(w io.Writer, columns []parquetColumn, records []Record) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	chunks := make([]parquetChunk, len(columns))
+	for i, column := range columns {
+		page, err := encodeParquetPage(column, records)
+		if err != nil {
+			return err
+		}
+
+		chunks[i] = parquetChunk{Offset: int64(file.Len()), Size: int64(len(page)), Values: int64(len(records))}
+		file.Write(page)
+	}
+
+	footer := parquetFooter(columns, chunks, int64(len(records)))
+	file.Write(footer)
+	_ = binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// parquetChunk locates the data page of a column in the file
+type parquetChunk struct {
+	Offset int64
+	Size   int64
+	Values int64
+}
+
+// encodeParquetPage returns the data page of a column, header included
+func encodeParquetPage(column parquetColumn, records []Record) ([]byte, error) {
+	var (
+		levels = make([]bool, len(records))
+		values bytes.Buffer
+		bits   parquetBits
+	)
+
+	for i, record := range records {
+		var value json.RawMessage
+		if column.Field != "" {
+			value, _ = record.lookup(column.Name, column.Field)
+		} else {
+			value = record[column.Name]
+		}
+
+		value = bytes.TrimSpace(value)
+		if len(value) == 0 || bytes.Equal(value, []byte("null")) {
+			continue
+		}
+
+		levels[i] = true
+		if err := encodeParquetValue(&values, &bits, column.Kind, value); err != nil {
+			return nil, fmt.Errorf("unable to export field %s: %w", column.Name, err)
+		}
+	}
+
+	if column.Kind == parquetBool {
+		values.Write(bits.Bytes())
+	}
+
+	var data bytes.Buffer
+	encodedLevels := encodeParquetLevels(levels)
+	_ = binary.Write(&data, binary.LittleEndian, uint32(len(encodedLevels)))
+	data.Write(encodedLevels)
+	data.Write(values.Bytes())
+
+	var header thriftWriter
+	header.i32(1, 0) // DATA_PAGE
+	header.i32(2, int32(data.Len()))
+	header.i32(3, int32(data.Len()))
+	header.structField(5, func() {
+		header.i32(1, int32(len(records)))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+	})
+	header.stop()
+
+	return append(header.Bytes(), data.Bytes()...), nil
+}
+
+// encodeParquetValue appends a non null JSON value in plain encoding
+func encodeParquetValue(buf *bytes.Buffer, bits *parquetBits, kind parquetKind, value json.RawMessage) error {
+	switch kind {
+	case parquetBool:
+		var b bool
+		if err := json.Unmarshal(value, &b); err != nil {
+			return err
+		}
+		bits.Append(b)
+	case parquetInt64:
+		var n int64
+		if err := json.Unmarshal(value, &n); err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, n)
+	case parquetDouble:
+		var f float64
+		if err := json.Unmarshal(value, &f); err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+	case parquetTimestamp:
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return err
+		}
+
+		t, ok := parseParquetTime(s)
+		if !ok {
+			return fmt.Errorf("invalid date %q", s)
+		}
+		_ = binary.Write(buf, binary.LittleEndian, t.UnixMilli())
+	case parquetJSON:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			return err
+		}
+		writeParquetBytes(buf, compact.Bytes())
+	default:
+		var s string
+		if value[0] != '"' || json.Unmarshal(value, &s) != nil {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, value); err != nil {
+				return err
+			}
+			s = compact.String()
+		}
+		writeParquetBytes(buf, []byte(s))
+	}
+
+	return nil
+}
+
+func writeParquetBytes(buf *bytes.Buffer, b []byte) {
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(b)))
+	buf.Write(b)
+}
+
+// parquetBits packs booleans least significant bit first
+type parquetBits struct {
+	buf []byte
+	n   int
+}
+
+func (b *parquetBits) Append(v bool) {
+	if b.n%8 == 0 {
+		b.buf = append(b.buf, 0)
+	}
+	if v {
+		b.buf[len(b.buf)-1] |= 1 << (b.n % 8)
+	}
+	b.n++
+}
+
+func (b *parquetBits) Bytes() []byte {
+	return b.buf
+}
+
+// encodeParquetLevels encodes the definition levels of an optional column
+// as runs of the RLE hybrid encoding, with a bit width of one
+func encodeParquetLevels(levels []bool) []byte {
+	var buf []byte
+	for i := 0; i < len(levels); {
+		run := 1
+		for i+run < len(levels) && levels[i+run] == levels[i] {
+			run++
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(run)<<1)
+		if levels[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i += run
+	}
+
+	return buf
+}
+
+// parquetFooter returns the file metadata in the Thrift compact protocol
+func parquetFooter(columns []parquetColumn, chunks []parquetChunk, rows int64) []byte {
+	var meta thriftWriter
+	meta.i32(1, 1) // version
+
+	meta.list(2, thriftStruct, len(columns)+1)
+	meta.structValue(func() {
+		meta.binary(4, "schema")
+		meta.i32(5, int32(len(columns)))
+	})
+	for _, column := range columns {
+		meta.structValue(func() {
+			meta.i32(1, column.Kind.physicalType())
+			meta.i32(3, 1) // OPTIONAL
+			meta.binary(4, column.Name)
+			if converted, ok := column.Kind.convertedType(); ok {
+				meta.i32(6, converted)
+			}
+		})
+	}
+
+	meta.i64(3, rows)
+
+	if rows == 0 {
+		meta.list(4, thriftStruct, 0)
+	} else {
+		var total int64
+		for _, chunk := range chunks {
+			total += chunk.Size
+		}
+
+		meta.list(4, thriftStruct, 1)
+		meta.structValue(func() {
+			meta.list(1, thriftStruct, len(columns))
+			for i, column := range columns {
+				chunk := chunks[i]
+				meta.structValue(func() {
+					meta.i64(2, chunk.Offset)
+					meta.structField(3, func() {
+						meta.i32(1, column.Kind.physicalType())
+						meta.list(2, thriftI32, 2)
+						meta.elemI32(parquetEncodingPlain)
+						meta.elemI32(parquetEncodingRLE)
+						meta.list(3, thriftBinary, 1)
+						meta.elemBinary(column.Name)
+						meta.i32(4, 0) // UNCOMPRESSED
+						meta.i64(5, chunk.Values)
+						meta.i64(6, chunk.Size)
+						meta.i64(7, chunk.Size)
+						meta.i64(9, chunk.Offset)
+					})
+				})
+			}
+			meta.i64(2, total)
+			meta.i64(3, rows)
+		})
+	}
+
+	meta.binary(6, "millennium-go")
+	meta.stop()
+
+	return meta.Bytes()
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes the Thrift compact protocol, enough for the Parquet
+// metadata
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last int16
+}
+
+func (t *thriftWriter) Bytes() []byte {
+	return t.buf.Bytes()
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.elemI32(v)
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.elemBinary(s)
+}
+
+func (t *thriftWriter) list(id int16, typ byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | typ)
+		return
+	}
+
+	t.buf.WriteByte(0xf0 | typ)
+	t.varint(uint64(size))
+}
+
+func (t *thriftWriter) elemI32(v int32) {
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) elemBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) structField(id int16, fn func()) {
+	t.field(id, thriftStruct)
+	t.structValue(fn)
+}
+
+// structValue writes a nested struct, whose field ids start over
+func (t *thriftWriter) structValue(fn func()) {
+	last := t.last
+	t.last = 0
+	fn()
+	t.stop()
+	t.last = last
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
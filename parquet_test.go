@@ -0,0 +1,318 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// parquetFile is a Parquet file read back by readParquet
+type parquetFile struct {
+	Rows      int64
+	Names     []string
+	Types     []int64
+	Converted []interface{}
+	Values    [][]interface{}
+}
+
+// readParquet decodes the files written by writeParquet, failing the test
+// on anything it does not expect
+func readParquet(t *testing.T, data []byte) parquetFile {
+	t.Helper()
+
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("Expected parquet magic around %q", data)
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-size : len(data)-8]
+	r := &thriftReader{t: t, buf: footer}
+	meta := r.readStruct()
+	if r.pos != len(footer) {
+		t.Fatalf("Expected footer of %d bytes but read %d", len(footer), r.pos)
+	}
+
+	var file parquetFile
+	file.Rows = meta[3].(int64)
+
+	for _, element := range meta[2].([]interface{})[1:] {
+		e := element.(map[int16]interface{})
+		if e[3].(int64) != 1 {
+			t.Errorf("Expected column %s to be optional", e[4])
+		}
+		file.Names = append(file.Names, e[4].(string))
+		file.Types = append(file.Types, e[1].(int64))
+		file.Converted = append(file.Converted, e[6])
+	}
+
+	groups := meta[4].([]interface{})
+	if len(groups) == 0 {
+		return file
+	}
+
+	for i, chunk := range groups[0].(map[int16]interface{})[1].([]interface{}) {
+		column := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		offset := int(column[9].(int64))
+
+		page := &thriftReader{t: t, buf: data[offset:]}
+		header := page.readStruct()
+		body := data[offset+page.pos : offset+page.pos+int(header[3].(int64))]
+		count := int(header[5].(map[int16]interface{})[1].(int64))
+
+		file.Values = append(file.Values, readParquetPage(t, body, count, file.Types[i]))
+	}
+
+	return file
+}
+
+// readParquetPage decodes a plain encoded data page of an optional column
+func readParquetPage(t *testing.T, body []byte, count int, typ int64) []interface{} {
+	size := int(binary.LittleEndian.Uint32(body))
+	levels := body[4 : 4+size]
+	values := body[4+size:]
+
+	var defined []bool
+	for len(levels) > 0 {
+		run, n := binary.Uvarint(levels)
+		if run&1 != 0 {
+			t.Fatal("Expected only RLE runs in the definition levels")
+		}
+		for i := 0; i < int(run>>1); i++ {
+			defined = append(defined, levels[n] == 1)
+		}
+		levels = levels[n+1:]
+	}
+
+	if len(defined) != count {
+		t.Fatalf("Expected %d definition levels but got %d", count, len(defined))
+	}
+
+	result := make([]interface{}, count)
+	bit := 0
+	for i, ok := range defined {
+		if !ok {
+			continue
+		}
+
+		switch typ {
+		case parquetTypeBoolean:
+			result[i] = values[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		case parquetTypeInt64:
+			result[i] = int64(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetTypeDouble:
+			result[i] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetTypeByteArray:
+			n := binary.LittleEndian.Uint32(values)
+			result[i] = string(values[4 : 4+n])
+			values = values[4+n:]
+		}
+	}
+
+	return result
+}
+
+// thriftReader reads the Thrift compact protocol into maps keyed by field id
+type thriftReader struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("Invalid varint at %d", r.pos)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		b := r.buf[r.pos]
+		r.pos++
+		if b == 0 {
+			return fields
+		}
+
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v := r.uvarint()
+			id = int16(v>>1) ^ -int16(v&1)
+		}
+		fields[id] = r.readValue(b & 0x0f)
+		last = id
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		v := r.uvarint()
+		return int64(v>>1) ^ -int64(v&1)
+	case thriftBinary:
+		n := int(r.uvarint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		b := r.buf[r.pos]
+		r.pos++
+		size := int(b >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.readValue(b & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		r.t.Fatalf("Unexpected thrift type %d", typ)
+		return nil
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	client := NewTestClient(t)
+
+	var buf bytes.Buffer
+	count, err := client.ExportParquet("test.export", url.Values{}, &buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 records but got %d", count)
+	}
+
+	file := readParquet(t, buf.Bytes())
+	if file.Rows != 2 {
+		t.Errorf("Expected 2 rows but got %d", file.Rows)
+	}
+
+	date := time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC).UnixMilli()
+	expected := parquetFile{
+		Rows:      2,
+		Names:     []string{"codigo", "preco", "ativo", "data", "obs", "itens"},
+		Types:     []int64{parquetTypeByteArray, parquetTypeDouble, parquetTypeBoolean, parquetTypeInt64, parquetTypeByteArray, parquetTypeByteArray},
+		Converted: []interface{}{int64(parquetConvertedUTF8), nil, nil, int64(parquetConvertedTimestampMillis), int64(parquetConvertedUTF8), int64(parquetConvertedJSON)},
+		Values: [][]interface{}{
+			{"001", "<002>"},
+			{12.5, 3.0},
+			{true, false},
+			{date, nil},
+			{nil, "x"},
+			{nil, "[1,2]"},
+		},
+	}
+
+	if !reflect.DeepEqual(file, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, file)
+	}
+
+	if _, err := client.ExportParquet("test.error400.GET", url.Values{}, &buf, nil); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestExportParquetSchema(t *testing.T) {
+	type base struct {
+		Codigo string `json:"codigo"`
+	}
+
+	type product struct {
+		base
+		Preco      float64
+		Ativo      *bool
+		Data       time.Time `millennium:"data"`
+		Quantidade int
+		Itens      []int
+		Obs        string `json:"-"`
+		internal   string
+	}
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithRedaction("preco"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := client.ExportParquet("test.export", url.Values{}, &buf, &product{}); err != nil {
+		t.Fatal(err)
+	}
+
+	file := readParquet(t, buf.Bytes())
+
+	date := time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC).UnixMilli()
+	expected := parquetFile{
+		Rows:      2,
+		Names:     []string{"codigo", "preco", "ativo", "data", "quantidade", "itens"},
+		Types:     []int64{parquetTypeByteArray, parquetTypeByteArray, parquetTypeBoolean, parquetTypeInt64, parquetTypeInt64, parquetTypeByteArray},
+		Converted: []interface{}{int64(parquetConvertedUTF8), int64(parquetConvertedUTF8), nil, int64(parquetConvertedTimestampMillis), nil, int64(parquetConvertedJSON)},
+		Values: [][]interface{}{
+			{"001", "<002>"},
+			{Redacted, Redacted},
+			{true, false},
+			{date, nil},
+			{nil, nil},
+			{nil, "[1,2]"},
+		},
+	}
+
+	if !reflect.DeepEqual(file, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, file)
+	}
+
+	t.Run("mismatched type", func(t *testing.T) {
+		var schema struct {
+			Codigo int `json:"codigo"`
+		}
+
+		if _, err := client.ExportParquet("test.export", url.Values{}, &buf, schema); err == nil {
+			t.Error("Expected error")
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := client.ExportParquet("test.export", url.Values{}, &buf, "codigo"); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
+
+func TestCommonParquetKind(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Kinds    []parquetKind
+		Expected parquetKind
+	}{
+		{Name: "no values", Kinds: nil, Expected: parquetString},
+		{Name: "integers", Kinds: []parquetKind{parquetInt64, parquetInt64}, Expected: parquetInt64},
+		{Name: "numbers", Kinds: []parquetKind{parquetInt64, parquetDouble, parquetInt64}, Expected: parquetDouble},
+		{Name: "dates", Kinds: []parquetKind{parquetTimestamp}, Expected: parquetTimestamp},
+		{Name: "dates and text", Kinds: []parquetKind{parquetTimestamp, parquetString}, Expected: parquetString},
+		{Name: "booleans and numbers", Kinds: []parquetKind{parquetBool, parquetDouble}, Expected: parquetString},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if kind := commonParquetKind(c.Kinds); kind != c.Expected {
+				t.Errorf("Expected %d but got %d", c.Expected, kind)
+			}
+		})
+	}
+}
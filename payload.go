@@ -0,0 +1,108 @@
+package millennium
+
+import (
+	"sort"
+	"sync"
+)
+
+// PayloadBuckets are the upper bounds, in bytes, of the buckets of the
+// payload size histograms
+var PayloadBuckets = []int64{1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20, 100 << 20}
+
+// SizeHistogram is the distribution of the payload sizes of a method
+type SizeHistogram struct {
+	// Buckets are the upper bounds of the buckets, as PayloadBuckets
+	Buckets []int64
+
+	// Counts holds the number of payloads of each bucket, with one more
+	// entry for the payloads larger than the last bucket
+	Counts []int64
+
+	Count int64
+	Sum   int64
+	Max   int64
+}
+
+// Mean returns the average payload size
+func (h SizeHistogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return float64(h.Sum) / float64(h.Count)
+}
+
+func (h *SizeHistogram) observe(size int64) {
+	if h.Counts == nil {
+		h.Buckets = PayloadBuckets
+		h.Counts = make([]int64, len(PayloadBuckets)+1)
+	}
+
+	h.Counts[sort.Search(len(h.Buckets), func(i int) bool { return size <= h.Buckets[i] })]++
+	h.Count++
+	h.Sum += size
+	if size > h.Max {
+		h.Max = size
+	}
+}
+
+func (h SizeHistogram) clone() SizeHistogram {
+	h.Counts = append([]int64(nil), h.Counts...)
+	return h
+}
+
+// PayloadSizes are the sizes of the bodies sent and received by a method
+type PayloadSizes struct {
+	Request  SizeHistogram
+	Response SizeHistogram
+}
+
+// PayloadSizes returns the payload sizes of every method requested by the
+// client, to spot methods that suddenly transfer much more data. Response
+// sizes are the bytes read by the time the body is closed.
+func (m *Millennium) PayloadSizes() map[string]PayloadSizes {
+	return m.payloads.snapshot()
+}
+
+// payloadTracker keeps the payload sizes of every method
+type payloadTracker struct {
+	mu      sync.Mutex
+	methods map[string]*PayloadSizes
+}
+
+func (t *payloadTracker) record(method string, sent int64, received int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.methods == nil {
+		t.methods = map[string]*PayloadSizes{}
+	}
+
+	sizes, ok := t.methods[method]
+	if !ok {
+		sizes = &PayloadSizes{}
+		t.methods[method] = sizes
+	}
+
+	if sent < 0 {
+		sent = 0
+	}
+
+	sizes.Request.observe(sent)
+	sizes.Response.observe(received)
+}
+
+func (t *payloadTracker) snapshot() map[string]PayloadSizes {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make(map[string]PayloadSizes, len(t.methods))
+	for method, sizes := range t.methods {
+		snapshot[method] = PayloadSizes{
+			Request:  sizes.Request.clone(),
+			Response: sizes.Response.clone(),
+		}
+	}
+
+	return snapshot
+}
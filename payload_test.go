@@ -0,0 +1,78 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPayloadSizes(t *testing.T) {
+	large := `{"odata.count":1,"value":[{"descricao":"` + strings.Repeat("x", 20<<10) + `"}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/test.large" {
+			_, _ = w.Write([]byte(large))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get("test.large", nil, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := client.Post("test.small", []byte(`{"pedido":1}`), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := client.PayloadSizes()
+
+	cases := []struct {
+		Method   string
+		Requests int64
+		Sent     int64
+		Received int64
+		Bucket   int
+	}{
+		{Method: "test.large", Requests: 2, Sent: 0, Received: int64(len(large)), Bucket: 2},
+		{Method: "test.small", Requests: 1, Sent: int64(len(`{"pedido":1}`)), Received: int64(len(`{"odata.count":0,"value":[]}`)), Bucket: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Method, func(t *testing.T) {
+			s, ok := sizes[c.Method]
+			if !ok {
+				t.Fatal("Expected payload sizes of the method")
+			}
+
+			if s.Response.Count != c.Requests || s.Request.Count != c.Requests {
+				t.Errorf("Expected %d requests but got %d and %d", c.Requests, s.Request.Count, s.Response.Count)
+			}
+
+			if s.Request.Max != c.Sent {
+				t.Errorf("Expected %d bytes sent but got %d", c.Sent, s.Request.Max)
+			}
+
+			if s.Response.Max != c.Received || s.Response.Mean() != float64(c.Received) {
+				t.Errorf("Expected %d bytes received but got %d", c.Received, s.Response.Max)
+			}
+
+			if s.Response.Counts[c.Bucket] != c.Requests {
+				t.Errorf("Expected responses in bucket %d but got %v", c.Bucket, s.Response.Counts)
+			}
+		})
+	}
+}
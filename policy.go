@@ -0,0 +1,61 @@
+package millennium
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrMethodNotAllowed is returned when the method policy of the client does
+// not allow a method
+var ErrMethodNotAllowed = errors.New("method not allowed by the client policy")
+
+// MethodPolicy restricts the Millennium methods a client may call, limiting
+// what a service can do with shared credentials. Patterns are case
+// insensitive and accept path.Match wildcards, like "millenium.produtos.*".
+type MethodPolicy struct {
+	// Allow lists the methods allowed, every method is allowed when empty
+	Allow []string
+
+	// Deny lists the methods denied, even if allowed by Allow
+	Deny []string
+}
+
+// WithMethodPolicy restricts the methods called by the client. Denied
+// requests fail with ErrMethodNotAllowed before being sent.
+func WithMethodPolicy(policy MethodPolicy) Option {
+	return func(m *Millennium) {
+		m.methodPolicy = &policy
+	}
+}
+
+// Allowed reports if the policy allows method
+func (p *MethodPolicy) Allowed(method string) bool {
+	method = strings.ToLower(method)
+
+	if matchMethod(p.Deny, method) {
+		return false
+	}
+
+	return len(p.Allow) == 0 || matchMethod(p.Allow, method)
+}
+
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), method); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMethod enforces the client method policy
+func (m *Millennium) checkMethod(method string) error {
+	if m.methodPolicy == nil || m.methodPolicy.Allowed(method) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrMethodNotAllowed, method)
+}
@@ -0,0 +1,72 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMethodPolicyAllowed(t *testing.T) {
+	policy := &MethodPolicy{
+		Allow: []string{"millenium.produtos.*", "millenium.pedido_venda.*"},
+		Deny:  []string{"*.exclui"},
+	}
+
+	cases := []struct {
+		Method  string
+		Allowed bool
+	}{
+		{Method: "millenium.produtos.lista", Allowed: true},
+		{Method: "MILLENIUM.Produtos.Lista", Allowed: true},
+		{Method: "millenium.pedido_venda.inclui", Allowed: true},
+		{Method: "millenium.pedido_venda.exclui", Allowed: false},
+		{Method: "millenium.clientes.lista", Allowed: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Method, func(t *testing.T) {
+			if allowed := policy.Allowed(c.Method); allowed != c.Allowed {
+				t.Errorf("Expected %v but got %v", c.Allowed, allowed)
+			}
+		})
+	}
+
+	if !(&MethodPolicy{}).Allowed("any.method") {
+		t.Error("Expected empty policy to allow every method")
+	}
+}
+
+func TestWithMethodPolicy(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusOK, `{"odata.count":0,"value":[]}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithMethodPolicy(MethodPolicy{
+		Deny: []string{"*.exclui"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Delete("millenium.pedido_venda.exclui", url.Values{}); !errors.Is(err, ErrMethodNotAllowed) {
+		t.Errorf("Expected ErrMethodNotAllowed but got %v", err)
+	}
+
+	records, errs := client.Stream(context.Background(), "millenium.pedido_venda.exclui", url.Values{})
+	for range records {
+	}
+	if err := <-errs; !errors.Is(err, ErrMethodNotAllowed) {
+		t.Errorf("Expected ErrMethodNotAllowed from stream but got %v", err)
+	}
+
+	if atomic.LoadInt32(hits) != 0 {
+		t.Errorf("Expected no request but got %d", *hits)
+	}
+
+	var r []Record
+	if _, err := client.Get("millenium.pedido_venda.lista", url.Values{}, &r); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package query builds OData $filter expressions for Millennium requests,
+// taking care of quoting, escaping and operator precedence.
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterParam is the request parameter receiving the filter
+const FilterParam = "$filter"
+
+// DateTimeLayout is the layout of the dates sent in filters
+const DateTimeLayout = "2006-01-02T15:04:05"
+
+// Filter is an OData filter expression. The zero value is an empty filter,
+// which matches every record.
+type Filter struct {
+	expr string
+}
+
+// String returns the filter expression
+func (f Filter) String() string {
+	return f.expr
+}
+
+// IsZero reports if the filter is empty
+func (f Filter) IsZero() bool {
+	return f.expr == ""
+}
+
+// Apply sets the filter in params and returns it, ready to be passed to Get.
+// Empty filters leave params untouched.
+func (f Filter) Apply(params url.Values) url.Values {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	if !f.IsZero() {
+		params.Set(FilterParam, f.expr)
+	}
+
+	return params
+}
+
+// Raw returns a filter with an expression written by hand, for operators
+// not covered by this package
+func Raw(expr string) Filter {
+	return Filter{expr: expr}
+}
+
+// Eq matches records whose field equals value
+func Eq(field string, value interface{}) Filter {
+	return compare(field, "eq", value)
+}
+
+// Ne matches records whose field is not equal to value
+func Ne(field string, value interface{}) Filter {
+	return compare(field, "ne", value)
+}
+
+// Gt matches records whose field is greater than value
+func Gt(field string, value interface{}) Filter {
+	return compare(field, "gt", value)
+}
+
+// Ge matches records whose field is greater than or equal to value
+func Ge(field string, value interface{}) Filter {
+	return compare(field, "ge", value)
+}
+
+// Lt matches records whose field is less than value
+func Lt(field string, value interface{}) Filter {
+	return compare(field, "lt", value)
+}
+
+// Le matches records whose field is less than or equal to value
+func Le(field string, value interface{}) Filter {
+	return compare(field, "le", value)
+}
+
+// Contains matches records whose field contains s, using the OData v3
+// substringof function supported by Millennium
+func Contains(field string, s string) Filter {
+	return Filter{expr: fmt.Sprintf("substringof(%s,%s)", Literal(s), field)}
+}
+
+// StartsWith matches records whose field starts with s
+func StartsWith(field string, s string) Filter {
+	return Filter{expr: fmt.Sprintf("startswith(%s,%s)", field, Literal(s))}
+}
+
+// And matches records matching every filter. Empty filters are ignored.
+func And(filters ...Filter) Filter {
+	return join("and", filters)
+}
+
+// Or matches records matching any of the filters. Empty filters are ignored.
+func Or(filters ...Filter) Filter {
+	return join("or", filters)
+}
+
+// Not matches records not matching f
+func Not(f Filter) Filter {
+	if f.IsZero() {
+		return f
+	}
+
+	return Filter{expr: fmt.Sprintf("not (%s)", f.expr)}
+}
+
+func compare(field string, op string, value interface{}) Filter {
+	return Filter{expr: fmt.Sprintf("%s %s %s", field, op, Literal(value))}
+}
+
+func join(op string, filters []Filter) Filter {
+	var exprs []string
+	var last Filter
+	for _, f := range filters {
+		if !f.IsZero() {
+			exprs = append(exprs, "("+f.expr+")")
+			last = f
+		}
+	}
+
+	// A single filter needs no parentheses
+	if len(exprs) <= 1 {
+		return last
+	}
+
+	return Filter{expr: strings.Join(exprs, " "+op+" ")}
+}
+
+// Literal renders value as an OData literal. Strings are quoted with their
+// single quotes doubled, and times are sent as datetime literals in the
+// layout of DateTimeLayout. Named types are rendered by their underlying
+// kind, so enum codes with a String method are sent as codes, not names.
+func Literal(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case time.Time:
+		return "datetime'" + v.Format(DateTimeLayout) + "'"
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return "'" + strings.ReplaceAll(v.String(), "'", "''") + "'"
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+
+	if stringer, ok := value.(fmt.Stringer); ok {
+		return Literal(stringer.String())
+	}
+
+	return Literal(fmt.Sprint(value))
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	date := time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name     string
+		Filter   Filter
+		Expected string
+	}{
+		{Name: "eq string", Filter: Eq("nome", "D'Avila"), Expected: "nome eq 'D''Avila'"},
+		{Name: "ne number", Filter: Ne("filial", 1), Expected: "filial ne 1"},
+		{Name: "gt float", Filter: Gt("preco", 10.5), Expected: "preco gt 10.5"},
+		{Name: "ge date", Filter: Ge("data", date), Expected: "data ge datetime'2024-03-01T10:30:00'"},
+		{Name: "lt", Filter: Lt("estoque", int64(3)), Expected: "estoque lt 3"},
+		{Name: "le", Filter: Le("estoque", uint(3)), Expected: "estoque le 3"},
+		{Name: "null", Filter: Eq("obs", nil), Expected: "obs eq null"},
+		{Name: "bool", Filter: Eq("ativo", true), Expected: "ativo eq true"},
+		{Name: "contains", Filter: Contains("descricao", "camisa"), Expected: "substringof('camisa',descricao)"},
+		{Name: "starts with", Filter: StartsWith("cod", "A'"), Expected: "startswith(cod,'A''')"},
+		{
+			Name:     "and or",
+			Filter:   And(Eq("ativo", true), Or(Eq("filial", 1), Eq("filial", 2))),
+			Expected: "(ativo eq true) and ((filial eq 1) or (filial eq 2))",
+		},
+		{Name: "single", Filter: And(Filter{}, Eq("ativo", true)), Expected: "ativo eq true"},
+		{Name: "empty", Filter: Or(), Expected: ""},
+		{Name: "not", Filter: Not(Eq("ativo", false)), Expected: "not (ativo eq false)"},
+		{Name: "not empty", Filter: Not(Filter{}), Expected: ""},
+		{Name: "raw", Filter: And(Raw("year(data) eq 2024"), Eq("filial", 1)), Expected: "(year(data) eq 2024) and (filial eq 1)"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if got := c.Filter.String(); got != c.Expected {
+				t.Errorf("Expected %q but got %q", c.Expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	params := Eq("filial", 1).Apply(url.Values{"$top": {"10"}})
+	if params.Get(FilterParam) != "filial eq 1" || params.Get("$top") != "10" {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	if params := (Filter{}).Apply(nil); params == nil || params.Has(FilterParam) {
+		t.Errorf("Expected empty params but got %v", params)
+	}
+}
+
+type orderStatus int
+
+func (s orderStatus) String() string { return "Open" }
+
+type paymentType string
+
+func (p paymentType) String() string { return "Cash" }
+
+type brNumber float64
+
+type code uint16
+
+type stringerStruct struct{}
+
+func (stringerStruct) String() string { return "x" }
+
+func TestLiteral(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Value    interface{}
+		Expected string
+	}{
+		{Name: "named int with String", Value: orderStatus(1), Expected: "1"},
+		{Name: "named string with String", Value: paymentType("DIN"), Expected: "'DIN'"},
+		{Name: "int16", Value: int16(5), Expected: "5"},
+		{Name: "int8", Value: int8(-5), Expected: "-5"},
+		{Name: "uint", Value: uint(7), Expected: "7"},
+		{Name: "named uint", Value: code(8), Expected: "8"},
+		{Name: "named float", Value: brNumber(1234.56), Expected: "1234.56"},
+		{Name: "float32", Value: float32(0.5), Expected: "0.5"},
+		{Name: "stringer struct", Value: stringerStruct{}, Expected: "'x'"},
+		{Name: "nil", Value: nil, Expected: "null"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if got := Literal(c.Value); got != c.Expected {
+				t.Errorf("Expected %q but got %q", c.Expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package millennium
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithRateLimit paces the requests of the client to rps per second, allowing
+// bursts of up to burst requests, so integrations don't overload Millennium.
+// Every attempt is paced, retries and logins included. The first attempt
+// is paced before waiting for a slot of WithScheduler, so requests waiting
+// for the rate limit don't hold a slot, and requests shed by
+// WithLoadShedding fail with ErrLoadShed when no token is available.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(m *Millennium) {
+		m.rateLimit = newRateLimiter(rps, burst)
+	}
+}
+
+// RateLimit paces the requests of the client, see WithRateLimit
+type RateLimit struct {
+	// RPS is the requests per second, zero for no limit
+	RPS float64
+
+	// Burst is the number of requests allowed at once
+	Burst int
+}
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at
+// rate tokens per second
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{rate: rps, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token, returning how long to wait until it is available.
+// Tokens may go negative, queuing the callers in arrival order.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// tryReserve takes a token only if one is available right away
+func (l *rateLimiter) tryReserve() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
+
+// cancel gives back a token reserved by a caller that gave up waiting
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// limiter returns the rate limiter of the client, nil without rate limit
+func (m *Millennium) limiter() *rateLimiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rateLimit == nil || m.rateLimit.rate <= 0 {
+		return nil
+	}
+
+	return m.rateLimit
+}
+
+// pace waits for the rate limit before an attempt, until ctx is done.
+// Attempts whose context is done fail on their own once sent.
+func (m *Millennium) pace(ctx context.Context) {
+	limiter := m.limiter()
+	if limiter == nil {
+		return
+	}
+
+	wait := limiter.reserve()
+	if wait <= 0 {
+		return
+	}
+
+	select {
+	case <-m.after(wait):
+	case <-ctx.Done():
+		limiter.cancel()
+	}
+}
+
+type pacedKey struct{}
+
+// pacedToken marks a request whose first attempt was already paced by
+// admitRate, consumed by the first attempt sent
+type pacedToken struct {
+	used atomic.Bool
+}
+
+// admitRate paces the first attempt of a request bound to ctx, before it
+// waits for a scheduler slot, returning the context marking it as paced.
+// Requests shed under load fail with ErrLoadShed instead of waiting.
+func (m *Millennium) admitRate(ctx context.Context) (context.Context, error) {
+	limiter := m.limiter()
+	if limiter == nil {
+		return ctx, nil
+	}
+
+	if m.sheds(priorityFrom(ctx)) {
+		if !limiter.tryReserve() {
+			return ctx, ErrLoadShed
+		}
+	} else {
+		m.pace(ctx)
+	}
+
+	return context.WithValue(ctx, pacedKey{}, &pacedToken{}), nil
+}
+
+// paceAttempt paces an attempt about to be sent, unless it is the first
+// one of a request already paced by admitRate
+func (m *Millennium) paceAttempt(ctx context.Context, attempt int) {
+	if token, ok := ctx.Value(pacedKey{}).(*pacedToken); ok && attempt == 0 && token.used.CompareAndSwap(false, true) {
+		return
+	}
+
+	m.pace(ctx)
+}
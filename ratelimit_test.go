@@ -0,0 +1,118 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Status   int
+		Burst    int
+		Requests int
+		Hits     int32
+		Min      time.Duration
+	}{
+		{Name: "burst", Status: http.StatusOK, Burst: 4, Requests: 4, Hits: 4},
+		{Name: "paced", Status: http.StatusOK, Burst: 2, Requests: 6, Hits: 6, Min: 200 * time.Millisecond},
+		{Name: "retries paced", Status: http.StatusInternalServerError, Burst: 1, Requests: 1, Hits: 3, Min: 100 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, hits := newCountingServer(t, c.Status, `{"odata.count":0,"value":[]}`)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRateLimit(20, c.Burst), WithRetryMax(2), WithRetryWaitMin(time.Millisecond), WithRetryWaitMax(time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			started := time.Now()
+			for i := 0; i < c.Requests; i++ {
+				var r interface{}
+				_, _ = client.Get("test", url.Values{}, &r)
+			}
+			elapsed := time.Since(started)
+
+			if *hits != c.Hits {
+				t.Errorf("Expected %d attempts but got %d", c.Hits, *hits)
+			}
+
+			if elapsed < c.Min || elapsed > c.Min+150*time.Millisecond {
+				t.Errorf("Expected the requests to take about %s but took %s", c.Min, elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusOK, `{"odata.count":0,"value":[]}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRateLimit(0.1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GetCtx(ctx, "test", url.Values{}, &r); err == nil {
+		t.Error("Expected the request waiting for the rate limit to be canceled")
+	}
+
+	if *hits != 1 {
+		t.Errorf("Expected a single request sent but got %d", *hits)
+	}
+}
+
+func TestRateLimitScheduler(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusOK, `{"odata.count":0,"value":[]}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRateLimit(5, 1), WithScheduler(1), WithLoadShedding(PriorityBatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(priority Priority) error {
+		var r interface{}
+		return client.Request(RequestMethod{HTTPMethod: GET, Method: "test", Response: &r, Priority: priority})
+	}
+
+	if err := request(PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a token the shed requests fail right away, even with a free slot
+	if err := request(PriorityBatch); !errors.Is(err, ErrLoadShed) {
+		t.Errorf("Expected the batch request to be shed but got %v", err)
+	}
+
+	// The request waiting for the rate limit doesn't hold the slot
+	paced := make(chan error, 1)
+	go func() { paced <- request(PriorityNormal) }()
+	time.Sleep(50 * time.Millisecond)
+
+	if !client.scheduler.tryAcquire() {
+		t.Error("Expected the slot to be free while waiting for the rate limit")
+	} else {
+		client.scheduler.release()
+	}
+
+	if err := <-paced; err != nil {
+		t.Error(err)
+	}
+
+	if *hits != 2 {
+		t.Errorf("Expected 2 requests sent but got %d", *hits)
+	}
+}
@@ -0,0 +1,123 @@
+package millennium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Record is a single Millennium record decoded without a predefined struct.
+// Each field is kept as raw JSON until it is scanned, so a []Record can be
+// passed to Get when the shape of the response is only known at runtime.
+type Record map[string]json.RawMessage
+
+// ScanStruct copies the record fields into the struct pointed by dst.
+//
+// Fields are matched by the `millennium` tag, then by the `json` tag and
+// finally by the snake_case form of the field name (CodFilial -> cod_filial),
+// falling back to a case insensitive comparison. Fields tagged with "-" and
+// unexported fields are ignored.
+func (r Record) ScanStruct(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("destination should be a non-nil pointer to a struct")
+	}
+
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("destination should point to a struct, got %s", v.Kind())
+	}
+
+	return r.scanValue(v)
+}
+
+func (r Record) scanValue(v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Embedded structs have their fields promoted, as encoding/json does
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && recordFieldTag(field) == "" {
+			if err := r.scanValue(v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := recordFieldTag(field)
+		if name == "-" {
+			continue
+		}
+
+		raw, ok := r.lookup(name, field.Name)
+		if !ok {
+			continue
+		}
+
+		if err := json.Unmarshal(raw, v.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("unable to scan field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// lookup finds the raw value for a struct field, trying the tag name first
+func (r Record) lookup(tag string, fieldName string) (json.RawMessage, bool) {
+	if tag != "" {
+		raw, ok := r[tag]
+		return raw, ok
+	}
+
+	if raw, ok := r[snakeCase(fieldName)]; ok {
+		return raw, true
+	}
+
+	for key, raw := range r {
+		if strings.EqualFold(key, fieldName) {
+			return raw, true
+		}
+	}
+
+	return nil, false
+}
+
+// recordFieldTag returns the record key defined by struct tags, if any
+func recordFieldTag(field reflect.StructField) string {
+	for _, key := range []string{"millennium", "json"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+// snakeCase converts a Go field name to the Millennium naming convention
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			// Start a new word unless it is part of an acronym like CNPJ
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
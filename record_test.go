@@ -0,0 +1,102 @@
+package millennium
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestRecordScanStruct(t *testing.T) {
+	type Base struct {
+		Filial int `json:"filial"`
+	}
+
+	type Target struct {
+		Base
+		CodFilial string
+		Nome      string `millennium:"nome_filial"`
+		CNPJ      string
+		Ignored   string `json:"-"`
+		Active    bool   `json:"ativo,omitempty"`
+		hidden    string
+	}
+
+	var r Record
+	body := []byte(`{"filial":1,"cod_filial":"001","nome_filial":"Matriz","cnpj":"123","Ignored":"x","ativo":true,"hidden":"x"}`)
+	if err := json.Unmarshal(body, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	var target Target
+	if err := r.ScanStruct(&target); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Target{
+		Base:      Base{Filial: 1},
+		CodFilial: "001",
+		Nome:      "Matriz",
+		CNPJ:      "123",
+		Active:    true,
+	}
+
+	if target != expected {
+		t.Errorf("Expected %+v but got %+v", expected, target)
+	}
+}
+
+func TestRecordScanStructErrors(t *testing.T) {
+	r := Record{"number": json.RawMessage(`"not a number"`)}
+
+	var number struct {
+		Number int
+	}
+
+	var notStruct int
+
+	cases := []struct {
+		Name string
+		Dst  interface{}
+	}{
+		{Name: "nil", Dst: nil},
+		{Name: "not pointer", Dst: number},
+		{Name: "not struct", Dst: &notStruct},
+		{Name: "wrong type", Dst: &number},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := r.ScanStruct(c.Dst); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestGetRecords(t *testing.T) {
+	client := NewTestClient(t)
+
+	var records []Record
+	count, err := client.Get("test.success.GET", url.Values{}, &records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 || len(records) != 1 {
+		t.Fatalf("Expected 1 record but got %v", len(records))
+	}
+
+	var res struct {
+		Number int
+		String string
+		Bool   bool
+	}
+
+	if err := records[0].ScanStruct(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Number != 1 || res.String != "test" || !res.Bool {
+		t.Errorf("Unexpected result %+v", res)
+	}
+}
@@ -0,0 +1,181 @@
+package millennium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Redacted replaces the values hidden by a RedactionPolicy
+const Redacted = "REDACTED"
+
+// redactedHeaders are the headers carrying credentials, always hidden
+var redactedHeaders = []string{"Authorization", "WTS-Authorization", "WTS-Session", "Cookie", "Set-Cookie"}
+
+// RedactionPolicy hides credentials and personal data (LGPD) from everything
+// the client writes out of the request path, like HAR captures, cURL exports,
+// the URLs logged by the client and the ones in a *ResponseError, and the
+// errors reported in a Sample.
+type RedactionPolicy struct {
+	fields []string
+}
+
+// NewRedactionPolicy returns a policy hiding the fields matching any of the
+// patterns. Patterns are case insensitive and accept path.Match wildcards,
+// like "cpf", "*_cnpj" or "telefone*".
+func NewRedactionPolicy(patterns ...string) *RedactionPolicy {
+	p := &RedactionPolicy{}
+	for _, pattern := range patterns {
+		p.fields = append(p.fields, strings.ToLower(pattern))
+	}
+	return p
+}
+
+// DefaultRedactionPolicy returns the policy hiding the credential fields
+// used by Millennium, which every client applies
+func DefaultRedactionPolicy() *RedactionPolicy {
+	return NewRedactionPolicy("session", "password", "senha", "token")
+}
+
+// WithRedaction adds the field patterns to the client redaction policy
+func WithRedaction(patterns ...string) Option {
+	return func(m *Millennium) {
+		m.redaction = NewRedactionPolicy(append(m.redaction.fields, patterns...)...)
+	}
+}
+
+// Match reports if the field should be hidden
+func (p *RedactionPolicy) Match(field string) bool {
+	field = strings.ToLower(field)
+	for _, pattern := range p.fields {
+		if ok, _ := path.Match(pattern, field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of a JSON body with the policy fields hidden,
+// at any depth. Bodies that are not JSON are returned untouched.
+func (p *RedactionPolicy) Redact(body []byte) []byte {
+	redacted, err := transformFields(body, p.Match, func(string, json.RawMessage) (json.RawMessage, error) {
+		return json.Marshal(Redacted)
+	})
+	if err != nil {
+		return body
+	}
+
+	return redacted
+}
+
+// redactHeader returns a copy of header with credentials replaced
+func (p *RedactionPolicy) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range redactedHeaders {
+		if redacted.Get(key) != "" {
+			redacted.Set(key, Redacted)
+		}
+	}
+	return redacted
+}
+
+// redactURL returns a copy of u with the query parameters of the policy hidden
+func (p *RedactionPolicy) redactURL(u *url.URL) *url.URL {
+	query := u.Query()
+
+	changed := false
+	for key := range query {
+		if p.Match(key) {
+			query.Set(key, Redacted)
+			changed = true
+		}
+	}
+
+	redacted := *u
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+
+	return &redacted
+}
+
+// queryParam matches the parameters of the URLs written in a log line. The
+// values are encoded, so they end at a separator, space or colon.
+var queryParam = regexp.MustCompile(`([?&])([^=&\s?]+)=([^&\s:]*)`)
+
+// redactLine returns line with the query parameters of the policy hidden
+// from the URLs written in it
+func (p *RedactionPolicy) redactLine(line string) string {
+	return queryParam.ReplaceAllStringFunc(line, func(param string) string {
+		match := queryParam.FindStringSubmatch(param)
+		key, err := url.QueryUnescape(match[2])
+		if err != nil || !p.Match(key) {
+			return param
+		}
+
+		return match[1] + match[2] + "=" + Redacted
+	})
+}
+
+// redactLogger wraps the retryablehttp logger, so the URLs it logs for every
+// request are redacted by the policy
+func (p *RedactionPolicy) redactLogger(logger interface{}) interface{} {
+	switch l := logger.(type) {
+	case retryablehttp.LeveledLogger:
+		return redactingLeveledLogger{logger: l, policy: p}
+	case retryablehttp.Logger:
+		return redactingLogger{logger: l, policy: p}
+	default:
+		return logger
+	}
+}
+
+// redactingLogger redacts the lines of a retryablehttp.Logger
+type redactingLogger struct {
+	logger retryablehttp.Logger
+	policy *RedactionPolicy
+}
+
+func (l redactingLogger) Printf(format string, args ...interface{}) {
+	l.logger.Printf("%s", l.policy.redactLine(fmt.Sprintf(format, args...)))
+}
+
+// redactingLeveledLogger redacts the string values of a
+// retryablehttp.LeveledLogger
+type redactingLeveledLogger struct {
+	logger retryablehttp.LeveledLogger
+	policy *RedactionPolicy
+}
+
+func (l redactingLeveledLogger) redact(keysAndValues []interface{}) []interface{} {
+	redacted := make([]interface{}, len(keysAndValues))
+	for i, v := range keysAndValues {
+		if s, ok := v.(string); ok {
+			v = l.policy.redactLine(s)
+		}
+		redacted[i] = v
+	}
+	return redacted
+}
+
+func (l redactingLeveledLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.Error(msg, l.redact(keysAndValues)...)
+}
+
+func (l redactingLeveledLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(msg, l.redact(keysAndValues)...)
+}
+
+func (l redactingLeveledLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Debug(msg, l.redact(keysAndValues)...)
+}
+
+func (l redactingLeveledLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.Warn(msg, l.redact(keysAndValues)...)
+}
@@ -0,0 +1,145 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactionPolicy(t *testing.T) {
+	p := NewRedactionPolicy("cpf", "*_cnpj", "Telefone*")
+
+	cases := []struct {
+		Field  string
+		Expect bool
+	}{
+		{Field: "cpf", Expect: true},
+		{Field: "CPF", Expect: true},
+		{Field: "cliente_cnpj", Expect: true},
+		{Field: "telefone_celular", Expect: true},
+		{Field: "nome", Expect: false},
+		{Field: "cnpj", Expect: false},
+	}
+
+	for _, c := range cases {
+		if p.Match(c.Field) != c.Expect {
+			t.Errorf("Expected Match(%s) to be %v", c.Field, c.Expect)
+		}
+	}
+
+	body := p.Redact([]byte(`{"nome":"Maria","cpf":"123","contatos":[{"telefone":"999"}],"valor":10.50}`))
+	expected := `{"nome":"Maria","cpf":"REDACTED","contatos":[{"telefone":"REDACTED"}],"valor":10.50}`
+	if string(body) != expected {
+		t.Errorf("Expected %s but got %s", expected, body)
+	}
+
+	if body := p.Redact([]byte("not json")); string(body) != "not json" {
+		t.Errorf("Expected body untouched but got %s", body)
+	}
+
+	header := p.redactHeader(http.Header{"Set-Cookie": {"ASP.NET_SessionId=abc"}, "Content-Type": {"application/json"}})
+	if header.Get("Set-Cookie") != Redacted || header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected only the cookie redacted but got %v", header)
+	}
+}
+
+func TestWithRedaction(t *testing.T) {
+	recorder := NewHARRecorder()
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithRedaction("cpf", "string"), WithHARRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := RequestMethod{
+		HTTPMethod: POST,
+		Method:     "test.success.POST",
+		Params:     url.Values{"cpf": []string{"12345678900"}},
+		Body:       []byte(`{"cpf":"12345678900","senha":"secret"}`),
+	}
+
+	curl, err := client.AsCurl(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(curl, "12345678900") || strings.Contains(curl, "secret") {
+		t.Errorf("Expected redacted cURL but got %s", curl)
+	}
+
+	var res interface{}
+	r.Response = &res
+	if err := client.Request(r); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := recorder.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The response field "string" holds "test" in the mock server
+	for _, secret := range []string{"12345678900", "secret", `"test"`} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("Expected %s to be redacted from HAR", secret)
+		}
+	}
+}
+
+func TestRedactedLogs(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Status int
+		Logged int
+	}{
+		{Name: "retried", Status: http.StatusInternalServerError, Logged: 2},
+		{Name: "rejected", Status: http.StatusBadRequest, Logged: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, _ := newCountingServer(t, c.Status, `{"error":{"code":400,"message":{"lang":"pt-BR","value":"Erro"}}}`)
+
+			var sampled string
+			hook := func(s Sample) { sampled = s.Error }
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRedaction("cpf"), WithRetryMax(1), WithRetryWaitMin(time.Millisecond), WithRetryWaitMax(time.Millisecond), WithSampleHook(hook))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var logs bytes.Buffer
+			client.Client.Logger = log.New(&logs, "", 0)
+
+			var r interface{}
+			_, err = client.Get("test", url.Values{"cpf": {"12345678900"}, "nome": {"Maria"}}, &r)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+
+			// The request and its retries are logged with the URL
+			if strings.Contains(logs.String(), "12345678900") || strings.Count(logs.String(), "cpf=REDACTED") != c.Logged || !strings.Contains(logs.String(), "nome=Maria") {
+				t.Errorf("Expected the logged URLs to be redacted but got %s", logs.String())
+			}
+
+			if strings.Contains(sampled, "12345678900") || strings.Contains(err.Error(), "12345678900") && !strings.Contains(sampled, "cpf=REDACTED") {
+				t.Errorf("Expected the sampled error to be redacted but got %s", sampled)
+			}
+
+			var resErr *ResponseError
+			if errors.As(err, &resErr) && (strings.Contains(resErr.URL, "12345678900") || !strings.Contains(resErr.URL, "cpf=REDACTED")) {
+				t.Errorf("Expected the error URL to be redacted but got %s", resErr.URL)
+			}
+
+			if c.Status == http.StatusBadRequest && resErr == nil {
+				t.Errorf("Expected *ResponseError but got %v", err)
+			}
+		})
+	}
+}
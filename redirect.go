@@ -0,0 +1,86 @@
+package millennium
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrRedirectNotAllowed is returned when Millennium redirects a request and
+// the client uses the RedirectError policy
+var ErrRedirectNotAllowed = errors.New("redirect not allowed")
+
+// RedirectPolicy defines how redirects sent by Millennium, or a proxy in
+// front of it, are handled
+type RedirectPolicy int
+
+// Redirect policies available for WithRedirectPolicy
+const (
+	// RedirectFollow follows redirects as net/http does, dropping the
+	// Authorization and Cookie headers when redirected to another domain,
+	// and the WTS-Authorization and WTS-Session headers when redirected to
+	// another host or port
+	RedirectFollow RedirectPolicy = iota
+
+	// RedirectFollowWithAuth follows redirects sending the credentials to
+	// the new location, even on another host or port
+	RedirectFollowWithAuth
+
+	// RedirectFollowWithoutAuth follows redirects without sending any
+	// credentials to the new location
+	RedirectFollowWithoutAuth
+
+	// RedirectError fails the request with ErrRedirectNotAllowed
+	RedirectError
+)
+
+// maxRedirects is the number of redirects followed by a request, as net/http
+const maxRedirects = 10
+
+// authHeaders are the headers carrying the credentials of a request
+var authHeaders = []string{"Authorization", "WTS-Authorization", "WTS-Session", "Cookie"}
+
+// wtsHeaders are the credentials of Millennium, unknown to net/http
+var wtsHeaders = []string{"WTS-Authorization", "WTS-Session"}
+
+// WithRedirectPolicy sets how redirects are handled, RedirectFollow by
+// default. Deployments redirecting /api to another host or port need
+// RedirectFollowWithAuth to keep the requests authenticated.
+func WithRedirectPolicy(policy RedirectPolicy) Option {
+	return func(m *Millennium) {
+		m.Client.HTTPClient.CheckRedirect = policy.checkRedirect
+	}
+}
+
+// checkRedirect is the http.Client CheckRedirect of the policy
+func (p RedirectPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if p == RedirectError {
+		return fmt.Errorf("%w: %s", ErrRedirectNotAllowed, req.URL.Redacted())
+	}
+
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	if p == RedirectFollow {
+		if req.URL.Host != via[0].URL.Host {
+			for _, header := range wtsHeaders {
+				req.Header.Del(header)
+			}
+		}
+		return nil
+	}
+
+	for _, header := range authHeaders {
+		switch p {
+		case RedirectFollowWithAuth:
+			if values := via[0].Header.Values(header); len(values) > 0 {
+				req.Header[http.CanonicalHeaderKey(header)] = values
+			}
+		case RedirectFollowWithoutAuth:
+			req.Header.Del(header)
+		}
+	}
+
+	return nil
+}
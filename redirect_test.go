@@ -0,0 +1,108 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRedirectPolicy(t *testing.T) {
+	var session, authorization atomic.Value
+	session.Store("")
+	authorization.Store("")
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session.Store(r.Header.Get("WTS-Session"))
+		authorization.Store(r.Header.Get("WTS-Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	}))
+	defer target.Close()
+
+	var redirects int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+			return
+		}
+
+		atomic.AddInt32(&redirects, 1)
+		http.Redirect(w, r, target.URL+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+	}))
+	defer origin.Close()
+
+	cases := []struct {
+		Name          string
+		Policy        RedirectPolicy
+		AuthType      AuthType
+		Session       string
+		Authorization string
+		Err           error
+	}{
+		{Name: "follow", Policy: RedirectFollow},
+		{Name: "follow stateless", Policy: RedirectFollow, AuthType: Stateless},
+		{Name: "follow with auth", Policy: RedirectFollowWithAuth, Session: "{00000000-0000-0000-0000-000000000000}"},
+		{Name: "follow stateless with auth", Policy: RedirectFollowWithAuth, AuthType: Stateless, Authorization: "TEST/TEST"},
+		{Name: "follow without auth", Policy: RedirectFollowWithoutAuth},
+		{Name: "error", Policy: RedirectError, Err: ErrRedirectNotAllowed},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			session.Store("")
+			authorization.Store("")
+			atomic.StoreInt32(&redirects, 0)
+
+			// RedirectFollow is the default policy
+			var opts []Option
+			if c.Policy != RedirectFollow {
+				opts = append(opts, WithRedirectPolicy(c.Policy))
+			}
+
+			client, err := NewClient(context.Background(), origin.URL, 5*time.Second, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			authType := c.AuthType
+			if authType == "" {
+				authType = Session
+			}
+
+			if err := client.Login("test", "test", authType); err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			_, err = client.Get("test", nil, &r)
+			if c.Err != nil {
+				if !errors.Is(err, c.Err) {
+					t.Errorf("Expected %v but got %v", c.Err, err)
+				}
+
+				if atomic.LoadInt32(&redirects) != 1 {
+					t.Errorf("Expected refused redirects not to be retried but got %d requests", redirects)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if session.Load() != c.Session {
+				t.Errorf("Expected session %q at the new location but got %q", c.Session, session.Load())
+			}
+
+			if authorization.Load() != c.Authorization {
+				t.Errorf("Expected authorization %q at the new location but got %q", c.Authorization, authorization.Load())
+			}
+		})
+	}
+}
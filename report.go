@@ -0,0 +1,186 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReportPollInterval is the time between polls of an asynchronous report,
+// when Millennium does not send a Retry-After header
+const ReportPollInterval = time.Second
+
+// ReportContentFields are the fields holding the base64 content of the
+// reports returned as JSON, in the order they are looked up
+var ReportContentFields = []string{"arquivo", "conteudo", "content", "base64"}
+
+// Report is the file generated by a Millennium report method
+type Report struct {
+	// ContentType is the media type of the file, detected from its content
+	// when Millennium does not tell it
+	ContentType string
+
+	// Name is the file name sent by Millennium, if any
+	Name string
+
+	// Body is the content of the file
+	Body io.Reader
+}
+
+// RunReport requests a report method using GET http method and returns the
+// generated file.
+// Files can be sent as the response body itself or as base64 in one of the
+// ReportContentFields of a JSON response. Asynchronous reports, answered
+// with 202 Accepted, are polled until ready, following the Location header
+// when sent and waiting for Retry-After or ReportPollInterval between polls.
+func (m *Millennium) RunReport(ctx context.Context, reportMethod string, params url.Values) (Report, error) {
+	if err := m.checkMethod(reportMethod); err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	err := m.onServer(ctx, func() (err error) {
+		report, err = m.runReport(ctx, reportMethod, params)
+		return err
+	})
+
+	return report, err
+}
+
+// runReport is RunReport once on the current server
+func (m *Millennium) runReport(ctx context.Context, reportMethod string, params url.Values) (Report, error) {
+	if err := m.ensureSession(); err != nil {
+		return Report{}, err
+	}
+
+	req, err := m.newRequest(ctx, RequestMethod{
+		HTTPMethod: GET,
+		Method:     reportMethod,
+		Params:     params,
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	for {
+		res, err := m.do(reportMethod, req)
+		if err != nil {
+			return Report{}, fmt.Errorf("unable to make the request to Millennium: %w", err)
+		}
+
+		body, err := readBody(res)
+		res.Body.Close()
+		if err != nil {
+			return Report{}, err
+		}
+
+		if res.StatusCode >= 400 {
+			return Report{}, m.responseError(res, body)
+		}
+
+		if res.StatusCode != http.StatusAccepted {
+			return decodeReport(res, body)
+		}
+
+		if location := res.Header.Get("Location"); location != "" {
+			u, err := req.URL.Parse(location)
+			if err != nil {
+				return Report{}, fmt.Errorf("invalid report location %q: %w", location, err)
+			}
+			req.URL = u
+		}
+
+		select {
+		case <-m.after(retryAfter(res, ReportPollInterval)):
+		case <-ctx.Done():
+			return Report{}, ctx.Err()
+		}
+	}
+}
+
+// after waits using the client clock, if set
+func (m *Millennium) after(d time.Duration) <-chan time.Time {
+	if m.clock != nil {
+		return m.clock.After(d)
+	}
+
+	return time.After(d)
+}
+
+// retryAfter returns the wait asked by the Retry-After header in seconds,
+// or fallback
+func retryAfter(res *http.Response, fallback time.Duration) time.Duration {
+	if wait, ok := parseRetryAfter(res); ok {
+		return wait
+	}
+
+	return fallback
+}
+
+func decodeReport(res *http.Response, body []byte) (Report, error) {
+	report := Report{ContentType: res.Header.Get("Content-Type")}
+
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		report.Name = params["filename"]
+	}
+
+	if !strings.Contains(report.ContentType, "json") {
+		if report.ContentType == "" {
+			report.ContentType = http.DetectContentType(body)
+		}
+
+		report.Body = bytes.NewReader(body)
+		return report, nil
+	}
+
+	content, err := reportContent(body)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report.ContentType = http.DetectContentType(content)
+	report.Body = bytes.NewReader(content)
+	return report, nil
+}
+
+// reportContent decodes the base64 content of a JSON report, either a
+// record or a Millennium response holding a single record
+func reportContent(body []byte) ([]byte, error) {
+	var res struct {
+		Value []Record `json:"value"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("unable to decode report: %w", err)
+	}
+
+	record := Record{}
+	if len(res.Value) > 0 {
+		record = res.Value[0]
+	} else if err := json.Unmarshal(body, &record); err != nil {
+		return nil, fmt.Errorf("unable to decode report: %w", err)
+	}
+
+	for _, field := range ReportContentFields {
+		raw, ok := record[field]
+		if !ok {
+			continue
+		}
+
+		var content Base64
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return nil, fmt.Errorf("unable to decode report field %s: %w", field, err)
+		}
+
+		return content, nil
+	}
+
+	return nil, errors.New("report response has no content")
+}
@@ -0,0 +1,120 @@
+package millennium
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunReport(t *testing.T) {
+	content := "%PDF-1.4 report"
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	cases := []struct {
+		Name        string
+		Handler     func(polls int32, w http.ResponseWriter, r *http.Request)
+		ContentType string
+		FileName    string
+	}{
+		{
+			Name: "file body",
+			Handler: func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Header().Set("Content-Disposition", `attachment; filename="vendas.pdf"`)
+				_, _ = io.WriteString(w, content)
+			},
+			ContentType: "application/pdf",
+			FileName:    "vendas.pdf",
+		},
+		{
+			Name: "base64 record",
+			Handler: func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, `{"value":[{"arquivo":"`+encoded+`"}]}`)
+			},
+			ContentType: "application/pdf",
+		},
+		{
+			Name: "asynchronous",
+			Handler: func(polls int32, w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/millenium/report.ready" {
+					w.Header().Set("Location", "/api/millenium/report.ready")
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+
+				if polls < 3 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, `{"content":"`+encoded+`"}`)
+			},
+			ContentType: "application/pdf",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var polls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.Handler(atomic.AddInt32(&polls, 1), w, r)
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := client.RunReport(context.Background(), "report.sales", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(report.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(body) != content {
+				t.Errorf("Expected body %q but got %q", content, body)
+			}
+
+			if report.ContentType != c.ContentType {
+				t.Errorf("Expected content type %q but got %q", c.ContentType, report.ContentType)
+			}
+
+			if report.Name != c.FileName {
+				t.Errorf("Expected file name %q but got %q", c.FileName, report.Name)
+			}
+		})
+	}
+}
+
+func TestRunReportCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.RunReport(ctx, "report.sales", nil); err != context.DeadlineExceeded {
+		t.Errorf("Expected %v but got %v", context.DeadlineExceeded, err)
+	}
+}
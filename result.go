@@ -0,0 +1,71 @@
+package millennium
+
+import (
+	"context"
+	"net/url"
+)
+
+// Result is the outcome of a request along with its decoded payload, for
+// callers logging and alerting on slow or retried Millennium calls
+type Result struct {
+	ResponseMeta
+
+	// Count is the total number of records reported by Millennium, set by
+	// GetResult
+	Count int
+}
+
+type attemptCountKey struct{}
+
+// withAttemptCount returns a copy of ctx counting the attempts of the
+// requests made with it into count
+func withAttemptCount(ctx context.Context, count *int) context.Context {
+	return context.WithValue(ctx, attemptCountKey{}, count)
+}
+
+func attemptCountFrom(ctx context.Context) *int {
+	count, _ := ctx.Value(attemptCountKey{}).(*int)
+	return count
+}
+
+// GetResult is GetCtx also returning the response metadata
+func (m *Millennium) GetResult(ctx context.Context, method string, params url.Values, response interface{}) (Result, error) {
+	var result Result
+
+	count, err := m.get(ctx, method, params, response, &result.ResponseMeta)
+	result.Count = count
+
+	return result, err
+}
+
+// PostResult is PostCtx also returning the response metadata
+func (m *Millennium) PostResult(ctx context.Context, method string, body []byte, response interface{}) (Result, error) {
+	var result Result
+
+	err := m.Request(RequestMethod{
+		HTTPMethod: POST,
+		Method:     method,
+		Params:     url.Values{},
+		Body:       body,
+		Response:   &response,
+		Meta:       &result.ResponseMeta,
+		Context:    ctx,
+	})
+
+	return result, err
+}
+
+// DeleteResult is DeleteCtx also returning the response metadata
+func (m *Millennium) DeleteResult(ctx context.Context, method string, params url.Values) (Result, error) {
+	var result Result
+
+	err := m.Request(RequestMethod{
+		HTTPMethod: DELETE,
+		Method:     method,
+		Params:     params,
+		Meta:       &result.ResponseMeta,
+		Context:    ctx,
+	})
+
+	return result, err
+}
@@ -0,0 +1,75 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResult(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "42")
+
+		// The first request of each method fails, to be retried
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":{"code":500,"message":{"lang":"pt-BR","value":"Erro"}}}`))
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"odata.count":3,"value":[{"number":1}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	var r interface{}
+	cases := []struct {
+		Name  string
+		Do    func() (Result, error)
+		Count int
+	}{
+		{Name: "get", Do: func() (Result, error) { return client.GetResult(context.Background(), "test", nil, &r) }, Count: 3},
+		{Name: "post", Do: func() (Result, error) { return client.PostResult(context.Background(), "test", []byte(`{}`), &r) }},
+		{Name: "delete", Do: func() (Result, error) { return client.DeleteResult(context.Background(), "test", nil) }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+
+			result, err := c.Do()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.StatusCode != http.StatusOK || result.Header.Get("X-Request-Id") != "42" {
+				t.Errorf("Unexpected status %d and header %v", result.StatusCode, result.Header)
+			}
+
+			if result.Retries != 1 {
+				t.Errorf("Expected 1 retry but got %d", result.Retries)
+			}
+
+			if result.Duration < 10*time.Millisecond {
+				t.Errorf("Expected the duration of the request but got %v", result.Duration)
+			}
+
+			if result.Count != c.Count {
+				t.Errorf("Expected count %d but got %d", c.Count, result.Count)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// RetryClassifier decides if a request to Millennium should be retried.
+// It receives the response, the error decoded from the response body, when
+// Millennium returned one, and the error from the transport.
+// Returning an error stops the retries and fails the request with it.
+type RetryClassifier interface {
+	Retryable(ctx context.Context, res *http.Response, resErr *ResponseError, err error) (bool, error)
+}
+
+// RetryClassifierFunc is an adapter to use ordinary functions as RetryClassifier
+type RetryClassifierFunc func(ctx context.Context, res *http.Response, resErr *ResponseError, err error) (bool, error)
+
+// Retryable calls f(ctx, res, resErr, err)
+func (f RetryClassifierFunc) Retryable(ctx context.Context, res *http.Response, resErr *ResponseError, err error) (bool, error) {
+	return f(ctx, res, resErr, err)
+}
+
+// DefaultRetryClassifier retries connection errors, 429 and 5xx responses,
+// as the default retryablehttp policy does
+var DefaultRetryClassifier RetryClassifier = RetryClassifierFunc(func(ctx context.Context, res *http.Response, _ *ResponseError, err error) (bool, error) {
+	return retryablehttp.DefaultRetryPolicy(ctx, res, err)
+})
+
+// ServerErrorRetryClassifier retries connection errors and 5xx responses
+// only, client errors like 400 validation failures and 429 are never retried.
+// Responses whose Millennium error code is a 4xx are not retried either, even
+// when sent with a 5xx status, as some methods report rejected data that way.
+// Use it with WithRetryClassifier.
+var ServerErrorRetryClassifier RetryClassifier = RetryClassifierFunc(func(ctx context.Context, res *http.Response, resErr *ResponseError, err error) (bool, error) {
+	if err == nil && res != nil && res.StatusCode < 500 {
+		return false, nil
+	}
+
+	if resErr != nil && resErr.Err.Code >= 400 && resErr.Err.Code < 500 {
+		return false, nil
+	}
+
+	return retryablehttp.DefaultRetryPolicy(ctx, res, err)
+})
+
+// checkRetry is the retryablehttp CheckRetry of the client, delegating the
+// decision to the configured RetryClassifier
+func (m *Millennium) checkRetry(ctx context.Context, res *http.Response, err error) (bool, error) {
+	if rec := attemptRecorderFrom(ctx); rec != nil {
+		rec.finish(res, err)
+	}
+
+	// Do not retry if the request was canceled or timed out
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	// Redirects refused by the redirect policy would be refused again
+	if errors.Is(err, ErrRedirectNotAllowed) {
+		return false, err
+	}
+
+	classifier := m.retryClassifier
+	if classifier == nil {
+		classifier = DefaultRetryClassifier
+	}
+
+	return classifier.Retryable(ctx, res, peekResponseError(res), err)
+}
+
+// peekResponseError decodes the Millennium error from the response body,
+// leaving the body available to be read again
+func peekResponseError(res *http.Response) *ResponseError {
+	if res == nil || res.StatusCode < 400 || res.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var resErr ResponseError
+	if err := json.Unmarshal(body, &resErr); err != nil {
+		return nil
+	}
+
+	return &resErr
+}
+
+// WithRetryMax sets the maximum number of retries, RetryMax by default.
+// Zero disables the retries.
+func WithRetryMax(retries int) Option {
+	return func(m *Millennium) {
+		m.Client.RetryMax = max(retries, 0)
+	}
+}
+
+// WithRetryWaitMin sets the minimum time waited between retries
+func WithRetryWaitMin(wait time.Duration) Option {
+	return func(m *Millennium) {
+		m.Client.RetryWaitMin = wait
+	}
+}
+
+// WithRetryWaitMax sets the maximum time waited between retries
+func WithRetryWaitMax(wait time.Duration) Option {
+	return func(m *Millennium) {
+		m.Client.RetryWaitMax = wait
+	}
+}
+
+// WithBackoff replaces the policy computing the time waited between retries,
+// exponential by default. retryablehttp.LinearJitterBackoff can be used to
+// spread the retries of many workers over flaky links.
+// The delay asked by throttled responses with Retry-After is always honored.
+func WithBackoff(backoff retryablehttp.Backoff) Option {
+	backoff = honorRetryAfter(backoff)
+
+	return func(m *Millennium) {
+		if m.clock != nil {
+			// WithClock waits in prepareRetry using its own backoff
+			m.backoff = backoff
+			return
+		}
+
+		m.Client.Backoff = backoff
+	}
+}
+
+// MaxRetryAfter caps the delay asked by Retry-After headers, so a
+// misconfigured gateway can't hold a request for hours
+var MaxRetryAfter = 2 * time.Minute
+
+// honorRetryAfter waits the delay asked by 429 and 503 responses with
+// Retry-After, instead of the one computed by backoff
+func honorRetryAfter(backoff retryablehttp.Backoff) retryablehttp.Backoff {
+	return func(waitMin, waitMax time.Duration, attempt int, res *http.Response) time.Duration {
+		if wait, ok := throttledFor(res); ok {
+			return wait
+		}
+
+		return backoff(waitMin, waitMax, attempt, res)
+	}
+}
+
+// throttledFor returns the delay asked by a 429 or 503 response, capped
+// by MaxRetryAfter
+func throttledFor(res *http.Response) (time.Duration, bool) {
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	wait, ok := parseRetryAfter(res)
+	if !ok {
+		return 0, false
+	}
+
+	return min(wait, MaxRetryAfter), true
+}
+
+// parseRetryAfter parses the Retry-After header, given in seconds or as an
+// http date
+func parseRetryAfter(res *http.Response) (time.Duration, bool) {
+	header := strings.TrimSpace(res.Header.Get("Retry-After"))
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(at), 0), true
+}
@@ -0,0 +1,282 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCountingServer returns a server that always responds with the given
+// status and body, counting how many requests it received
+func newCountingServer(t *testing.T, status int, body string) (*httptest.Server, *int32) {
+	var hits int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &hits
+}
+
+func TestRetryClassifier(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusInternalServerError, `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Registro duplicado"}}}`)
+
+	classifier := RetryClassifierFunc(func(ctx context.Context, res *http.Response, resErr *ResponseError, err error) (bool, error) {
+		if resErr != nil && resErr.Error() == "Registro duplicado" {
+			return false, nil
+		}
+		return DefaultRetryClassifier.Retryable(ctx, res, resErr, err)
+	})
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRetryClassifier(classifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	var r interface{}
+	_, err = client.Get("test", url.Values{}, &r)
+
+	var resErr *ResponseError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("Expected ResponseError but got %v", err)
+	}
+
+	if *hits != 1 {
+		t.Errorf("Expected a single attempt but got %d", *hits)
+	}
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusInternalServerError, `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Query error"}}}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.RetryWaitMin = time.Millisecond
+	client.Client.RetryWaitMax = time.Millisecond
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err == nil {
+		t.Error("Expected error")
+	}
+
+	if *hits != RetryMax+1 {
+		t.Errorf("Expected %d attempts but got %d", RetryMax+1, *hits)
+	}
+}
+
+// instantClock is a Clock that never waits
+type instantClock struct{}
+
+func (instantClock) Now() time.Time { return time.Now() }
+
+func (instantClock) After(time.Duration) <-chan time.Time { return time.After(0) }
+
+func TestRetryPolicyOptions(t *testing.T) {
+	cases := []struct {
+		Name     string
+		RetryMax int
+		Clock    bool
+		Hits     int32
+	}{
+		{Name: "disabled", RetryMax: 0, Hits: 1},
+		{Name: "negative", RetryMax: -1, Hits: 1},
+		{Name: "custom", RetryMax: 5, Hits: 6},
+		{Name: "with clock", RetryMax: 2, Clock: true, Hits: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, hits := newCountingServer(t, http.StatusInternalServerError, `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Query error"}}}`)
+
+			var waits []time.Duration
+			backoff := func(min, max time.Duration, attempt int, res *http.Response) time.Duration {
+				waits = append(waits, min, max)
+				return 0
+			}
+
+			opts := []Option{WithRetryMax(c.RetryMax), WithRetryWaitMin(2 * time.Millisecond), WithRetryWaitMax(3 * time.Millisecond)}
+			if c.Clock {
+				opts = append(opts, WithClock(instantClock{}))
+			}
+			opts = append(opts, WithBackoff(backoff))
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			if _, err := client.Get("test", url.Values{}, &r); err == nil {
+				t.Error("Expected error")
+			}
+
+			if *hits != c.Hits {
+				t.Errorf("Expected %d attempts but got %d", c.Hits, *hits)
+			}
+
+			if len(waits) != 2*int(c.Hits-1) {
+				t.Fatalf("Expected the backoff to be called %d times but got %d", c.Hits-1, len(waits)/2)
+			}
+
+			for i := 0; i < len(waits); i += 2 {
+				if waits[i] != 2*time.Millisecond || waits[i+1] != 3*time.Millisecond {
+					t.Errorf("Expected waits between 2ms and 3ms but got %s and %s", waits[i], waits[i+1])
+				}
+			}
+		})
+	}
+}
+
+func TestServerErrorRetryClassifier(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Status int
+		Body   string
+		Hits   int32
+	}{
+		{Name: "validation", Status: http.StatusBadRequest, Body: `{"error":{"code":400,"message":{"lang":"pt-BR","value":"Campo obrigatório"}}}`, Hits: 1},
+		{Name: "too many requests", Status: http.StatusTooManyRequests, Body: `{}`, Hits: 1},
+		{Name: "client error code", Status: http.StatusInternalServerError, Body: `{"error":{"code":400,"message":{"lang":"pt-BR","value":"Campo obrigatório"}}}`, Hits: 1},
+		{Name: "server error", Status: http.StatusInternalServerError, Body: `{"error":{"code":500,"message":{"lang":"pt-BR","value":"Query error"}}}`, Hits: RetryMax + 1},
+		{Name: "bad gateway", Status: http.StatusBadGateway, Body: `<html>Bad Gateway</html>`, Hits: RetryMax + 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, hits := newCountingServer(t, c.Status, c.Body)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRetryClassifier(ServerErrorRetryClassifier), WithRetryWaitMin(time.Millisecond), WithRetryWaitMax(time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			if _, err := client.Get("test", url.Values{}, &r); err == nil {
+				t.Error("Expected error")
+			}
+
+			if *hits != c.Hits {
+				t.Errorf("Expected %d attempts but got %d", c.Hits, *hits)
+			}
+		})
+	}
+}
+
+// recordingClock is a Clock that never waits, recording the waits asked
+type recordingClock struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *recordingClock) Now() time.Time { return time.Now() }
+
+func (c *recordingClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.waits = append(c.waits, d)
+	return time.After(0)
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Status     int
+		RetryAfter string
+		Wait       time.Duration
+		Throttled  bool
+	}{
+		{Name: "too many requests", Status: http.StatusTooManyRequests, RetryAfter: "7", Wait: 7 * time.Second, Throttled: true},
+		{Name: "unavailable", Status: http.StatusServiceUnavailable, RetryAfter: "3", Wait: 3 * time.Second, Throttled: true},
+		{Name: "http date", Status: http.StatusTooManyRequests, RetryAfter: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), Wait: time.Minute, Throttled: true},
+		{Name: "capped", Status: http.StatusTooManyRequests, RetryAfter: "86400", Wait: MaxRetryAfter, Throttled: true},
+		{Name: "without header", Status: http.StatusServiceUnavailable, Wait: time.Millisecond},
+		{Name: "not throttled", Status: http.StatusInternalServerError, RetryAfter: "7", Wait: time.Millisecond},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if c.RetryAfter != "" {
+					w.Header().Set("Retry-After", c.RetryAfter)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(c.Status)
+				_, _ = w.Write([]byte(`{"error":{"code":0,"message":{"lang":"pt-BR","value":"Aguarde"}}}`))
+			}))
+			t.Cleanup(server.Close)
+
+			clock := &recordingClock{}
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithRetryMax(1), WithRetryWaitMin(time.Millisecond), WithRetryWaitMax(time.Millisecond), WithClock(clock))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			_, err = client.Get("test", url.Values{}, &r)
+
+			if errors.Is(err, ErrThrottled) != c.Throttled {
+				t.Errorf("Expected errors.Is(ErrThrottled) to be %v for %v", c.Throttled, err)
+			}
+
+			if len(clock.waits) != 1 || clock.waits[0] < c.Wait-time.Second || clock.waits[0] > c.Wait {
+				t.Errorf("Expected to wait %s but got %v", c.Wait, clock.waits)
+			}
+
+			var retryErr *RetryError
+			if !errors.As(err, &retryErr) {
+				t.Fatalf("Expected RetryError but got %v", err)
+			}
+
+			if last := retryErr.Attempts[len(retryErr.Attempts)-1]; c.Throttled && last.RetryAfter == 0 {
+				t.Error("Expected the delay asked in the last attempt")
+			}
+		})
+	}
+}
+
+func TestThrottledWithoutRetries(t *testing.T) {
+	cases := []struct {
+		Name string
+		Opt  Option
+	}{
+		{Name: "retries disabled", Opt: WithRetryMax(0)},
+		{Name: "not retryable", Opt: WithRetryClassifier(ServerErrorRetryClassifier)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server, hits := newCountingServer(t, http.StatusTooManyRequests, `{"error":{"code":429,"message":{"lang":"pt-BR","value":"Aguarde"}}}`)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, c.Opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			_, err = client.Get("test", url.Values{}, &r)
+
+			if !errors.Is(err, ErrThrottled) || errors.Is(err, ErrServerError) {
+				t.Errorf("Expected a throttled error but got %v", err)
+			}
+
+			if *hits != 1 {
+				t.Errorf("Expected a single attempt but got %d", *hits)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package millennium
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode is how a value exactly between two results is rounded
+type RoundingMode int
+
+// Rounding modes used by Millennium
+const (
+	// RoundHalfUp rounds ties away from zero, like 2.345 to 2.35 and
+	// -2.345 to -2.35
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds ties to the even digit, like 2.345 to 2.34 and
+	// 2.355 to 2.36
+	RoundHalfEven
+)
+
+func (mode RoundingMode) String() string {
+	switch mode {
+	case RoundHalfUp:
+		return "half-up"
+	case RoundHalfEven:
+		return "half-even"
+	}
+
+	return fmt.Sprintf("RoundingMode(%d)", int(mode))
+}
+
+// Rounding is the number of decimal places and the rounding mode of a field
+type Rounding struct {
+	Places int
+	Mode   RoundingMode
+}
+
+// DefaultRounding is used for the fields not found in RoundingFields
+var DefaultRounding = Rounding{Places: 2, Mode: RoundHalfUp}
+
+// RoundingFields are the rounding rules of the fields, matched ignoring
+// case, that differ from DefaultRounding in the Millennium configuration,
+// like {"quantidade": {Places: 3, Mode: RoundHalfEven}}
+var RoundingFields = map[string]Rounding{}
+
+// RoundingFor returns the rounding of a field
+func RoundingFor(field string) Rounding {
+	for name, rounding := range RoundingFields {
+		if strings.EqualFold(name, field) {
+			return rounding
+		}
+	}
+
+	return DefaultRounding
+}
+
+// Round rounds v to the decimal places of r.
+// v is read as its shortest decimal representation, so 2.675 is rounded as
+// written instead of as the 2.67499999... stored in the float, like
+// Millennium does with the values it receives.
+// NaN and infinities are returned unchanged.
+func (r Rounding) Round(v float64) float64 {
+	if !finite(v) {
+		return v
+	}
+
+	return r.round(decimal(v))
+}
+
+// Mul returns a × b rounded, like the total of an item (quantity × price)
+// or an amount converted to another currency (amount × rate), computed
+// without the float errors of a × b. With NaN or infinite operands the
+// result is the float a × b, NaN or infinite as well.
+func (r Rounding) Mul(a, b float64) float64 {
+	if !finite(a, b) {
+		return a * b
+	}
+
+	return r.round(new(big.Rat).Mul(decimal(a), decimal(b)))
+}
+
+// Sum returns the sum of values rounded, like the total of an order,
+// computed without the float errors of adding them one by one. With NaN or
+// infinite values the result is their float sum, NaN or infinite as well.
+func (r Rounding) Sum(values ...float64) float64 {
+	if !finite(values...) {
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	}
+
+	sum := new(big.Rat)
+	for _, v := range values {
+		sum.Add(sum, decimal(v))
+	}
+
+	return r.round(sum)
+}
+
+// round rounds the exact value x
+func (r Rounding) round(x *big.Rat) float64 {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(r.Places, 0))), nil)
+	scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+
+	// scaled = quotient + remainder / denominator, truncated towards zero
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// Compare twice the remainder with the denominator to find the ties
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+
+	switch cmp := half.Cmp(scaled.Denom()); {
+	case cmp > 0, cmp == 0 && (r.Mode == RoundHalfUp || quotient.Bit(0) == 1):
+		quotient.Add(quotient, big.NewInt(int64(remainder.Sign())))
+	}
+
+	f, _ := new(big.Rat).SetFrac(quotient, scale).Float64()
+	return f
+}
+
+// finite reports if none of values is NaN or infinite
+func finite(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decimal returns the shortest decimal representation of the finite v as an
+// exact value
+func decimal(v float64) *big.Rat {
+	x, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'f', -1, 64))
+	return x
+}
@@ -0,0 +1,92 @@
+package millennium
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestRounding(t *testing.T) {
+	halfUp := Rounding{Places: 2, Mode: RoundHalfUp}
+	halfEven := Rounding{Places: 2, Mode: RoundHalfEven}
+
+	cases := []struct {
+		Name     string
+		Got      float64
+		Expected float64
+	}{
+		{Name: "half-up tie", Got: halfUp.Round(2.345), Expected: 2.35},
+		{Name: "half-up negative tie", Got: halfUp.Round(-2.345), Expected: -2.35},
+		{Name: "half-up below tie", Got: halfUp.Round(2.3449), Expected: 2.34},
+		{Name: "half-up float error", Got: halfUp.Round(2.675), Expected: 2.68},
+		{Name: "half-even tie to even", Got: halfEven.Round(2.345), Expected: 2.34},
+		{Name: "half-even tie to odd", Got: halfEven.Round(2.355), Expected: 2.36},
+		{Name: "half-even negative tie", Got: halfEven.Round(-2.345), Expected: -2.34},
+		{Name: "half-even above tie", Got: halfEven.Round(2.3451), Expected: 2.35},
+		{Name: "no places", Got: Rounding{Mode: RoundHalfEven}.Round(2.5), Expected: 2},
+		{Name: "item total", Got: halfUp.Mul(3, 19.995), Expected: 59.99},
+		{Name: "item total half-even", Got: halfEven.Mul(3, 0.125), Expected: 0.38},
+		{Name: "currency conversion", Got: halfUp.Mul(1.1, 5.4321), Expected: 5.98},
+		{Name: "sum", Got: halfUp.Sum(0.1, 0.2, 0.005), Expected: 0.31},
+		{Name: "empty sum", Got: halfUp.Sum(), Expected: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if c.Got != c.Expected {
+				t.Errorf("Expected %v but got %v", c.Expected, c.Got)
+			}
+		})
+	}
+}
+
+func TestRoundingNotFinite(t *testing.T) {
+	halfUp := Rounding{Places: 2, Mode: RoundHalfUp}
+
+	cases := []struct {
+		Name string
+		Got  float64
+		NaN  bool
+		Inf  int
+	}{
+		{Name: "round NaN", Got: halfUp.Round(math.NaN()), NaN: true},
+		{Name: "round infinity", Got: halfUp.Round(math.Inf(1)), Inf: 1},
+		{Name: "mul NaN", Got: halfUp.Mul(2, math.NaN()), NaN: true},
+		{Name: "mul infinity", Got: halfUp.Mul(-2, math.Inf(1)), Inf: -1},
+		{Name: "sum NaN", Got: halfUp.Sum(1.5, math.NaN()), NaN: true},
+		{Name: "sum infinity", Got: halfUp.Sum(1.5, math.Inf(-1)), Inf: -1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if c.NaN && !math.IsNaN(c.Got) || c.Inf != 0 && !math.IsInf(c.Got, c.Inf) {
+				t.Errorf("Expected NaN %v or infinity %d but got %v", c.NaN, c.Inf, c.Got)
+			}
+		})
+	}
+}
+
+func TestRoundingFor(t *testing.T) {
+	defer func(fields map[string]Rounding) { RoundingFields = fields }(RoundingFields)
+	RoundingFields = map[string]Rounding{"quantidade": {Places: 3, Mode: RoundHalfEven}}
+
+	cases := []struct {
+		Field    string
+		Expected Rounding
+	}{
+		{Field: "Quantidade", Expected: Rounding{Places: 3, Mode: RoundHalfEven}},
+		{Field: "preco", Expected: DefaultRounding},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Field, func(t *testing.T) {
+			if got := RoundingFor(c.Field); got != c.Expected {
+				t.Errorf("Expected %+v but got %+v", c.Expected, got)
+			}
+		})
+	}
+
+	if got := fmt.Sprint(RoundHalfEven); got != "half-even" {
+		t.Errorf("Expected half-even but got %s", got)
+	}
+}
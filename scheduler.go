@@ -0,0 +1,230 @@
+package millennium
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrLoadShed is returned by requests shed by WithLoadShedding
+var ErrLoadShed = errors.New("request shed under load")
+
+// Priority of a request when the client is configured with a scheduler.
+// Requests with higher priority are sent first.
+type Priority int
+
+// Request priorities
+const (
+	PriorityBatch       Priority = -1
+	PriorityNormal      Priority = 0
+	PriorityInteractive Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx carrying the priority of the requests made with it
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// priorityFrom returns the priority carried by ctx, PriorityNormal if none
+func priorityFrom(ctx context.Context) Priority {
+	priority, _ := ctx.Value(priorityKey{}).(Priority)
+	return priority
+}
+
+// WithScheduler limits the client to concurrency simultaneous requests.
+// Waiting requests are sent by priority, so interactive lookups jump ahead of
+// batch synchronizations sharing the same client.
+func WithScheduler(concurrency int) Option {
+	return func(m *Millennium) {
+		m.scheduler = newScheduler(concurrency)
+	}
+}
+
+// WithMaxConcurrentRequests allows at most n requests in flight, from the
+// moment they are sent until their response body is closed, so goroutines
+// sharing the client stay within the ERP connection budget. The other
+// requests queue by priority, as with WithScheduler; WithMaxQueuedRequests
+// and WithLoadShedding reject them instead.
+func WithMaxConcurrentRequests(n int) Option {
+	return WithScheduler(n)
+}
+
+// WithMaxQueuedRequests rejects with ErrLoadShed the requests arriving when
+// n requests are already waiting for a slot of WithMaxConcurrentRequests or
+// WithScheduler. With zero, requests never wait.
+func WithMaxQueuedRequests(n int) Option {
+	return func(m *Millennium) {
+		m.maxQueued = &n
+	}
+}
+
+// WithLoadShedding makes the requests with priority at or below the given
+// one fail right away with ErrLoadShed when the scheduler set by
+// WithScheduler has no free slot, or the rate limit of WithRateLimit has
+// no token available, instead of waiting for one. Under
+// overload batch jobs then back off, while interactive requests queue and
+// keep a predictable latency.
+func WithLoadShedding(priority Priority) Option {
+	return func(m *Millennium) {
+		m.shedPriority = &priority
+	}
+}
+
+// sheds reports if requests with priority are shed under load
+func (m *Millennium) sheds(priority Priority) bool {
+	return m.shedPriority != nil && priority <= *m.shedPriority
+}
+
+// schedule waits for a scheduler slot for a request bound to ctx, or fails
+// with ErrLoadShed if the request is shed
+func (m *Millennium) schedule(ctx context.Context) error {
+	priority := priorityFrom(ctx)
+	if !m.sheds(priority) {
+		queue := -1
+		if m.maxQueued != nil {
+			queue = *m.maxQueued
+		}
+
+		return m.scheduler.acquireQueued(ctx, priority, queue)
+	}
+
+	if !m.scheduler.tryAcquire() {
+		return ErrLoadShed
+	}
+
+	return nil
+}
+
+// scheduler hands out a fixed number of slots to requests by priority
+type scheduler struct {
+	mu      sync.Mutex
+	slots   int
+	running int
+	seq     uint64
+	queue   waitQueue
+}
+
+func newScheduler(slots int) *scheduler {
+	if slots < 1 {
+		slots = 1
+	}
+
+	return &scheduler{slots: slots}
+}
+
+// acquire waits for a free slot, until ctx is done
+func (s *scheduler) acquire(ctx context.Context, priority Priority) error {
+	return s.acquireQueued(ctx, priority, -1)
+}
+
+// acquireQueued is acquire failing with ErrLoadShed when maxQueue requests
+// are already waiting, negative for no limit
+func (s *scheduler) acquireQueued(ctx context.Context, priority Priority, maxQueue int) error {
+	s.mu.Lock()
+	if s.running < s.slots && len(s.queue) == 0 {
+		s.running++
+		s.mu.Unlock()
+		return nil
+	}
+
+	if maxQueue >= 0 && len(s.queue) >= maxQueue {
+		s.mu.Unlock()
+		return ErrLoadShed
+	}
+
+	s.seq++
+	w := &waiter{ready: make(chan struct{}), priority: priority, seq: s.seq}
+	heap.Push(&s.queue, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		granted := w.index < 0
+		if !granted {
+			heap.Remove(&s.queue, w.index)
+		}
+		s.mu.Unlock()
+
+		// The slot was handed over while giving up, pass it to the next one
+		if granted {
+			s.release()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// tryAcquire takes a free slot without waiting, reporting false if there
+// is none or other requests are already waiting
+func (s *scheduler) tryAcquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running < s.slots && len(s.queue) == 0 {
+		s.running++
+		return true
+	}
+
+	return false
+}
+
+// release frees a slot, handing it to the waiter with the highest priority
+func (s *scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) > 0 {
+		w := heap.Pop(&s.queue).(*waiter)
+		close(w.ready)
+		return
+	}
+
+	s.running--
+}
+
+type waiter struct {
+	ready    chan struct{}
+	priority Priority
+	seq      uint64
+	index    int
+}
+
+// waitQueue is a heap of waiters ordered by priority and arrival
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
@@ -0,0 +1,243 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSchedulerPriority(t *testing.T) {
+	s := newScheduler(1)
+	if err := s.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan Priority, 3)
+	start := func(priority Priority) {
+		go func() {
+			if err := s.acquire(context.Background(), priority); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- priority
+			s.release()
+		}()
+
+		// Wait the waiter to be queued to keep the arrival order
+		for {
+			s.mu.Lock()
+			queued := s.queue.hasPriority(priority)
+			s.mu.Unlock()
+			if queued {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	start(PriorityBatch)
+	start(PriorityNormal)
+	start(PriorityInteractive)
+
+	s.release()
+
+	expected := []Priority{PriorityInteractive, PriorityNormal, PriorityBatch}
+	for _, p := range expected {
+		if got := <-order; got != p {
+			t.Errorf("Expected priority %d but got %d", p, got)
+		}
+	}
+}
+
+func (q waitQueue) hasPriority(priority Priority) bool {
+	for _, w := range q {
+		if w.priority == priority {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := newScheduler(1)
+	if err := s.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := s.acquire(ctx, PriorityInteractive); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded but got %v", err)
+	}
+
+	s.release()
+
+	if s.running != 0 || len(s.queue) != 0 {
+		t.Errorf("Expected free scheduler but got %d running and %d queued", s.running, len(s.queue))
+	}
+}
+
+func TestWithScheduler(t *testing.T) {
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithScheduler(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	for i := 0; i < 3; i++ {
+		err := client.Request(RequestMethod{
+			HTTPMethod: GET,
+			Method:     "test.success.GET",
+			Response:   &r,
+			Priority:   PriorityInteractive,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadShedding(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/test.slow" {
+			started <- struct{}{}
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithScheduler(1), WithLoadShedding(PriorityBatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(method string, priority Priority) error {
+		var r interface{}
+		return client.Request(RequestMethod{HTTPMethod: GET, Method: method, Response: &r, Priority: priority})
+	}
+
+	// The slow request takes the only slot
+	slow := make(chan error, 1)
+	go func() { slow <- request("test.slow", PriorityNormal) }()
+	<-started
+
+	if err := request("test", PriorityBatch); !errors.Is(err, ErrLoadShed) {
+		t.Errorf("Expected the batch request to be shed but got %v", err)
+	}
+
+	queued := make(chan error, 1)
+	go func() { queued <- request("test", PriorityInteractive) }()
+
+	select {
+	case err := <-queued:
+		t.Fatalf("Expected the interactive request to wait but got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	for _, done := range []chan error{slow, queued} {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := request("test", PriorityBatch); err != nil {
+		t.Errorf("Expected the batch request to be sent with a free slot but got %v", err)
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Opts     []Option
+		Requests int
+		Shed     int
+	}{
+		{Name: "queued", Opts: []Option{WithMaxConcurrentRequests(2)}, Requests: 5},
+		{Name: "queue limit", Opts: []Option{WithMaxConcurrentRequests(2), WithMaxQueuedRequests(1)}, Requests: 5, Shed: 2},
+		{Name: "never queued", Opts: []Option{WithMaxQueuedRequests(0), WithMaxConcurrentRequests(2)}, Requests: 5, Shed: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			release := make(chan struct{})
+			started := make(chan struct{}, c.Requests)
+
+			var mu sync.Mutex
+			inFlight, peak := 0, 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				inFlight++
+				peak = max(peak, inFlight)
+				mu.Unlock()
+
+				started <- struct{}{}
+				<-release
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, c.Opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := make(chan error, c.Requests)
+			for i := 0; i < c.Requests; i++ {
+				go func() {
+					var r interface{}
+					_, err := client.Get("test", url.Values{}, &r)
+					errs <- err
+				}()
+			}
+
+			// Let the slots fill and the rest queue or be shed
+			<-started
+			<-started
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+
+			shed := 0
+			for i := 0; i < c.Requests; i++ {
+				err := <-errs
+				switch {
+				case errors.Is(err, ErrLoadShed):
+					shed++
+				case err != nil:
+					t.Error(err)
+				}
+			}
+
+			if shed != c.Shed {
+				t.Errorf("Expected %d requests shed but got %d", c.Shed, shed)
+			}
+
+			if peak != 2 {
+				t.Errorf("Expected at most 2 requests in flight but got %d", peak)
+			}
+		})
+	}
+}
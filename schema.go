@@ -0,0 +1,205 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldType is the JSON type expected for a field of a Schema
+type FieldType string
+
+// JSON types accepted by a Schema
+const (
+	FieldString  FieldType = "string"
+	FieldNumber  FieldType = "number"
+	FieldBoolean FieldType = "boolean"
+	FieldObject  FieldType = "object"
+	FieldArray   FieldType = "array"
+)
+
+// SchemaField describes a field of a request body
+type SchemaField struct {
+	Name string
+
+	// Type is the expected JSON type, any type is accepted when empty
+	Type FieldType
+
+	// Required fields should be present and not null
+	Required bool
+
+	// Schema validates the nested object, or each object of an array
+	Schema *Schema
+}
+
+// Schema describes the body accepted by a Millennium method, so invalid
+// POST, PUT and PATCH bodies are rejected before being sent. It is set for a method with
+// the Schema field of MethodConfig.
+type Schema struct {
+	Fields []SchemaField
+
+	// AllowUnknown accepts fields not listed in Fields
+	AllowUnknown bool
+}
+
+// FieldError describes a field that does not match a Schema
+type FieldError struct {
+	// Field is the path of the field, like itens[0].produto
+	Field   string
+	Message string
+}
+
+// SchemaError is returned when a request body does not match the schema of
+// its method
+type SchemaError struct {
+	Method string
+	Fields []FieldError
+}
+
+func (e *SchemaError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		problems[i] = fmt.Sprintf("%s: %s", field.Field, field.Message)
+	}
+
+	return fmt.Sprintf("invalid body for method %s: %s", e.Method, strings.Join(problems, "; "))
+}
+
+// Validate checks body against the schema. The body should be a JSON
+// object or an array of objects.
+func (s *Schema) Validate(body []byte) []FieldError {
+	return s.validate(body, false)
+}
+
+// ValidatePartial checks a partial body, like the ones sent by PATCH,
+// against the schema. Missing required fields are accepted.
+func (s *Schema) ValidatePartial(body []byte) []FieldError {
+	return s.validate(body, true)
+}
+
+func (s *Schema) validate(body []byte, partial bool) []FieldError {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return []FieldError{{Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var errs []FieldError
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		s.validateObject("", v, partial, &errs)
+	case []interface{}:
+		for i, item := range v {
+			s.validateItem(fmt.Sprintf("[%d]", i), item, partial, &errs)
+		}
+	default:
+		errs = append(errs, FieldError{Message: fmt.Sprintf("expected object but got %s", jsonType(doc))})
+	}
+
+	return errs
+}
+
+func (s *Schema) validateItem(path string, value interface{}, partial bool, errs *[]FieldError) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf("expected object but got %s", jsonType(value))})
+		return
+	}
+
+	s.validateObject(path, object, partial, errs)
+}
+
+func (s *Schema) validateObject(path string, object map[string]interface{}, partial bool, errs *[]FieldError) {
+	known := make(map[string]bool, len(s.Fields))
+
+	for _, field := range s.Fields {
+		known[field.Name] = true
+		fieldPath := joinFieldPath(path, field.Name)
+
+		value, ok := object[field.Name]
+		if !ok || value == nil {
+			if field.Required && !partial {
+				*errs = append(*errs, FieldError{Field: fieldPath, Message: "missing required field"})
+			}
+			continue
+		}
+
+		if field.Type != "" && jsonType(value) != field.Type {
+			*errs = append(*errs, FieldError{Field: fieldPath, Message: fmt.Sprintf("expected %s but got %s", field.Type, jsonType(value))})
+			continue
+		}
+
+		if field.Schema == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			field.Schema.validateObject(fieldPath, v, partial, errs)
+		case []interface{}:
+			for i, item := range v {
+				field.Schema.validateItem(fmt.Sprintf("%s[%d]", fieldPath, i), item, partial, errs)
+			}
+		}
+	}
+
+	if s.AllowUnknown {
+		return
+	}
+
+	var unknown []string
+	for name := range object {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		*errs = append(*errs, FieldError{Field: joinFieldPath(path, name), Message: "unknown field"})
+	}
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// jsonType returns the FieldType of a value decoded with UseNumber
+func jsonType(value interface{}) FieldType {
+	switch value.(type) {
+	case string:
+		return FieldString
+	case json.Number:
+		return FieldNumber
+	case bool:
+		return FieldBoolean
+	case map[string]interface{}:
+		return FieldObject
+	case []interface{}:
+		return FieldArray
+	default:
+		return "null"
+	}
+}
+
+// validateBody checks a request body against the schema configured for
+// method, accepting missing required fields on partial bodies
+func (m *Millennium) validateBody(method string, body []byte, partial bool) error {
+	schema := m.methodConfig(method).Schema
+	if schema == nil {
+		return nil
+	}
+
+	if errs := schema.validate(body, partial); len(errs) > 0 {
+		return &SchemaError{Method: method, Fields: errs}
+	}
+
+	return nil
+}
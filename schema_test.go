@@ -0,0 +1,116 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "cod_pedido", Type: FieldString, Required: true},
+			{Name: "total", Type: FieldNumber},
+			{Name: "itens", Type: FieldArray, Required: true, Schema: &Schema{
+				Fields: []SchemaField{
+					{Name: "produto", Type: FieldString, Required: true},
+					{Name: "quantidade", Type: FieldNumber},
+				},
+			}},
+			{Name: "cliente", Type: FieldObject, Schema: &Schema{AllowUnknown: true, Fields: []SchemaField{
+				{Name: "nome", Type: FieldString, Required: true},
+			}}},
+		},
+	}
+
+	cases := []struct {
+		Name     string
+		Body     string
+		Expected []FieldError
+	}{
+		{
+			Name: "valid",
+			Body: `{"cod_pedido":"1","total":10.5,"itens":[{"produto":"A","quantidade":2}],"cliente":{"nome":"X","extra":1}}`,
+		},
+		{
+			Name: "valid array",
+			Body: `[{"cod_pedido":"1","itens":[]},{"cod_pedido":"2","itens":[],"total":null}]`,
+		},
+		{
+			Name: "missing and unknown",
+			Body: `{"total":"10","itens":[{"quantidade":1},2],"desconto":1,"cliente":{}}`,
+			Expected: []FieldError{
+				{Field: "cod_pedido", Message: "missing required field"},
+				{Field: "total", Message: "expected number but got string"},
+				{Field: "itens[0].produto", Message: "missing required field"},
+				{Field: "itens[1]", Message: "expected object but got number"},
+				{Field: "cliente.nome", Message: "missing required field"},
+				{Field: "desconto", Message: "unknown field"},
+			},
+		},
+		{
+			Name:     "not an object",
+			Body:     `"pedido"`,
+			Expected: []FieldError{{Message: "expected object but got string"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			errs := schema.Validate([]byte(c.Body))
+			if !reflect.DeepEqual(errs, c.Expected) {
+				t.Errorf("Expected %+v but got %+v", c.Expected, errs)
+			}
+		})
+	}
+
+	partial := schema.ValidatePartial([]byte(`{"total":"10","itens":[{"quantidade":1}]}`))
+	if !reflect.DeepEqual(partial, []FieldError{{Field: "total", Message: "expected number but got string"}}) {
+		t.Errorf("Unexpected partial validation %+v", partial)
+	}
+
+	if errs := schema.Validate([]byte(`{`)); len(errs) != 1 {
+		t.Errorf("Expected invalid JSON error but got %+v", errs)
+	}
+}
+
+func TestSchemaValidationBeforeSend(t *testing.T) {
+	server, hits := newCountingServer(t, http.StatusOK, `{}`)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Configure("pedido_venda.inclui", MethodConfig{Schema: &Schema{
+		Fields: []SchemaField{{Name: "cod_pedido", Type: FieldString, Required: true}},
+	}})
+
+	var res interface{}
+	err = client.Post("pedido_venda.inclui", []byte(`{"pedido":"1"}`), &res)
+
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected SchemaError but got %v", err)
+	}
+
+	if schemaErr.Method != "pedido_venda.inclui" || len(schemaErr.Fields) != 2 {
+		t.Errorf("Unexpected error %v", schemaErr)
+	}
+
+	if atomic.LoadInt32(hits) != 0 {
+		t.Errorf("Expected no request but got %d", *hits)
+	}
+
+	if err := client.Post("pedido_venda.inclui", []byte(`{"cod_pedido":"1"}`), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(hits) != 1 {
+		t.Errorf("Expected 1 request but got %d", *hits)
+	}
+}
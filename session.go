@@ -0,0 +1,270 @@
+package millennium
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithLazyLogin makes Login with Session authentication only store the
+// credentials. The session is requested on the first request, once, even if
+// many requests start at the same time, so creating many clients at startup
+// does not stampede the login endpoint.
+func WithLazyLogin() Option {
+	return func(m *Millennium) {
+		m.lazyLogin = true
+	}
+}
+
+// WithSessionMaxAge renews the WTS session once it is older than maxAge.
+// The new session is requested by the first request after maxAge, so it
+// should be shorter than the server session expiration, avoiding sessions
+// that expire in the middle of long synchronizations.
+func WithSessionMaxAge(maxAge time.Duration) Option {
+	return func(m *Millennium) {
+		m.login.maxAge = maxAge
+	}
+}
+
+// sessionLoginState tracks a pending lazy login and the one in progress
+type sessionLoginState struct {
+	mu      sync.Mutex
+	pending bool
+	call    *sharedCall
+
+	// at is when the current session was created, renewed after maxAge
+	at     time.Time
+	maxAge time.Duration
+
+	// expiresAt is the expiration sent by the login response, if any
+	expiresAt time.Time
+}
+
+// SessionRefreshMargin is how long before the session expiration it is
+// renewed, so requests in progress are not rejected
+const SessionRefreshMargin = 30 * time.Second
+
+// expiration returns when the session expires, zero if unknown.
+// It should be called with the lock held.
+func (s *sessionLoginState) expiration() time.Time {
+	if !s.expiresAt.IsZero() {
+		return s.expiresAt
+	}
+
+	if s.maxAge > 0 && !s.at.IsZero() {
+		return s.at.Add(s.maxAge)
+	}
+
+	return time.Time{}
+}
+
+// expired reports if the session should be renewed.
+// It should be called with the lock held.
+func (s *sessionLoginState) expired() bool {
+	if s.maxAge > 0 && !s.at.IsZero() && time.Since(s.at) >= s.maxAge {
+		return true
+	}
+
+	return !s.expiresAt.IsZero() && time.Until(s.expiresAt) <= SessionRefreshMargin
+}
+
+// sharedCall is a call in progress, like a login, shared by every goroutine
+// waiting for it
+type sharedCall struct {
+	done chan struct{}
+	err  error
+}
+
+func (m *Millennium) setSessionPending() {
+	m.login.mu.Lock()
+	defer m.login.mu.Unlock()
+
+	m.login.pending = true
+}
+
+// ensureSession performs the pending lazy login, if any.
+// Concurrent requests wait for a single login instead of starting their own.
+func (m *Millennium) ensureSession() error {
+	m.login.mu.Lock()
+	if !m.login.pending && m.getCredentials().AuthType == Session && m.login.expired() {
+		m.login.pending = true
+	}
+
+	if !m.login.pending {
+		m.login.mu.Unlock()
+		return nil
+	}
+
+	if call := m.login.call; call != nil {
+		m.login.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+
+	call := &sharedCall{done: make(chan struct{})}
+	m.login.call = call
+	m.login.mu.Unlock()
+
+	call.err = m.sessionLogin()
+
+	m.login.mu.Lock()
+	if call.err == nil {
+		m.login.pending = false
+	}
+	m.login.call = nil
+	m.login.mu.Unlock()
+
+	close(call.done)
+	return call.err
+}
+
+// sessionLogin requests a new WTS session with the stored credentials
+func (m *Millennium) sessionLogin() error {
+	var responseLogin ResponseLogin
+
+	// The request is built directly, as Request would wait for this same login
+	req, err := m.newRequest(m.Context, RequestMethod{
+		HTTPMethod: POST,
+		Method:     "login",
+		Body:       []byte{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Credentials are only sent by the login request
+	req.Header.Set("WTS-Authorization", wtsAuthorization(m.getCredentials()))
+
+	if err := m.sendRequest("login", req, &responseLogin, nil); err != nil {
+		return err
+	}
+
+	m.updateCredentials(func(c *credentials) { c.Session = responseLogin.Session })
+	m.setHeader("WTS-Session", responseLogin.Session)
+
+	now := time.Now()
+
+	m.login.mu.Lock()
+	m.login.at = now
+	m.login.expiresAt = responseLogin.expiresAt(now)
+	m.login.mu.Unlock()
+
+	return nil
+}
+
+// expiresAt returns when the session expires, zero if not sent
+func (r ResponseLogin) expiresAt(now time.Time) time.Time {
+	if r.ExpiresIn > 0 {
+		return now.Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.ParseInLocation(layout, r.ExpiresAt, time.Local); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// SessionExpiresAt returns when the current WTS session expires, as sent by
+// the login response or, when not sent, set by WithSessionMaxAge. It
+// returns false when the expiration is unknown.
+func (m *Millennium) SessionExpiresAt() (time.Time, bool) {
+	m.login.mu.Lock()
+	defer m.login.mu.Unlock()
+
+	if expiresAt := m.login.expiration(); !expiresAt.IsZero() {
+		return expiresAt, true
+	}
+
+	return time.Time{}, false
+}
+
+// WithReloginOnUnauthorized renews the WTS session with the stored
+// credentials when a request is answered with 401 Unauthorized, sending the
+// request once more with the new session, so sessions expired in the middle
+// of a run do not need a manual Login
+func WithReloginOnUnauthorized() Option {
+	return func(m *Millennium) {
+		m.relogin = true
+	}
+}
+
+// shouldRelogin reports if the response asks for a new session
+func (m *Millennium) shouldRelogin(method string, res *http.Response) bool {
+	return m.relogin && method != "login" && res.StatusCode == http.StatusUnauthorized && m.getCredentials().AuthType == Session
+}
+
+// renewSession requests a new session unless the stale one was already
+// renewed by another request
+func (m *Millennium) renewSession(stale string) error {
+	m.login.mu.Lock()
+	if m.getCredentials().Session == stale {
+		m.login.pending = true
+	}
+	m.login.mu.Unlock()
+
+	return m.ensureSession()
+}
+
+// Logout ends the WTS session on Millennium, releasing it from the server
+// session pool, and forgets it, so the next requests are unauthenticated
+// until Login is called again. It does nothing without a session.
+// Close also calls it.
+func (m *Millennium) Logout(ctx context.Context) error {
+	return m.onServer(ctx, func() error {
+		return m.logout(ctx)
+	})
+}
+
+// logout is Logout once on the current server
+func (m *Millennium) logout(ctx context.Context) error {
+	creds := m.getCredentials()
+	if creds.AuthType != Session || creds.Session == "" {
+		return nil
+	}
+
+	req, err := m.newRequest(ctx, RequestMethod{
+		HTTPMethod: POST,
+		Method:     "logout",
+		Body:       []byte{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The session is forgotten even if Millennium fails to end it
+	m.updateCredentials(func(c *credentials) { c.Session = "" })
+	m.delHeader("WTS-Session")
+
+	m.login.mu.Lock()
+	m.login.at = time.Time{}
+	m.login.expiresAt = time.Time{}
+	m.login.mu.Unlock()
+
+	res, err := m.do("logout", req)
+	if err != nil {
+		return fmt.Errorf("unable to make the request to Millennium: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := readBody(res)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 400 {
+		return m.responseError(res, body)
+	}
+
+	return nil
+}
+
+// wtsAuthorization returns the WTS-Authorization header of the credentials
+func wtsAuthorization(creds credentials) string {
+	return fmt.Sprintf("%s/%s", strings.ToUpper(creds.Username), strings.ToUpper(creds.Password))
+}
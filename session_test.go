@@ -0,0 +1,375 @@
+package millennium
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSessionServer returns a server accepting the TEST/TEST credentials,
+// counting the logins it received
+func newSessionServer(t *testing.T) (*httptest.Server, *int32) {
+	var logins int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		time.Sleep(10 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("WTS-Authorization") != "TEST/TEST" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"PERMISSÃO NEGADA"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+	})
+	mux.HandleFunc("/api/logout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("WTS-Session") == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessão inválida"}}}`))
+		}
+	})
+	mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("WTS-Session") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessão inválida"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, &logins
+}
+
+func TestLazyLogin(t *testing.T) {
+	server, logins := newSessionServer(t)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithLazyLogin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(logins) != 0 {
+		t.Fatal("Expected no login before the first request")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var r interface{}
+			if _, err := client.Get("test", url.Values{}, &r); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(logins) != 1 {
+		t.Errorf("Expected a single login but got %d", *logins)
+	}
+}
+
+func TestLazyLoginError(t *testing.T) {
+	server, logins := newSessionServer(t)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithLazyLogin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "wrong", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get("test", url.Values{}, &r); err == nil {
+			t.Error("Expected login error")
+		}
+	}
+
+	// A failed login is attempted again by the next request
+	if atomic.LoadInt32(logins) != 2 {
+		t.Errorf("Expected 2 logins but got %d", *logins)
+	}
+}
+
+func TestSessionMaxAge(t *testing.T) {
+	server, logins := newSessionServer(t)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithSessionMaxAge(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(logins) != 1 {
+		t.Fatalf("Expected the session to be reused but got %d logins", *logins)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := client.Get("test", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(logins) != 2 {
+		t.Errorf("Expected the session to be renewed but got %d logins", *logins)
+	}
+}
+
+func TestConcurrentLoginHeaders(t *testing.T) {
+	var leaked int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"session":"{00000000-0000-0000-0000-000000000000}"}`))
+	})
+	mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("WTS-Authorization") != "" {
+			atomic.AddInt32(&leaked, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := client.Login("test", "test", Session); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var r interface{}
+			if _, err := client.Get("test", url.Values{}, &r); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&leaked) > 0 {
+		t.Errorf("Login credentials leaked into %d requests", leaked)
+	}
+}
+
+func TestReloginOnUnauthorized(t *testing.T) {
+	var (
+		logins  int32
+		current atomic.Value
+	)
+	current.Store("")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&logins, 1)
+		session := fmt.Sprintf("session-%d", n)
+		current.Store(session)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"session":%q}`, session)
+	})
+	mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("WTS-Session") != current.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessão inválida"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"number":1}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		Name    string
+		Options []Option
+		Fails   bool
+		Logins  int32
+	}{
+		{Name: "disabled", Fails: true, Logins: 1},
+		{Name: "enabled", Options: []Option{WithReloginOnUnauthorized()}, Logins: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			atomic.StoreInt32(&logins, 0)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, c.Options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := client.Login("test", "test", Session); err != nil {
+				t.Fatal(err)
+			}
+
+			// The server forgets the session, as if it had expired
+			current.Store("expired")
+
+			var wg sync.WaitGroup
+			var failed int32
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					var r interface{}
+					if _, err := client.Get("test", url.Values{}, &r); err != nil {
+						atomic.AddInt32(&failed, 1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if (failed > 0) != c.Fails {
+				t.Errorf("Expected fails to be %v but %d requests failed", c.Fails, failed)
+			}
+
+			if atomic.LoadInt32(&logins) != c.Logins {
+				t.Errorf("Expected %d logins but got %d", c.Logins, logins)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	server, _ := newSessionServer(t)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a session there is nothing to end
+	if err := client.Logout(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Logout(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.getCredentials().Session != "" {
+		t.Error("Expected the session to be cleared")
+	}
+
+	if client.headerSnapshot().Get("WTS-Session") != "" {
+		t.Error("Expected the WTS-Session header to be removed")
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", url.Values{}, &r); err == nil {
+		t.Error("Expected requests to fail after logout")
+	}
+}
+
+func TestSessionExpiresAt(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Login   string
+		Known   bool
+		Expires time.Duration
+		Logins  int32
+	}{
+		{Name: "expires in", Login: `"expires_in":3600`, Known: true, Expires: time.Hour, Logins: 1},
+		{Name: "expires at", Login: fmt.Sprintf(`"expires_at":%q`, time.Now().Add(time.Hour).Format(time.RFC3339)), Known: true, Expires: time.Hour, Logins: 1},
+		{Name: "within refresh margin", Login: `"expires_in":10`, Known: true, Expires: 10 * time.Second, Logins: 2},
+		{Name: "unknown", Login: `"expires_at":""`, Logins: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var logins int32
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&logins, 1)
+
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"session":"{00000000-0000-0000-0000-000000000000}",%s}`, c.Login)
+			})
+			mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"odata.count":0,"value":[]}`))
+			})
+
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := client.Login("test", "test", Session); err != nil {
+				t.Fatal(err)
+			}
+
+			expiresAt, known := client.SessionExpiresAt()
+			if known != c.Known {
+				t.Fatalf("Expected expiration known to be %v but got %v", c.Known, known)
+			}
+
+			if known {
+				if d := time.Until(expiresAt); d > c.Expires || d < c.Expires-time.Minute {
+					t.Errorf("Expected the session to expire in %s but got %s", c.Expires, d)
+				}
+			}
+
+			var r interface{}
+			if _, err := client.Get("test", url.Values{}, &r); err != nil {
+				t.Fatal(err)
+			}
+
+			if atomic.LoadInt32(&logins) != c.Logins {
+				t.Errorf("Expected %d logins but got %d", c.Logins, logins)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package millennium
+
+import (
+	"math/rand"
+	"net/url"
+)
+
+// ShadowResult compares a read request answered by the primary server with
+// its mirror on the shadow server
+type ShadowResult struct {
+	Method string
+	Params url.Values
+
+	// StatusCode and Checksum of the primary response
+	StatusCode int
+	Checksum   string
+
+	// ShadowStatusCode and ShadowChecksum of the shadow response
+	ShadowStatusCode int
+	ShadowChecksum   string
+
+	// Err is the error of the shadow request, if any
+	Err error
+}
+
+// Diverged reports if the shadow server failed or answered differently
+// from the primary one
+func (r ShadowResult) Diverged() bool {
+	return r.Err != nil || r.StatusCode != r.ShadowStatusCode || r.Checksum != r.ShadowChecksum
+}
+
+// ShadowHook receives the comparison of every mirrored request
+type ShadowHook func(result ShadowResult)
+
+// WithShadow mirrors percent (0 to 100) of the successful GET requests to
+// shadow, like a homolog server running a new ERP version, and calls hook
+// with the comparison of both responses. Responses are compared byte by
+// byte, through their checksums.
+// Mirrored requests run in background, with the shadow client context, so
+// they never delay or fail the primary requests. Close waits for them like
+// for the other requests in flight.
+func WithShadow(shadow *Millennium, percent float64, hook ShadowHook) Option {
+	return func(m *Millennium) {
+		m.shadow = &shadowMirror{client: shadow, percent: percent, hook: hook}
+	}
+}
+
+// shadowMirror is the shadow server set by WithShadow
+type shadowMirror struct {
+	client  *Millennium
+	percent float64
+	hook    ShadowHook
+}
+
+// sampled reports if a request should be mirrored
+func (s *shadowMirror) sampled(r RequestMethod) bool {
+	return s != nil && r.HTTPMethod == GET && rand.Float64()*100 < s.percent
+}
+
+// mirror sends r to the shadow server and reports the comparison with the
+// primary response
+func (s *shadowMirror) mirror(r RequestMethod, primary ResponseMeta) {
+	var response interface{}
+	var meta ResponseMeta
+
+	err := s.client.Request(RequestMethod{
+		HTTPMethod: r.HTTPMethod,
+		Method:     r.Method,
+		Params:     r.Params,
+		Response:   &response,
+		Meta:       &meta,
+	})
+
+	if s.hook == nil {
+		return
+	}
+
+	s.hook(ShadowResult{
+		Method:           r.Method,
+		Params:           r.Params,
+		StatusCode:       primary.StatusCode,
+		Checksum:         primary.Checksum,
+		ShadowStatusCode: meta.StatusCode,
+		ShadowChecksum:   meta.Checksum,
+		Err:              err,
+	})
+}
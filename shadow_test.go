@@ -0,0 +1,119 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithShadow(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("$format") != "json" || len(r.URL.Query()["$format"]) != 1 {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	primary := newServer(`{"odata.count":1,"value":[{"preco":10}]}`)
+
+	cases := []struct {
+		Name     string
+		Shadow   string
+		Percent  float64
+		Mirrored bool
+		Diverged bool
+	}{
+		{Name: "same response", Shadow: `{"odata.count":1,"value":[{"preco":10}]}`, Percent: 100, Mirrored: true},
+		{Name: "divergent response", Shadow: `{"odata.count":1,"value":[{"preco":12}]}`, Percent: 100, Mirrored: true, Diverged: true},
+		{Name: "not sampled", Shadow: `{"odata.count":1,"value":[{"preco":10}]}`, Percent: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			shadowServer := newServer(c.Shadow)
+
+			shadow, err := NewClient(context.Background(), shadowServer.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := make(chan ShadowResult, 1)
+			client, err := NewClient(context.Background(), primary.URL, 5*time.Second, WithShadow(shadow, c.Percent, func(result ShadowResult) {
+				results <- result
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			if _, err := client.Get("test", nil, &r); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case result := <-results:
+				if !c.Mirrored {
+					t.Fatal("Expected the request not to be mirrored")
+				}
+
+				if result.Method != "test" {
+					t.Errorf("Expected method test but got %s", result.Method)
+				}
+
+				if result.Diverged() != c.Diverged {
+					t.Errorf("Expected diverged to be %v but got %+v", c.Diverged, result)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if c.Mirrored {
+					t.Fatal("Expected the request to be mirrored")
+				}
+			}
+		})
+	}
+}
+
+func TestShadowClose(t *testing.T) {
+	primary, _ := newCountingServer(t, http.StatusOK, `{"odata.count":1,"value":[{"preco":10}]}`)
+
+	var mirrored int32
+	shadowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"odata.count":1,"value":[{"preco":10}]}`))
+	}))
+	t.Cleanup(shadowServer.Close)
+
+	shadow, err := NewClient(context.Background(), shadowServer.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(context.Background(), primary.URL, 5*time.Second, WithShadow(shadow, 100, func(ShadowResult) {
+		atomic.AddInt32(&mirrored, 1)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test", nil, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&mirrored) != 1 {
+		t.Error("Expected Close to wait for the mirrored request")
+	}
+}
@@ -0,0 +1,218 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// FieldShape is the statistics of a field of the responses of a method
+type FieldShape struct {
+	// Seen is the number of records having the field
+	Seen int `json:"seen"`
+
+	// Types counts the records by the JSON type of the field: string,
+	// number, boolean, null, object or array
+	Types map[FieldType]int `json:"types"`
+}
+
+// fieldNull is the type of the fields received as null
+const fieldNull FieldType = "null"
+
+// Type returns the most seen type of the field other than null, null if the
+// field was always null
+func (s FieldShape) Type() FieldType {
+	types := make([]FieldType, 0, len(s.Types))
+	for t := range s.Types {
+		if t != fieldNull {
+			types = append(types, t)
+		}
+	}
+
+	if len(types) == 0 {
+		return fieldNull
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		if s.Types[types[i]] != s.Types[types[j]] {
+			return s.Types[types[i]] > s.Types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	return types[0]
+}
+
+// ShapeChange tells a field was received with a type other than the one
+// seen first, like a number sent as a string after an update of Millennium
+type ShapeChange struct {
+	Method   string
+	Field    string
+	Previous FieldType
+	Current  FieldType
+}
+
+// ShapeChangeHook receives the fields changing type
+type ShapeChangeHook func(change ShapeChange)
+
+// ShapeRecorder keeps the names and types of the fields of the responses
+// sampled by WithShapeSampling, never their values, so the statistics can
+// be shared to infer the structs of the methods or detect the fields
+// changing type in production.
+type ShapeRecorder struct {
+	// OnChange is called once for each new type of a field, optional
+	OnChange ShapeChangeHook
+
+	mu      sync.Mutex
+	methods map[string]map[string]*FieldShape
+	first   map[string]FieldType
+}
+
+// NewShapeRecorder returns an empty ShapeRecorder. The zero value is ready
+// to use as well.
+func NewShapeRecorder() *ShapeRecorder {
+	return &ShapeRecorder{methods: map[string]map[string]*FieldShape{}, first: map[string]FieldType{}}
+}
+
+// WithShapeSampling records the shape of percent% of the JSON responses in
+// recorder. The fields of the records of the value array are recorded,
+// named by their path, like "itens[].preco" for a field of the objects of
+// the itens array.
+func WithShapeSampling(percent float64, recorder *ShapeRecorder) Option {
+	return func(m *Millennium) {
+		m.shapes = &shapeSampler{percent: percent, recorder: recorder}
+	}
+}
+
+// shapeSampler is the sampler set by WithShapeSampling
+type shapeSampler struct {
+	percent  float64
+	recorder *ShapeRecorder
+}
+
+// sampled reports if the next response should be recorded
+func (s *shapeSampler) sampled() bool {
+	return s != nil && s.recorder != nil && rand.Float64()*100 < s.percent
+}
+
+// Shapes returns a copy of the statistics of each field, by method and
+// field path
+func (r *ShapeRecorder) Shapes() map[string]map[string]FieldShape {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	shapes := make(map[string]map[string]FieldShape, len(r.methods))
+	for method, fields := range r.methods {
+		shapes[method] = make(map[string]FieldShape, len(fields))
+		for name, shape := range fields {
+			types := make(map[FieldType]int, len(shape.Types))
+			for t, n := range shape.Types {
+				types[t] = n
+			}
+			shapes[method][name] = FieldShape{Seen: shape.Seen, Types: types}
+		}
+	}
+
+	return shapes
+}
+
+// recordBody records the records of a response body of method
+func (r *ShapeRecorder) recordBody(method string, body []byte) {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return
+	}
+
+	value, ok := response["value"]
+	if !ok {
+		r.recordRecord(method, body)
+		return
+	}
+
+	var records []json.RawMessage
+	if err := json.Unmarshal(value, &records); err != nil {
+		return
+	}
+
+	for _, record := range records {
+		r.recordRecord(method, record)
+	}
+}
+
+// recordRecord records the fields of a single record of method
+func (r *ShapeRecorder) recordRecord(method string, record []byte) {
+	dec := json.NewDecoder(bytes.NewReader(record))
+	dec.UseNumber()
+
+	var v map[string]interface{}
+	if err := dec.Decode(&v); err != nil {
+		return
+	}
+
+	fields := map[string]FieldType{}
+	collectShape(fields, "", v)
+
+	var changes []ShapeChange
+
+	r.mu.Lock()
+	if r.methods == nil {
+		r.methods = map[string]map[string]*FieldShape{}
+		r.first = map[string]FieldType{}
+	}
+
+	if r.methods[method] == nil {
+		r.methods[method] = map[string]*FieldShape{}
+	}
+
+	for name, t := range fields {
+		shape := r.methods[method][name]
+		if shape == nil {
+			shape = &FieldShape{Types: map[FieldType]int{}}
+			r.methods[method][name] = shape
+		}
+
+		shape.Seen++
+		shape.Types[t]++
+
+		if t == fieldNull {
+			continue
+		}
+
+		key := method + "\x00" + name
+		if first, ok := r.first[key]; !ok {
+			r.first[key] = t
+		} else if first != t && shape.Types[t] == 1 {
+			changes = append(changes, ShapeChange{Method: method, Field: name, Previous: first, Current: t})
+		}
+	}
+	hook := r.OnChange
+	r.mu.Unlock()
+
+	if hook != nil {
+		for _, change := range changes {
+			hook(change)
+		}
+	}
+}
+
+// collectShape sets the type of each field of object in fields, walking
+// into the nested objects and the objects of arrays
+func collectShape(fields map[string]FieldType, prefix string, object map[string]interface{}) {
+	for name, value := range object {
+		path := prefix + name
+		fields[path] = jsonType(value)
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			collectShape(fields, path+".", v)
+		case []interface{}:
+			for _, item := range v {
+				if nested, ok := item.(map[string]interface{}); ok {
+					collectShape(fields, path+"[].", nested)
+				}
+			}
+		}
+	}
+}
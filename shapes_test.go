@@ -0,0 +1,117 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShapeSampling(t *testing.T) {
+	var second int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// The price becomes a string in the second response
+		if atomic.AddInt32(&second, 1) > 1 {
+			_, _ = w.Write([]byte(`{"value":[{"produto":3,"preco":"1.234,56","itens":[{"sku":"C"}]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"odata.count":2,"value":[{"produto":1,"preco":9.9,"descricao":"Camisa","itens":[{"sku":"A"}]},{"produto":2,"preco":null,"descricao":null,"itens":[]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	recorder := NewShapeRecorder()
+
+	var changes []ShapeChange
+	recorder.OnChange = func(change ShapeChange) {
+		changes = append(changes, change)
+	}
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second, WithShapeSampling(100, recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []map[string]interface{}
+	if _, err := client.Get("produtos.lista", url.Values{}, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, errs := client.Stream(context.Background(), "produtos.lista", url.Values{})
+	for range stream {
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	shapes := recorder.Shapes()["produtos.lista"]
+
+	expected := map[string]FieldShape{
+		"produto":     {Seen: 3, Types: map[FieldType]int{FieldNumber: 3}},
+		"preco":       {Seen: 3, Types: map[FieldType]int{FieldNumber: 1, fieldNull: 1, FieldString: 1}},
+		"descricao":   {Seen: 2, Types: map[FieldType]int{FieldString: 1, fieldNull: 1}},
+		"itens":       {Seen: 3, Types: map[FieldType]int{FieldArray: 3}},
+		"itens[].sku": {Seen: 2, Types: map[FieldType]int{FieldString: 2}},
+	}
+	if !reflect.DeepEqual(shapes, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, shapes)
+	}
+
+	if got := shapes["descricao"].Type(); got != FieldString {
+		t.Errorf("Expected string but got %s", got)
+	}
+
+	expectedChanges := []ShapeChange{{Method: "produtos.lista", Field: "preco", Previous: FieldNumber, Current: FieldString}}
+	if !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("Expected %+v but got %+v", expectedChanges, changes)
+	}
+
+	// Only names and types are kept
+	for field := range shapes {
+		if strings.Contains(field, "Camisa") {
+			t.Errorf("Unexpected value in %s", field)
+		}
+	}
+}
+
+func TestShapeSamplingDisabled(t *testing.T) {
+	recorder := NewShapeRecorder()
+	client, err := NewClient(context.Background(), serverAddr, 5*time.Second, WithShapeSampling(0, recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if shapes := recorder.Shapes(); len(shapes) != 0 {
+		t.Errorf("Expected nothing recorded but got %v", shapes)
+	}
+}
+
+func TestShapeRecorderZeroValue(t *testing.T) {
+	var changes int
+	recorder := &ShapeRecorder{OnChange: func(ShapeChange) { changes++ }}
+
+	client, err := NewClient(context.Background(), serverAddr, 5*time.Second, WithShapeSampling(100, recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if shapes := recorder.Shapes(); len(shapes["test.success.GET"]) == 0 || changes != 0 {
+		t.Errorf("Expected the shapes recorded without changes but got %v and %d changes", shapes, changes)
+	}
+}
@@ -0,0 +1,159 @@
+package millennium
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SLOWindow is the default number of recent requests an SLO is measured over
+const SLOWindow = 100
+
+// SLO is a latency objective for Millennium methods, like "95% of the
+// requests to millenium.produtos.lista answer within 2s"
+type SLO struct {
+	// Method is the method measured, it accepts path.Match wildcards and
+	// every method is measured when empty
+	Method string
+
+	// Percentile of the requests that should answer within Threshold,
+	// like 0.95 for the p95
+	Percentile float64
+
+	// Threshold is the maximum latency of the percentile
+	Threshold time.Duration
+
+	// Window is the number of recent requests considered, SLOWindow if zero.
+	// The objective is only evaluated once the window is full.
+	Window int
+}
+
+// SLOStatus is the current state of an SLO
+type SLOStatus struct {
+	SLO SLO
+
+	// Latency is the latency of the percentile over the window
+	Latency time.Duration
+
+	// Samples is the number of requests in the window
+	Samples int
+
+	// Violated reports if Latency is above the SLO threshold
+	Violated bool
+}
+
+// SLOHook receives the status of an SLO when it becomes violated and when it
+// recovers
+type SLOHook func(status SLOStatus)
+
+// WithSLO tracks the latency of the requests, from the request until the
+// response headers, against the objectives and calls hook when one of them
+// is violated or recovers. It can be used more than once.
+func WithSLO(hook SLOHook, objectives ...SLO) Option {
+	return func(m *Millennium) {
+		for _, slo := range objectives {
+			if slo.Window <= 0 {
+				slo.Window = SLOWindow
+			}
+
+			m.slo.objectives = append(m.slo.objectives, &sloObjective{
+				slo:       slo,
+				hook:      hook,
+				latencies: make([]time.Duration, slo.Window),
+			})
+		}
+	}
+}
+
+// SLOs returns the current status of every objective, to be exported as
+// metrics
+func (m *Millennium) SLOs() []SLOStatus {
+	m.slo.mu.Lock()
+	defer m.slo.mu.Unlock()
+
+	statuses := make([]SLOStatus, len(m.slo.objectives))
+	for i, o := range m.slo.objectives {
+		statuses[i] = o.status()
+	}
+
+	return statuses
+}
+
+type sloTracker struct {
+	mu         sync.Mutex
+	objectives []*sloObjective
+}
+
+// sloObjective keeps the latencies of an SLO in a ring buffer
+type sloObjective struct {
+	slo       SLO
+	hook      SLOHook
+	latencies []time.Duration
+	next      int
+	count     int
+	violated  bool
+}
+
+func (t *sloTracker) record(method string, latency time.Duration) {
+	type notification struct {
+		hook   SLOHook
+		status SLOStatus
+	}
+
+	var notify []notification
+
+	t.mu.Lock()
+	for _, o := range t.objectives {
+		if o.slo.Method != "" && !matchMethod([]string{o.slo.Method}, strings.ToLower(method)) {
+			continue
+		}
+
+		o.latencies[o.next] = latency
+		o.next = (o.next + 1) % len(o.latencies)
+		if o.count < len(o.latencies) {
+			o.count++
+		}
+
+		status := o.status()
+		if o.count == len(o.latencies) && status.Violated != o.violated {
+			o.violated = status.Violated
+			if o.hook != nil {
+				notify = append(notify, notification{hook: o.hook, status: status})
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	// Hooks run without the lock, so they can call SLOs
+	for _, n := range notify {
+		n.hook(n.status)
+	}
+}
+
+// status computes the percentile latency over the window.
+// It should be called with the lock held.
+func (o *sloObjective) status() SLOStatus {
+	status := SLOStatus{SLO: o.slo, Samples: o.count}
+	if o.count == 0 {
+		return status
+	}
+
+	latencies := make([]time.Duration, o.count)
+	copy(latencies, o.latencies[:o.count])
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	// Nearest rank percentile
+	rank := int(math.Ceil(o.slo.Percentile*float64(o.count))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= o.count {
+		rank = o.count - 1
+	}
+
+	status.Latency = latencies[rank]
+	status.Violated = o.count == len(o.latencies) && status.Latency > o.slo.Threshold
+	return status
+}
@@ -0,0 +1,72 @@
+package millennium
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSLO(t *testing.T) {
+	var statuses []SLOStatus
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithSLO(func(s SLOStatus) {
+		statuses = append(statuses, s)
+	}, SLO{
+		Method:     "millenium.produtos.*",
+		Percentile: 0.9,
+		Threshold:  2 * time.Second,
+		Window:     10,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Other methods are not measured
+	client.slo.record("millenium.clientes.lista", time.Minute)
+
+	for i := 0; i < 9; i++ {
+		client.slo.record("millenium.produtos.lista", 5*time.Second)
+	}
+
+	if len(statuses) != 0 {
+		t.Errorf("Expected no notification before the window is full but got %+v", statuses)
+	}
+
+	client.slo.record("millenium.produtos.lista", time.Second)
+
+	if len(statuses) != 1 || !statuses[0].Violated || statuses[0].Latency != 5*time.Second || statuses[0].Samples != 10 {
+		t.Fatalf("Expected a violation but got %+v", statuses)
+	}
+
+	// One slow request in ten keeps the p90 within the threshold
+	for i := 0; i < 9; i++ {
+		client.slo.record("millenium.produtos.lista", 100*time.Millisecond)
+	}
+
+	if len(statuses) != 2 || statuses[1].Violated {
+		t.Fatalf("Expected a recovery but got %+v", statuses)
+	}
+
+	current := client.SLOs()
+	if len(current) != 1 || current[0].Violated || current[0].Latency != 100*time.Millisecond {
+		t.Errorf("Unexpected status %+v", current)
+	}
+}
+
+func TestSLORecordsRequests(t *testing.T) {
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithSLO(nil, SLO{Percentile: 0.5, Threshold: time.Second}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	current := client.SLOs()
+	if len(current) != 1 || current[0].Samples != 1 || current[0].SLO.Window != SLOWindow {
+		t.Errorf("Unexpected status %+v", current)
+	}
+}
@@ -0,0 +1,154 @@
+package millennium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Sample is the outcome of a request to Millennium, meant to feed uptime and
+// latency monitoring systems
+type Sample struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	Latency    time.Duration `json:"latency"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Health     Health        `json:"health"`
+	Tenant     string        `json:"tenant,omitempty"`
+	Operation  string        `json:"operation,omitempty"`
+
+	// Baggage of the request context, see WithBaggage
+	Baggage map[string]string `json:"baggage,omitempty"`
+}
+
+// OK reports if Millennium answered the request without a server error
+func (s Sample) OK() bool {
+	return s.Error == "" && s.StatusCode < 500
+}
+
+// SampleHook receives a Sample after every request
+type SampleHook func(sample Sample)
+
+// WithSampleHook sets a hook to receive a Sample after every request
+func WithSampleHook(hook SampleHook) Option {
+	return func(m *Millennium) {
+		m.sampleHook = hook
+	}
+}
+
+// sample sends the outcome of a request to the sample hook
+func (m *Millennium) sample(ctx context.Context, method string, started time.Time, res *http.Response, err error) {
+	if m.sampleHook == nil {
+		return
+	}
+
+	s := Sample{
+		Time:      started,
+		Method:    method,
+		Latency:   time.Since(started),
+		Health:    m.Health(),
+		Tenant:    m.tenant,
+		Operation: OperationFrom(ctx),
+		Baggage:   BaggageFrom(ctx),
+	}
+
+	if res != nil {
+		s.StatusCode = res.StatusCode
+	}
+
+	// Errors may carry the URL of the request, like the retries giving up
+	if err != nil {
+		s.Error = m.redaction.redactLine(err.Error())
+	}
+
+	m.sampleHook(s)
+}
+
+// StatusPinger pings a status page or healthcheck system, like
+// healthchecks.io, with the samples of the client. Its Hook method
+// should be set with WithSampleHook. The tenant, operation and baggage of
+// the sample are sent in the W3C baggage header.
+type StatusPinger struct {
+	// URL receives a POST with the sample when Millennium is healthy
+	URL string
+
+	// FailURL receives a POST with the sample when Millennium failed,
+	// URL is used if empty
+	FailURL string
+
+	// Interval is the minimum time between pings, so the monitoring system
+	// is not flooded by busy clients. Failures are always sent right away
+	// after a success.
+	Interval time.Duration
+
+	// Client sends the pings, http.DefaultClient if nil
+	Client *http.Client
+
+	mu       sync.Mutex
+	last     time.Time
+	lastOK   bool
+	inflight sync.WaitGroup
+}
+
+// Hook pings the status URL with the sample, in background
+func (p *StatusPinger) Hook(s Sample) {
+	ok := s.OK()
+
+	p.mu.Lock()
+	if !p.last.IsZero() && ok == p.lastOK && time.Since(p.last) < p.Interval {
+		p.mu.Unlock()
+		return
+	}
+	p.last, p.lastOK = time.Now(), ok
+	p.mu.Unlock()
+
+	url := p.URL
+	if !ok && p.FailURL != "" {
+		url = p.FailURL
+	}
+
+	p.inflight.Add(1)
+	go func() {
+		defer p.inflight.Done()
+		p.ping(url, s)
+	}()
+}
+
+// Wait blocks until the pings in background are sent
+func (p *StatusPinger) Wait() {
+	p.inflight.Wait()
+}
+
+func (p *StatusPinger) ping(url string, s Sample) {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if baggage := baggageHeader(s.Tenant, s.Operation, s.Baggage); baggage != "" {
+		req.Header.Set("Baggage", baggage)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+}
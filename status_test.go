@@ -0,0 +1,73 @@
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatusPinger(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		pings = map[string][]Sample{}
+	)
+
+	status := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s Sample
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		pings[r.URL.Path] = append(pings[r.URL.Path], s)
+		mu.Unlock()
+	}))
+	defer status.Close()
+
+	pinger := &StatusPinger{
+		URL:      status.URL + "/ping",
+		FailURL:  status.URL + "/ping/fail",
+		Interval: time.Hour,
+	}
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithSampleHook(pinger.Hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r interface{}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.Get("test.error400.GET", url.Values{}, &r); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	// Server errors are sent to the fail URL as soon as they happen
+	client.Configure("test.error500.GET", MethodConfig{RetryMax: -1})
+	if _, err := client.Get("test.error500.GET", url.Values{}, &r); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	pinger.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(pings["/ping"]) != 1 {
+		t.Errorf("Expected a single success ping in the interval but got %d", len(pings["/ping"]))
+	}
+
+	fails := pings["/ping/fail"]
+	if len(fails) != 1 || fails[0].OK() || fails[0].Method != "test.error500.GET" {
+		t.Errorf("Unexpected fail pings %+v", fails)
+	}
+}
@@ -0,0 +1,187 @@
+package millennium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// StreamBuffer is the number of records buffered by Stream before the
+// producer waits for the consumer
+const StreamBuffer = 64
+
+// Stream requests a method using GET http method and sends each record of the
+// response to the returned channel as soon as it is decoded.
+//
+// The records channel holds at most StreamBuffer records, so the response is
+// read only as fast as the consumer handles the records, instead of loading
+// the entire response in memory. Both channels are closed when the response
+// ends; at most one error is sent to the errors channel.
+func (m *Millennium) Stream(ctx context.Context, method string, params url.Values) (<-chan Record, <-chan error) {
+	records := make(chan Record, StreamBuffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(records)
+		defer close(errs)
+
+		if err := m.stream(ctx, method, params, records); err != nil {
+			errs <- err
+		}
+	}()
+
+	return records, errs
+}
+
+func (m *Millennium) stream(ctx context.Context, method string, params url.Values, records chan<- Record) error {
+	return m.streamValues(ctx, method, params, func(raw json.RawMessage) error {
+		var record Record
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return fmt.Errorf("unable to decode record: %w", err)
+		}
+
+		select {
+		case records <- record:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// streamValues requests a method using GET http method and calls fn with
+// each entry of the value array as soon as it is decoded
+func (m *Millennium) streamValues(ctx context.Context, method string, params url.Values, fn func(raw json.RawMessage) error) error {
+	if err := m.checkMethod(method); err != nil {
+		return err
+	}
+
+	return m.onServer(ctx, func() error {
+		return m.streamServerValues(ctx, method, params, fn)
+	})
+}
+
+// streamServerValues is streamValues once on the current server
+func (m *Millennium) streamServerValues(ctx context.Context, method string, params url.Values, fn func(raw json.RawMessage) error) error {
+	if err := m.ensureSession(); err != nil {
+		return err
+	}
+
+	req, err := m.newRequest(ctx, RequestMethod{
+		HTTPMethod: GET,
+		Method:     method,
+		Params:     params,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := m.do(method, req)
+	if err != nil {
+		return fmt.Errorf("unable to make the request to Millennium: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read body from Millennium response: %w", err)
+		}
+
+		return m.responseError(res, body)
+	}
+
+	count := m.recordCounter(method)
+	sampled := m.shapes.sampled()
+	return decodeValues(json.NewDecoder(res.Body), func(dec *json.Decoder) error {
+		if err := count(); err != nil {
+			return err
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return decodeError(err)
+		}
+
+		raw, err := m.maskFields(raw)
+		if err != nil {
+			return err
+		}
+
+		raw, err = m.encryptFields(raw)
+		if err != nil {
+			return err
+		}
+
+		if sampled {
+			m.shapes.recorder.recordRecord(method, raw)
+		}
+
+		return fn(raw)
+	})
+}
+
+// decodeValues walks through a Millennium response calling fn for each
+// entry of the value array, leaving the decoder positioned at the entry
+func decodeValues(dec *json.Decoder, fn func(dec *json.Decoder) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return decodeError(err)
+		}
+
+		if key, _ := token.(string); key != "value" {
+			// Skip any other field, like odata.count
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return decodeError(err)
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+
+		for dec.More() {
+			if err := fn(dec); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return decodeError(err)
+	}
+
+	if token != delim {
+		return fmt.Errorf("unable to decode response: expected %v but got %v", delim, token)
+	}
+
+	return nil
+}
+
+// decodeError reports a response that ended in the middle as truncated
+func decodeError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrTruncatedResponse, io.ErrUnexpectedEOF)
+	}
+
+	return fmt.Errorf("unable to decode response: %w", err)
+}
@@ -0,0 +1,62 @@
+package millennium
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	client := NewTestClient(t)
+
+	cases := []struct {
+		Method      string
+		Count       int
+		ExpectError bool
+	}{
+		{Method: "test.stream", Count: 200},
+		{Method: "test.success.GET", Count: 1},
+		{Method: "test.error400.GET", ExpectError: true},
+		{Method: "test.error.invalidjson", ExpectError: true},
+		{Method: "test.error.empty", ExpectError: true},
+		{Method: "", ExpectError: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Method, func(t *testing.T) {
+			records, errs := client.Stream(context.Background(), c.Method, url.Values{})
+
+			count := 0
+			for range records {
+				count++
+			}
+
+			err := <-errs
+			if (err == nil) == c.ExpectError {
+				t.Errorf("Unexpected error result: %v", err)
+			}
+
+			if !c.ExpectError && count != c.Count {
+				t.Errorf("Expected %v records but got %v", c.Count, count)
+			}
+		})
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	client := NewTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	records, errs := client.Stream(ctx, "test.stream", url.Values{})
+
+	// Read a single record and give up, the producer must not block forever
+	<-records
+	cancel()
+
+	for range records {
+	}
+
+	if err := <-errs; err == nil {
+		t.Error("Expected context error")
+	}
+}
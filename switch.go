@@ -0,0 +1,210 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// ErrSwitchInProgress is returned by SwitchServer while another switch is
+// in progress
+var ErrSwitchInProgress = errors.New("server switch already in progress")
+
+// serverGate counts the requests using the server, holding new ones while
+// the server is switched
+type serverGate struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	active int
+
+	// paused is closed once the switch in progress, if any, is done
+	paused chan struct{}
+}
+
+// onServer runs fn as a request to the current server, waiting for a server
+// switch in progress to finish first
+func (m *Millennium) onServer(ctx context.Context, fn func() error) error {
+	leave, err := m.enterServer(ctx)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	return fn()
+}
+
+// enterServer registers a request to the current server, waiting for a
+// server switch in progress to finish first. leave should be called once
+// the request is done.
+func (m *Millennium) enterServer(ctx context.Context) (leave func(), err error) {
+	g := &m.gate
+
+	g.mu.Lock()
+	for g.paused != nil {
+		paused := g.paused
+		g.mu.Unlock()
+
+		select {
+		case <-paused:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		g.mu.Lock()
+	}
+	g.active++
+	g.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			g.active--
+			if g.active == 0 && g.cond != nil {
+				g.cond.Broadcast()
+			}
+			g.mu.Unlock()
+		})
+	}, nil
+}
+
+// serverAddr returns the address of the current server
+func (m *Millennium) serverAddr() string {
+	m.gate.mu.Lock()
+	defer m.gate.mu.Unlock()
+
+	return m.ServerAddr
+}
+
+// SwitchServer moves the client to another Millennium server, like the
+// other side of a blue/green deployment. New requests wait while the
+// in-flight ones are drained, then the client logs in to server, when
+// using Session authentication, and the waiting requests are sent to it.
+//
+// The session on the previous server is ended once the client logged in to
+// server, on a best effort basis bounded by ctx.
+//
+// When ctx is done before the requests are drained, or the login fails,
+// the client keeps using the current server. Requests made from inside
+// another request, like from a Stream consumer, wait for the switch, so
+// ctx should have a deadline.
+func (m *Millennium) SwitchServer(ctx context.Context, server string) error {
+	if server == "" {
+		return errors.New("no server address defined")
+	}
+
+	g := &m.gate
+
+	g.mu.Lock()
+	if g.paused != nil {
+		g.mu.Unlock()
+		return ErrSwitchInProgress
+	}
+
+	paused := make(chan struct{})
+	g.paused = paused
+	if g.cond == nil {
+		g.cond = sync.NewCond(&g.mu)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		g.mu.Lock()
+		g.cond.Broadcast()
+		g.mu.Unlock()
+	})
+	defer stop()
+
+	for g.active > 0 && ctx.Err() == nil {
+		g.cond.Wait()
+	}
+
+	previous := m.ServerAddr
+	if ctx.Err() == nil {
+		m.ServerAddr = server
+	}
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.paused = nil
+		g.mu.Unlock()
+		close(paused)
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("unable to drain requests: %w", err)
+	}
+
+	if creds := m.getCredentials(); creds.AuthType == Session && creds.Session != "" {
+		if err := m.sessionLogin(); err != nil {
+			g.mu.Lock()
+			m.ServerAddr = previous
+			g.mu.Unlock()
+
+			return fmt.Errorf("unable to login to %s: %w", server, err)
+		}
+
+		m.endSessionOn(ctx, previous, creds.Session)
+	}
+
+	// Connections to the previous server are no longer needed
+	m.Client.HTTPClient.CloseIdleConnections()
+
+	return nil
+}
+
+// endSessionOn ends session on server, which is no longer the current one,
+// logging the failures
+func (m *Millennium) endSessionOn(ctx context.Context, server, session string) {
+	if err := m.logoutFrom(ctx, server, session); err != nil {
+		if logger, ok := m.Client.Logger.(retryablehttp.Logger); ok {
+			logger.Printf("[WARN] unable to end the Millennium session on %s: %v", server, err)
+		}
+	}
+}
+
+// logoutFrom is logout of session on server
+func (m *Millennium) logoutFrom(ctx context.Context, server, session string) error {
+	req, err := m.newRequest(ctx, RequestMethod{
+		HTTPMethod: POST,
+		Method:     "logout",
+		Body:       []byte{},
+	})
+	if err != nil {
+		return err
+	}
+
+	target, err := url.Parse(server + "/api/logout")
+	if err != nil {
+		return fmt.Errorf("invalid server address: %w", err)
+	}
+	target.RawQuery = req.URL.RawQuery
+
+	// Keep a Host overridden by the client
+	if req.Host == req.URL.Host {
+		req.Host = target.Host
+	}
+	req.URL = target
+	req.Header.Set("WTS-Session", session)
+
+	res, err := m.do("logout", req)
+	if err != nil {
+		return fmt.Errorf("unable to make the request to Millennium: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := readBody(res)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 400 {
+		return m.responseError(res, body)
+	}
+
+	return nil
+}
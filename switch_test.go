@@ -0,0 +1,112 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSwitchServer returns a server named name, issuing its own session and
+// answering test after delay, and the number of its sessions ended
+func newSwitchServer(t *testing.T, name string, delay time.Duration) (*httptest.Server, *int32) {
+	session := "{" + name + "}"
+	var logouts int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"session":%q}`, session)
+	})
+	mux.HandleFunc("/api/logout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("WTS-Session") == session {
+			atomic.AddInt32(&logouts, 1)
+		}
+	})
+	mux.HandleFunc("/api/test", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("WTS-Session") != session {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":401,"message":{"lang":"pt-BR","value":"Sessão inválida"}}}`))
+			return
+		}
+		fmt.Fprintf(w, `{"odata.count":1,"value":[{"server":%q}]}`, name)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, &logouts
+}
+
+func TestSwitchServer(t *testing.T) {
+	blue, blueLogouts := newSwitchServer(t, "blue", 100*time.Millisecond)
+	green, greenLogouts := newSwitchServer(t, "green", 0)
+
+	client, err := NewClient(context.Background(), blue.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("test", "test", Session); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() (string, error) {
+		var records []struct {
+			Server string `json:"server"`
+		}
+		if _, err := client.Get("test", nil, &records); err != nil {
+			return "", err
+		}
+		return records[0].Server, nil
+	}
+
+	// A request in flight is drained before the switch
+	var inflight atomic.Value
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server, err := get()
+		if err != nil {
+			t.Error(err)
+		}
+		inflight.Store(server)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	timeout, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.SwitchServer(timeout, green.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %v but got %v", context.DeadlineExceeded, err)
+	}
+
+	if err := client.SwitchServer(context.Background(), green.URL); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	if inflight.Load() != "blue" {
+		t.Errorf("Expected the in-flight request to finish on blue but got %v", inflight.Load())
+	}
+
+	server, err := get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server != "green" {
+		t.Errorf("Expected requests to go to green but got %s", server)
+	}
+
+	// The blue session is ended once, only after the switch
+	if blue, green := atomic.LoadInt32(blueLogouts), atomic.LoadInt32(greenLogouts); blue != 1 || green != 0 {
+		t.Errorf("Expected the blue session ended once but got %d on blue and %d on green", blue, green)
+	}
+}
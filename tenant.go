@@ -0,0 +1,26 @@
+package millennium
+
+import (
+	"log"
+)
+
+// WithTenant labels the client with the tenant it serves, like a franchise
+// of a multi-tenant deployment. The label is set in every Sample and
+// prefixes the log lines of the client, so problems of a single tenant
+// can be told apart.
+func WithTenant(tenant string) Option {
+	return func(m *Millennium) {
+		m.tenant = tenant
+
+		// The default logger is shared by every retryablehttp client, so a
+		// new one is created instead of changing its prefix
+		if logger, ok := m.Client.Logger.(*log.Logger); ok && logger != nil {
+			m.Client.Logger = log.New(logger.Writer(), logger.Prefix()+"tenant="+tenant+" ", logger.Flags())
+		}
+	}
+}
+
+// Tenant returns the tenant label set by WithTenant
+func (m *Millennium) Tenant() string {
+	return m.tenant
+}
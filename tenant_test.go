@@ -0,0 +1,46 @@
+package millennium
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTenant(t *testing.T) {
+	var samples []Sample
+
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second,
+		WithSampleHook(func(s Sample) { samples = append(samples, s) }),
+		WithTenant("loja-01"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Tenant() != "loja-01" {
+		t.Errorf("Expected tenant loja-01 but got %s", client.Tenant())
+	}
+
+	var r interface{}
+	if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(samples) != 1 || samples[0].Tenant != "loja-01" {
+		t.Errorf("Expected a sample of tenant loja-01 but got %+v", samples)
+	}
+
+	logger, ok := client.Client.Logger.(*log.Logger)
+	if !ok || !strings.Contains(logger.Prefix(), "tenant=loja-01") {
+		t.Errorf("Expected logger prefixed with the tenant")
+	}
+
+	// Other clients keep the default logger untouched
+	other := NewTestClient(t)
+	if logger, ok := other.Client.Logger.(*log.Logger); ok && strings.Contains(logger.Prefix(), "tenant=") {
+		t.Errorf("Expected default logger without tenant but got %q", logger.Prefix())
+	}
+}
@@ -0,0 +1,152 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrHeaderTimeout is returned when Millennium takes longer than the header
+// timeout to answer a request
+var ErrHeaderTimeout = errors.New("timed out waiting for the response headers")
+
+// ErrIdleTimeout is returned when the response body stops arriving for
+// longer than the idle timeout
+var ErrIdleTimeout = errors.New("timed out waiting for the response body")
+
+// WithHeaderTimeout limits the time waiting for the response headers,
+// retries included. When not set, the client timeout is used.
+func WithHeaderTimeout(timeout time.Duration) Option {
+	return func(m *Millennium) {
+		m.headerTimeout = timeout
+	}
+}
+
+// WithIdleTimeout replaces the client timeout of the response body with an
+// idle timeout, reset whenever data is read. Slow downloads complete as
+// long as they keep progressing, while stalled ones fail with ErrIdleTimeout.
+//
+// The idle time is measured between reads of the body, so consumers of
+// Stream slower than the idle timeout also abort the request.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(m *Millennium) {
+		m.idleTimeout = timeout
+	}
+}
+
+// deadlines bounds a request by the client timeouts
+type deadlines struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stop   context.CancelFunc
+	header *time.Timer
+	idle   time.Duration
+}
+
+// newDeadlines derives the request context from parent. Without an idle
+// timeout the whole request, body included, is bounded by the client
+// timeout; otherwise the timeout bounds only the wait for the headers.
+func (m *Millennium) newDeadlines(parent context.Context, config MethodConfig) *deadlines {
+	timeout := m.Timeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout
+	}
+
+	headerTimeout := m.headerTimeout
+	if config.HeaderTimeout > 0 {
+		headerTimeout = config.HeaderTimeout
+	}
+
+	d := &deadlines{idle: m.idleTimeout, stop: func() {}}
+	if config.IdleTimeout > 0 {
+		d.idle = config.IdleTimeout
+	}
+
+	ctx := parent
+	if d.idle > 0 {
+		if headerTimeout <= 0 {
+			headerTimeout = timeout
+		}
+	} else {
+		ctx, d.stop = context.WithTimeout(parent, timeout)
+	}
+
+	d.ctx, d.cancel = context.WithCancelCause(ctx)
+	if headerTimeout > 0 {
+		d.header = time.AfterFunc(headerTimeout, func() {
+			d.cancel(ErrHeaderTimeout)
+		})
+	}
+
+	return d
+}
+
+// received stops the header timeout once the response headers arrived
+func (d *deadlines) received() {
+	if d.header != nil {
+		d.header.Stop()
+	}
+}
+
+// release frees the resources of the request context
+func (d *deadlines) release() {
+	d.received()
+	d.cancel(nil)
+	d.stop()
+}
+
+// wrap reports the timeout that canceled the request, if any
+func (d *deadlines) wrap(err error) error {
+	cause := context.Cause(d.ctx)
+	if errors.Is(err, cause) {
+		return err
+	}
+
+	if errors.Is(cause, ErrHeaderTimeout) || errors.Is(cause, ErrIdleTimeout) {
+		return fmt.Errorf("%w: %v", cause, err)
+	}
+
+	return err
+}
+
+// body applies the idle timeout to the response body
+func (d *deadlines) body(body io.ReadCloser) io.ReadCloser {
+	if d.idle <= 0 {
+		return body
+	}
+
+	return &idleBody{
+		ReadCloser: body,
+		deadlines:  d,
+		timer: time.AfterFunc(d.idle, func() {
+			d.cancel(ErrIdleTimeout)
+		}),
+	}
+}
+
+// idleBody cancels the request when no data is read for the idle timeout
+type idleBody struct {
+	io.ReadCloser
+	deadlines *deadlines
+	timer     *time.Timer
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.deadlines.idle)
+	}
+
+	if err != nil && err != io.EOF {
+		err = b.deadlines.wrap(err)
+	}
+
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
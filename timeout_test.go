@@ -0,0 +1,143 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newSlowServer returns a server that waits headerDelay before answering and
+// then sends 10 records, waiting interval between them. A negative interval
+// stalls after the first record.
+func newSlowServer(t *testing.T, headerDelay, interval time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wait := func(d time.Duration) bool {
+			select {
+			case <-time.After(d):
+				return true
+			case <-r.Context().Done():
+				return false
+			}
+		}
+
+		if !wait(headerDelay) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"odata.count":10,"value":[`)
+
+		for i := 0; i < 10; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"number":%d}`, i)
+			w.(http.Flusher).Flush()
+
+			if interval < 0 {
+				wait(time.Second)
+				return
+			}
+
+			if !wait(interval) {
+				return
+			}
+		}
+
+		fmt.Fprint(w, `]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestIdleTimeout(t *testing.T) {
+	cases := []struct {
+		Name        string
+		HeaderDelay time.Duration
+		Interval    time.Duration
+		Options     []Option
+		ExpectError error
+	}{
+		{
+			Name:        "client timeout covers the body",
+			Interval:    20 * time.Millisecond,
+			ExpectError: context.DeadlineExceeded,
+		},
+		{
+			Name:     "progressing body",
+			Interval: 20 * time.Millisecond,
+			Options:  []Option{WithIdleTimeout(80 * time.Millisecond)},
+		},
+		{
+			Name:        "stalled body",
+			Interval:    -1,
+			Options:     []Option{WithIdleTimeout(50 * time.Millisecond)},
+			ExpectError: ErrIdleTimeout,
+		},
+		{
+			Name:        "slow headers",
+			HeaderDelay: 300 * time.Millisecond,
+			Options:     []Option{WithHeaderTimeout(50 * time.Millisecond), WithIdleTimeout(time.Second)},
+			ExpectError: ErrHeaderTimeout,
+		},
+		{
+			Name:        "client timeout covers the headers",
+			HeaderDelay: 300 * time.Millisecond,
+			Options:     []Option{WithIdleTimeout(time.Second)},
+			ExpectError: ErrHeaderTimeout,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server := newSlowServer(t, c.HeaderDelay, c.Interval)
+
+			client, err := NewClient(context.Background(), server.URL, 100*time.Millisecond, c.Options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r []map[string]int
+			count, err := client.Get("test", url.Values{}, &r)
+
+			if c.ExpectError != nil {
+				if !errors.Is(err, c.ExpectError) {
+					t.Errorf("Expected %v but got %v", c.ExpectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != 10 {
+				t.Errorf("Expected 10 records but got %d", count)
+			}
+		})
+	}
+}
+
+func TestMethodIdleTimeout(t *testing.T) {
+	server := newSlowServer(t, 0, -1)
+
+	client, err := NewClient(context.Background(), server.URL, time.Second, WithIdleTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Configure("test", MethodConfig{IdleTimeout: 50 * time.Millisecond})
+
+	records, errs := client.Stream(context.Background(), "test", url.Values{})
+	for range records {
+	}
+
+	if err := <-errs; !errors.Is(err, ErrIdleTimeout) {
+		t.Errorf("Expected ErrIdleTimeout but got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package millennium
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// TranslateHeader is the response header carrying the error message
+// translated by Millennium, set in ResponseError.Translated
+const TranslateHeader = "WTS-Translate"
+
+// ErrorTranslator translates the message of a ResponseError, sent in lang,
+// to the language of the application, returning false when it can't
+type ErrorTranslator func(lang, message string) (string, bool)
+
+// WithErrorTranslator translates the messages of the ResponseError not
+// translated by Millennium with translator, like a lookup of the known
+// Portuguese messages, keeping the original message in Error
+func WithErrorTranslator(translator ErrorTranslator) Option {
+	return func(m *Millennium) {
+		m.errorTranslator = translator
+	}
+}
+
+// Message returns the original message sent by Millennium, in Lang
+func (r *ResponseError) Message() string {
+	return r.Err.Message.Value
+}
+
+// LocalizedMessage returns the translated message, falling back to the
+// original one when not translated
+func (r *ResponseError) LocalizedMessage() string {
+	if r.Translated != "" {
+		return r.Translated
+	}
+
+	return r.Err.Message.Value
+}
+
+// translateError sets the translated message of r, from the response
+// header or the translator of the client
+func (m *Millennium) translateError(r *ResponseError, res *http.Response) {
+	if translated := res.Header.Get(TranslateHeader); translated != "" {
+		// Headers can't carry accents, so they may be escaped
+		if unescaped, err := url.PathUnescape(translated); err == nil {
+			translated = unescaped
+		}
+
+		r.Translated = translated
+		return
+	}
+
+	if m.errorTranslator == nil || r.Err.Message.Value == "" {
+		return
+	}
+
+	if translated, ok := m.errorTranslator(r.Lang, r.Err.Message.Value); ok {
+		r.Translated = translated
+	}
+}
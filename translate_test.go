@@ -0,0 +1,83 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestResponseErrorTranslation(t *testing.T) {
+	const body = `{"error":{"code":400,"message":{"lang":"pt-BR","value":"Produto não encontrado"}}}`
+
+	translator := func(lang, message string) (string, bool) {
+		if lang == "pt-BR" && message == "Produto não encontrado" {
+			return "Product not found", true
+		}
+		return "", false
+	}
+
+	cases := []struct {
+		Name       string
+		Header     string
+		Translator ErrorTranslator
+		Translated string
+		Localized  string
+	}{
+		{Name: "untranslated", Localized: "Produto não encontrado"},
+		{Name: "translator", Translator: translator, Translated: "Product not found", Localized: "Product not found"},
+		{Name: "header", Header: "Product%20not%20found", Translator: translator, Translated: "Product not found", Localized: "Product not found"},
+		{Name: "unknown message", Translator: func(string, string) (string, bool) { return "", false }, Localized: "Produto não encontrado"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if c.Header != "" {
+					w.Header().Set(TranslateHeader, c.Header)
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(body))
+			}))
+			t.Cleanup(server.Close)
+
+			var opts []Option
+			if c.Translator != nil {
+				opts = append(opts, WithErrorTranslator(c.Translator))
+			}
+
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			_, err = client.Get("produtos.lista", url.Values{}, &r)
+
+			var resErr *ResponseError
+			if !errors.As(err, &resErr) {
+				t.Fatalf("Expected a ResponseError but got %v", err)
+			}
+
+			if resErr.Lang != "pt-BR" {
+				t.Errorf("Expected lang pt-BR but got %q", resErr.Lang)
+			}
+
+			if resErr.Error() != "Produto não encontrado" || resErr.Message() != "Produto não encontrado" {
+				t.Errorf("Expected the original message but got %q", resErr.Error())
+			}
+
+			if resErr.Translated != c.Translated {
+				t.Errorf("Expected translated %q but got %q", c.Translated, resErr.Translated)
+			}
+
+			if got := resErr.LocalizedMessage(); got != c.Localized {
+				t.Errorf("Expected localized %q but got %q", c.Localized, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package millennium
+
+import "net/http"
+
+// WithTransport sets the RoundTripper used to reach Millennium, like a proxy
+// or a tracing transport. NTLM authentication wraps it instead of replacing
+// it, so its settings survive Login.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(m *Millennium) {
+		if t, ok := transport.(*http.Transport); ok {
+			m.configureTransport(t)
+		}
+
+		m.Client.HTTPClient.Transport = transport
+	}
+}
@@ -0,0 +1,62 @@
+package millennium
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	count int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.count, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	cases := []struct {
+		Name     string
+		AuthType AuthType
+	}{
+		{Name: "session", AuthType: Session},
+		{Name: "ntlm", AuthType: NTLM},
+		{Name: "ntlm twice", AuthType: NTLM},
+	}
+
+	transport := &countingTransport{}
+
+	client, err := NewClient(context.Background(), serverAddr, 5*time.Second, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			before := atomic.LoadInt32(&transport.count)
+
+			if err := client.Login("test", "test", c.AuthType); err != nil {
+				t.Fatal(err)
+			}
+
+			var r interface{}
+			if _, err := client.Get("test.success.GET", url.Values{}, &r); err != nil {
+				t.Fatal(err)
+			}
+
+			if atomic.LoadInt32(&transport.count) == before {
+				t.Error("Expected the requests to go through the configured transport")
+			}
+
+			// Login never changes the shared client
+			if client.Client.HTTPClient.Transport != http.RoundTripper(transport) {
+				t.Errorf("Expected the transport to be kept but got %T", client.Client.HTTPClient.Transport)
+			}
+		})
+	}
+}
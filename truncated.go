@@ -0,0 +1,56 @@
+package millennium
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrTruncatedResponse is returned when the response body ended before the
+// expected, usually because the connection dropped. Requests failing with it
+// are retried.
+var ErrTruncatedResponse = errors.New("truncated response from Millennium")
+
+// readBody reads the whole response body, detecting truncated ones
+func readBody(res *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: %v", ErrTruncatedResponse, err)
+		}
+		return nil, fmt.Errorf("unable to read body from Millennium response: %w", err)
+	}
+
+	if res.ContentLength >= 0 && int64(len(body)) != res.ContentLength {
+		return nil, fmt.Errorf("%w: expected %d bytes but got %d", ErrTruncatedResponse, res.ContentLength, len(body))
+	}
+
+	return body, nil
+}
+
+// decodeJSON unmarshals a complete JSON document into output.
+// Documents ending unexpectedly are reported as truncated, and any data after
+// the document is rejected.
+func decodeJSON(body []byte, output interface{}) error {
+	// Keep the encoding/json error for empty bodies
+	if len(bytes.TrimSpace(body)) == 0 {
+		return json.Unmarshal(body, output)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(output); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: %v", ErrTruncatedResponse, err)
+		}
+		return err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the JSON response")
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	cases := []struct {
+		Body      string
+		Truncated bool
+		Error     bool
+	}{
+		{Body: `{"value":[1,2]}`},
+		{Body: `{"value":[1,2]}` + "\n"},
+		{Body: `{"value":[1,`, Truncated: true, Error: true},
+		{Body: `{"value":[1,2]}{"value":[3]}`, Error: true},
+		{Body: ``, Error: true},
+	}
+
+	for _, c := range cases {
+		var v interface{}
+		err := decodeJSON([]byte(c.Body), &v)
+
+		if (err != nil) != c.Error {
+			t.Errorf("%s: unexpected error %v", c.Body, err)
+		}
+
+		if errors.Is(err, ErrTruncatedResponse) != c.Truncated {
+			t.Errorf("%s: expected truncated %v but got %v", c.Body, c.Truncated, err)
+		}
+	}
+}
+
+// newTruncatingServer returns a server that drops the connection in the
+// middle of the first truncate responses
+func newTruncatingServer(t *testing.T, truncate int32) (*httptest.Server, *int32) {
+	var hits int32
+	body := `{"odata.count":2,"value":[{"number":1},{"number":2}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) > truncate {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+			return
+		}
+
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n")
+		_, _ = buf.WriteString(body[:20])
+		_ = buf.Flush()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &hits
+}
+
+func TestTruncatedResponseRetry(t *testing.T) {
+	server, hits := newTruncatingServer(t, 2)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r []map[string]int
+	count, err := client.Get("test", url.Values{}, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 || atomic.LoadInt32(hits) != 3 {
+		t.Errorf("Expected 2 records after 3 attempts but got %d after %d", count, *hits)
+	}
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	server, hits := newTruncatingServer(t, 100)
+
+	client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r []map[string]int
+	if _, err := client.Get("test", url.Values{}, &r); !errors.Is(err, ErrTruncatedResponse) {
+		t.Errorf("Expected ErrTruncatedResponse but got %v", err)
+	}
+
+	if atomic.LoadInt32(hits) != RetryMax+1 {
+		t.Errorf("Expected %d attempts but got %d", RetryMax+1, *hits)
+	}
+
+	records, errs := client.Stream(context.Background(), "test", url.Values{})
+	for range records {
+	}
+
+	if err := <-errs; !errors.Is(err, ErrTruncatedResponse) {
+		t.Errorf("Expected ErrTruncatedResponse from stream but got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package millennium
+
+import (
+	"context"
+	"net/url"
+)
+
+// GetAs requests a method using GET http method and decodes the records of
+// the response into a slice of T, returning it with the total number of
+// records reported by Millennium
+func GetAs[T any](m *Millennium, method string, params url.Values) ([]T, int, error) {
+	return GetAsCtx[T](m.Context, m, method, params)
+}
+
+// GetAsCtx is GetAs bound to ctx instead of the client context
+func GetAsCtx[T any](ctx context.Context, m *Millennium, method string, params url.Values) ([]T, int, error) {
+	var records []T
+
+	count, err := m.GetCtx(ctx, method, params, &records)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, count, nil
+}
@@ -0,0 +1,30 @@
+package millennium
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGetAs(t *testing.T) {
+	client := NewTestClient(t)
+
+	type Result struct {
+		Number int    `json:"number"`
+		String string `json:"string"`
+		Bool   bool   `json:"bool"`
+	}
+
+	records, count, err := GetAs[Result](client, "test.success.GET", url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Result{Number: 1, String: "test", Bool: true}
+	if count != 1 || len(records) != 1 || records[0] != expected {
+		t.Errorf("Expected [%+v] but got %+v (count %d)", expected, records, count)
+	}
+
+	if records, _, err := GetAs[Record](client, "test.error400.GET", url.Values{}); err == nil || records != nil {
+		t.Errorf("Expected error and no records but got %v %v", records, err)
+	}
+}
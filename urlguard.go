@@ -0,0 +1,52 @@
+package millennium
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxURLLength is the longest request URL sent by the client, the
+// default limit of the Windows http stack serving Millennium
+const DefaultMaxURLLength = 8192
+
+// ErrURLTooLong is returned, as an URLTooLongError, when the parameters of a
+// request make its URL longer than the client limit
+var ErrURLTooLong = errors.New("request URL too long")
+
+// URLTooLongError describes a request not sent because of its URL length,
+// instead of letting Millennium answer with an opaque 414 or 400
+type URLTooLongError struct {
+	Method string
+	Length int
+	Max    int
+}
+
+func (e *URLTooLongError) Error() string {
+	return fmt.Sprintf("%v: %s has %d characters, the limit is %d; split the filter or the list of values in smaller requests", ErrURLTooLong, e.Method, e.Length, e.Max)
+}
+
+func (e *URLTooLongError) Unwrap() error {
+	return ErrURLTooLong
+}
+
+// WithMaxURLLength replaces DefaultMaxURLLength as the longest request URL
+// sent by the client. A negative max disables the check.
+func WithMaxURLLength(max int) Option {
+	return func(m *Millennium) {
+		m.maxURLLength = max
+	}
+}
+
+// checkURLLength fails requests with URLs longer than the client limit
+func (m *Millennium) checkURLLength(method string, requestURL string) error {
+	max := m.maxURLLength
+	if max == 0 {
+		max = DefaultMaxURLLength
+	}
+
+	if max < 0 || len(requestURL) <= max {
+		return nil
+	}
+
+	return &URLTooLongError{Method: method, Length: len(requestURL), Max: max}
+}
@@ -0,0 +1,45 @@
+package millennium
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxURLLength(t *testing.T) {
+	filter := url.Values{"$filter": {"produto eq '" + strings.Repeat("1", 9000) + "'"}}
+
+	cases := []struct {
+		Name    string
+		Options []Option
+		Params  url.Values
+		Error   bool
+	}{
+		{Name: "short url", Params: url.Values{"produto": {"1"}}},
+		{Name: "default limit", Params: filter, Error: true},
+		{Name: "custom limit", Options: []Option{WithMaxURLLength(100)}, Params: url.Values{"$filter": {strings.Repeat("a", 100)}}, Error: true},
+		{Name: "disabled", Options: []Option{WithMaxURLLength(-1)}, Params: filter},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), serverAddr, 5*time.Second, c.Options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.newRequest(context.Background(), RequestMethod{HTTPMethod: GET, Method: "test.success.GET", Params: c.Params})
+			if c.Error != errors.Is(err, ErrURLTooLong) {
+				t.Errorf("Expected %v to be %v but got %v", ErrURLTooLong, c.Error, err)
+			}
+
+			var urlErr *URLTooLongError
+			if c.Error && (!errors.As(err, &urlErr) || urlErr.Length <= urlErr.Max) {
+				t.Errorf("Expected the URL length in the error but got %v", err)
+			}
+		})
+	}
+}
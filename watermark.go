@@ -0,0 +1,130 @@
+package millennium
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// WatermarkParam is the default parameter used to filter records updated
+// since the last synchronization
+const WatermarkParam = "data_atualizacao"
+
+// WatermarkStore persists the timestamps used by delta synchronizations
+type WatermarkStore interface {
+	// Load returns the stored timestamp for key, or the zero time if there is none
+	Load(key string) (time.Time, error)
+
+	// Save stores the timestamp for key
+	Save(key string, t time.Time) error
+}
+
+// MemoryWatermarkStore is a WatermarkStore that keeps the timestamps in memory
+type MemoryWatermarkStore struct {
+	mu     sync.Mutex
+	values map[string]time.Time
+}
+
+// NewMemoryWatermarkStore returns an empty MemoryWatermarkStore
+func NewMemoryWatermarkStore() *MemoryWatermarkStore {
+	return &MemoryWatermarkStore{values: map[string]time.Time{}}
+}
+
+// Load returns the stored timestamp for key
+func (s *MemoryWatermarkStore) Load(key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key], nil
+}
+
+// Save stores the timestamp for key
+func (s *MemoryWatermarkStore) Save(key string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = t
+	return nil
+}
+
+// Watermark keeps the bookkeeping of a delta synchronization.
+// It holds the timestamp of the last successful synchronization and the
+// timestamp that will be stored once the current one succeeds.
+type Watermark struct {
+	// Param is the request parameter that receives the watermark
+	Param string
+
+	mu    sync.Mutex
+	store WatermarkStore
+	key   string
+	since time.Time
+	next  time.Time
+}
+
+// SinceWatermark reads the persisted watermark for key and returns a
+// Watermark ready to filter the next synchronization.
+// The next watermark is taken before any request is made, so records updated
+// while the synchronization runs are fetched again on the next run.
+func SinceWatermark(store WatermarkStore, key string) (*Watermark, error) {
+	since, err := store.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load watermark %s: %w", key, err)
+	}
+
+	return &Watermark{
+		Param: WatermarkParam,
+		store: store,
+		key:   key,
+		since: since,
+		next:  time.Now(),
+	}, nil
+}
+
+// Since returns the timestamp of the last successful synchronization
+func (w *Watermark) Since() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.since
+}
+
+// Apply sets the watermark filter in params and returns it.
+// Nothing is set on the first synchronization, when there is no watermark yet.
+func (w *Watermark) Apply(params url.Values) url.Values {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	since := w.Since()
+	if !since.IsZero() {
+		params.Set(w.Param, since.Format("2006-01-02T15:04:05"))
+	}
+
+	return params
+}
+
+// Commit advances the watermark and persists it in the store.
+// The next watermark is taken again, so the same Watermark can be used by
+// the following synchronizations.
+func (w *Watermark) Commit() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.store.Save(w.key, w.next); err != nil {
+		return fmt.Errorf("unable to save watermark %s: %w", w.key, err)
+	}
+
+	w.since, w.next = w.next, time.Now()
+	return nil
+}
+
+// Sync calls fn with params filtered by the watermark and commits the
+// watermark only if fn succeeds
+func (w *Watermark) Sync(params url.Values, fn func(params url.Values) error) error {
+	if err := fn(w.Apply(params)); err != nil {
+		return err
+	}
+
+	return w.Commit()
+}
@@ -0,0 +1,85 @@
+package millennium
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWatermark(t *testing.T) {
+	store := NewMemoryWatermarkStore()
+
+	w, err := SinceWatermark(store, "produtos")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First synchronization has no filter
+	err = w.Sync(url.Values{}, func(params url.Values) error {
+		if params.Has(WatermarkParam) {
+			t.Errorf("Expected no watermark but got %s", params.Get(WatermarkParam))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := store.Load("produtos")
+	if stored.IsZero() {
+		t.Fatal("Expected watermark to be saved")
+	}
+
+	// A second run of the same Watermark advances it again
+	time.Sleep(10 * time.Millisecond)
+	if err := w.Sync(nil, func(url.Values) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if second, _ := store.Load("produtos"); !second.After(stored) || !w.Since().Equal(second) {
+		t.Errorf("Expected the watermark to advance from %v but got %v", stored, second)
+	}
+	stored, _ = store.Load("produtos")
+
+	w, err = SinceWatermark(store, "produtos")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Failed synchronization keeps the watermark untouched
+	err = w.Sync(nil, func(params url.Values) error {
+		if params.Get(WatermarkParam) != stored.Format("2006-01-02T15:04:05") {
+			t.Errorf("Unexpected watermark %s", params.Get(WatermarkParam))
+		}
+		return errors.New("sync failed")
+	})
+	if err == nil {
+		t.Error("Expected error")
+	}
+
+	if current, _ := store.Load("produtos"); !current.Equal(stored) {
+		t.Errorf("Expected watermark %v but got %v", stored, current)
+	}
+}
+
+type failingWatermarkStore struct{}
+
+func (failingWatermarkStore) Load(string) (time.Time, error) {
+	return time.Time{}, errors.New("load failed")
+}
+
+func (failingWatermarkStore) Save(string, time.Time) error {
+	return errors.New("save failed")
+}
+
+func TestWatermarkStoreErrors(t *testing.T) {
+	if _, err := SinceWatermark(failingWatermarkStore{}, "x"); err == nil {
+		t.Error("Expected load error")
+	}
+
+	w := &Watermark{Param: WatermarkParam, store: failingWatermarkStore{}, key: "x"}
+	if err := w.Commit(); err == nil {
+		t.Error("Expected save error")
+	}
+}
@@ -0,0 +1,263 @@
+package millennium
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxDateLayouts are the layouts of the string values exported as dates
+var xlsxDateLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// xlsxEpoch is the day zero of the Excel date serial numbers
+var xlsxEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// ExportXLSX requests a method using GET http method and writes the response
+// to w as an Excel workbook with a single sheet.
+// The first row holds the field names, in the order they appear in the
+// response. Numbers and booleans are exported as such, ISO dates as Excel
+// dates, nested objects and arrays as JSON text and everything else as text.
+// The fields of the client redaction policy are hidden, as the records
+// leave the process. It returns the number of records written.
+//
+// The header row depends on every record, so the sheet is kept in memory
+// until the response ends, about the size of the response. Large exports
+// should use GetNDJSON, which streams the records.
+func (m *Millennium) ExportXLSX(method string, params url.Values, w io.Writer) (int, error) {
+	var (
+		sheet   bytes.Buffer
+		columns []string
+		index   = map[string]int{}
+		count   int
+	)
+
+	err := m.streamValues(m.Context, method, params, func(raw json.RawMessage) error {
+		fields, err := orderedFields(m.redaction.Redact(raw))
+		if err != nil {
+			return err
+		}
+
+		count++
+		row := count + 1
+		fmt.Fprintf(&sheet, `<row r="%d">`, row)
+
+		for _, field := range fields {
+			col, ok := index[field.Name]
+			if !ok {
+				col = len(columns)
+				index[field.Name] = col
+				columns = append(columns, field.Name)
+			}
+
+			writeXLSXCell(&sheet, xlsxCellRef(col, row), field.Value)
+		}
+
+		sheet.WriteString(`</row>`)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeXLSX(w, xlsxSheetName(method), columns, sheet.Bytes()); err != nil {
+		return 0, fmt.Errorf("unable to write workbook: %w", err)
+	}
+
+	return count, nil
+}
+
+type orderedField struct {
+	Name  string
+	Value json.RawMessage
+}
+
+// orderedFields decodes a JSON object keeping the order of its fields
+func orderedFields(raw json.RawMessage) ([]orderedField, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var fields []orderedField
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, decodeError(err)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, decodeError(err)
+		}
+
+		fields = append(fields, orderedField{Name: token.(string), Value: value})
+	}
+
+	return fields, nil
+}
+
+// writeXLSXCell writes a JSON value as a cell, skipping null values
+func writeXLSXCell(buf *bytes.Buffer, ref string, value json.RawMessage) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 {
+		return
+	}
+
+	switch value[0] {
+	case 'n':
+		return
+	case 't', 'f':
+		b := "0"
+		if value[0] == 't' {
+			b = "1"
+		}
+		fmt.Fprintf(buf, `<c r="%s" t="b"><v>%s</v></c>`, ref, b)
+	case '"':
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			writeXLSXText(buf, ref, string(value))
+			return
+		}
+
+		if t, ok := parseXLSXDate(s); ok {
+			serial := t.Sub(xlsxEpoch).Hours() / 24
+			fmt.Fprintf(buf, `<c r="%s" s="1"><v>%s</v></c>`, ref, strconv.FormatFloat(serial, 'f', -1, 64))
+			return
+		}
+
+		writeXLSXText(buf, ref, s)
+	case '{', '[':
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			writeXLSXText(buf, ref, string(value))
+			return
+		}
+		writeXLSXText(buf, ref, compact.String())
+	default:
+		fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, value)
+	}
+}
+
+func writeXLSXText(buf *bytes.Buffer, ref, s string) {
+	fmt.Fprintf(buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+	_ = xml.EscapeText(buf, []byte(s))
+	buf.WriteString(`</t></is></c>`)
+}
+
+func parseXLSXDate(s string) (time.Time, bool) {
+	for _, layout := range xlsxDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			// Excel dates have no time zone, keep the wall clock
+			y, mo, d := t.Date()
+			h, mi, sec := t.Clock()
+			return time.Date(y, mo, d, h, mi, sec, t.Nanosecond(), time.UTC), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// xlsxCellRef returns the A1 reference of a zero based column and a one
+// based row
+func xlsxCellRef(col, row int) string {
+	var name []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append([]byte{byte('A' + (col-1)%26)}, name...)
+	}
+
+	return string(name) + strconv.Itoa(row)
+}
+
+// xlsxSheetName turns a method name into a valid sheet name
+func xlsxSheetName(method string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, method)
+
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+
+	if name == "" {
+		name = "Sheet1"
+	}
+
+	return name
+}
+
+func writeXLSX(w io.Writer, sheetName string, columns []string, rows []byte) error {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if len(columns) > 0 {
+		sheet.WriteString(`<row r="1">`)
+		for col, name := range columns {
+			writeXLSXText(&sheet, xlsxCellRef(col, 1), name)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.Write(rows)
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var escapedName bytes.Buffer
+	_ = xml.EscapeText(&escapedName, []byte(sheetName))
+
+	files := []struct {
+		Name string
+		Body []byte
+	}{
+		{Name: "[Content_Types].xml", Body: []byte(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`)},
+		{Name: "_rels/.rels", Body: []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`)},
+		{Name: "xl/workbook.xml", Body: []byte(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escapedName.String() + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`)},
+		{Name: "xl/_rels/workbook.xml.rels", Body: []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`)},
+		{Name: "xl/styles.xml", Body: []byte(xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+			`<fonts count="1"><font/></fonts>` +
+			`<fills count="1"><fill/></fills>` +
+			`<borders count="1"><border/></borders>` +
+			`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf/><xf numFmtId="164" applyNumberFormat="1"/></cellXfs>` +
+			`</styleSheet>`)},
+		{Name: "xl/worksheets/sheet1.xml", Body: sheet.Bytes()},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := zw.Create(file.Name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Write(file.Body); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,143 @@
+package millennium
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportXLSX(t *testing.T) {
+	client := NewTestClient(t)
+
+	var buf bytes.Buffer
+	count, err := client.ExportXLSX("test.export", url.Values{}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 records but got %d", count)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[f.Name] = string(body)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in workbook", name)
+		}
+	}
+
+	if !strings.Contains(files["xl/workbook.xml"], `name="test.export"`) {
+		t.Errorf("Expected sheet named after the method in %s", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	expected := []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">codigo</t></is></c>`,
+		`<c r="F1" t="inlineStr"><is><t xml:space="preserve">itens</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">001</t></is></c>`,
+		`<c r="B2"><v>12.5</v></c>`,
+		`<c r="C2" t="b"><v>1</v></c>`,
+		`<c r="D2" s="1"><v>45293.5</v></c>`,
+		`<c r="A3" t="inlineStr"><is><t xml:space="preserve">&lt;002&gt;</t></is></c>`,
+		`<c r="C3" t="b"><v>0</v></c>`,
+		`<c r="F3" t="inlineStr"><is><t xml:space="preserve">[1,2]</t></is></c>`,
+	}
+
+	for _, cell := range expected {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("Expected %s in sheet %s", cell, sheet)
+		}
+	}
+
+	if strings.Contains(sheet, `r="E2"`) {
+		t.Error("Expected null value to be skipped")
+	}
+
+	if _, err := client.ExportXLSX("test.error400.GET", url.Values{}, &buf); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestExportXLSXRedaction(t *testing.T) {
+	client, err := NewClient(context.Background(), serverAddr, 30*time.Second, WithRedaction("codigo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := client.ExportXLSX("test.export", url.Values{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheet, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(sheet), "001") || !strings.Contains(string(sheet), `<c r="A2" t="inlineStr"><is><t xml:space="preserve">REDACTED</t></is></c>`) {
+			t.Errorf("Expected the codes to be redacted in sheet %s", sheet)
+		}
+	}
+}
+
+func TestXLSXCellRef(t *testing.T) {
+	cases := []struct {
+		Col      int
+		Row      int
+		Expected string
+	}{
+		{Col: 0, Row: 1, Expected: "A1"},
+		{Col: 25, Row: 2, Expected: "Z2"},
+		{Col: 26, Row: 3, Expected: "AA3"},
+		{Col: 701, Row: 4, Expected: "ZZ4"},
+		{Col: 702, Row: 5, Expected: "AAA5"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Expected, func(t *testing.T) {
+			if ref := xlsxCellRef(c.Col, c.Row); ref != c.Expected {
+				t.Errorf("Expected %s but got %s", c.Expected, ref)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package millennium
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// XMLRecordFunc is called by StreamXML with each record element. It should
+// consume the element, with dec.DecodeElement(&v, &start) or dec.Skip(),
+// leaving the decoder positioned after its end.
+type XMLRecordFunc func(dec *xml.Decoder, start xml.StartElement) error
+
+// StreamXML requests a method using GET http method in the XML response
+// mode ($format=xml) and calls fn with each element named element, matched
+// by its local name, as soon as it is read, like Stream does for JSON.
+// The response is read only as fast as fn handles the records, instead of
+// loading the entire response in memory. Masked and encrypted fields are
+// not applied to XML responses.
+func (m *Millennium) StreamXML(ctx context.Context, method string, params url.Values, element string, fn XMLRecordFunc) error {
+	if err := m.checkMethod(method); err != nil {
+		return err
+	}
+
+	params = cloneParams(params)
+	params.Set("$format", "xml")
+
+	return m.onServer(ctx, func() error {
+		return m.streamServerXML(ctx, method, params, element, fn)
+	})
+}
+
+// streamServerXML is StreamXML once on the current server
+func (m *Millennium) streamServerXML(ctx context.Context, method string, params url.Values, element string, fn XMLRecordFunc) error {
+	if err := m.ensureSession(); err != nil {
+		return err
+	}
+
+	req, err := m.newRequest(ctx, RequestMethod{
+		HTTPMethod: GET,
+		Method:     method,
+		Params:     params,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := m.do(method, req)
+	if err != nil {
+		return fmt.Errorf("unable to make the request to Millennium: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read body from Millennium response: %w", err)
+		}
+
+		return m.responseError(res, body)
+	}
+
+	count := m.recordCounter(method)
+	return decodeXMLRecords(xml.NewDecoder(res.Body), element, func(dec *xml.Decoder, start xml.StartElement) error {
+		if err := count(); err != nil {
+			return err
+		}
+
+		return fn(dec, start)
+	})
+}
+
+// decodeXMLRecords walks through an XML document calling fn for each
+// element named element, reporting documents ending in the middle as
+// truncated
+func decodeXMLRecords(dec *xml.Decoder, element string, fn XMLRecordFunc) error {
+	depth := 0
+	root := false
+
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) && root && depth == 0 {
+			return nil
+		}
+
+		if err != nil {
+			return xmlDecodeError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			root = true
+
+			if t.Name.Local == element {
+				if err := fn(dec, t); err != nil {
+					if truncatedXML(err) {
+						return xmlDecodeError(err)
+					}
+					return err
+				}
+				continue
+			}
+
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// truncatedXML reports if err tells the document ended in the middle
+func truncatedXML(err error) bool {
+	var syntaxErr *xml.SyntaxError
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || (errors.As(err, &syntaxErr) && syntaxErr.Msg == "unexpected EOF")
+}
+
+// xmlDecodeError reports a document that ended in the middle as truncated
+func xmlDecodeError(err error) error {
+	if truncatedXML(err) {
+		return decodeError(io.ErrUnexpectedEOF)
+	}
+
+	return decodeError(err)
+}
@@ -0,0 +1,91 @@
+package millennium
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const xmlProducts = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:d="http://schemas.microsoft.com/ado/2007/08/dataservices" xmlns:m="http://schemas.microsoft.com/ado/2007/08/dataservices/metadata">
+  <entry><content><m:properties><d:produto>1</d:produto><d:descricao>Camisa</d:descricao></m:properties></content></entry>
+  <entry><content><m:properties><d:produto>2</d:produto><d:descricao>Calça</d:descricao></m:properties></content></entry>
+  <entry><content><m:properties><d:produto>3</d:produto><d:descricao>Meia</d:descricao></m:properties></content></entry>
+</feed>`
+
+func TestStreamXML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if formats := r.URL.Query()["$format"]; len(formats) != 1 || formats[0] != "xml" {
+			t.Errorf("Expected $format=xml but got %v", formats)
+		}
+
+		body := xmlProducts
+		if r.URL.Path == "/api/test.truncated" {
+			body = body[:strings.Index(body, "Meia")]
+		}
+
+		w.Header().Set("Content-Type", "application/atom+xml")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	stop := errors.New("stop")
+
+	cases := []struct {
+		Name     string
+		Method   string
+		StopAt   int
+		Products []int
+		Err      error
+	}{
+		{Name: "all records", Method: "test", Products: []int{1, 2, 3}},
+		{Name: "stopped by callback", Method: "test", StopAt: 2, Products: []int{1, 2}, Err: stop},
+		{Name: "truncated", Method: "test.truncated", Products: []int{1, 2}, Err: ErrTruncatedResponse},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), server.URL, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var products []int
+			err = client.StreamXML(context.Background(), c.Method, url.Values{"$format": {"json"}}, "properties", func(dec *xml.Decoder, start xml.StartElement) error {
+				var product struct {
+					Produto   int    `xml:"produto"`
+					Descricao string `xml:"descricao"`
+				}
+				if err := dec.DecodeElement(&product, &start); err != nil {
+					return err
+				}
+
+				products = append(products, product.Produto)
+				if len(products) == c.StopAt {
+					return stop
+				}
+				return nil
+			})
+
+			if (c.Err == nil && err != nil) || (c.Err != nil && !errors.Is(err, c.Err)) {
+				t.Errorf("Expected error %v but got %v", c.Err, err)
+			}
+
+			if len(products) != len(c.Products) {
+				t.Fatalf("Expected products %v but got %v", c.Products, products)
+			}
+
+			for i := range products {
+				if products[i] != c.Products[i] {
+					t.Errorf("Expected products %v but got %v", c.Products, products)
+				}
+			}
+		})
+	}
+}